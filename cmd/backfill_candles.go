@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"time"
+	configs "tradeTornado/config"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/marketdata/application"
+	"tradeTornado/internal/service/wiring"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var backfillCandlesOptions struct {
+	symbol string
+	from   string
+	to     string
+}
+
+// BackfillCandles rebuilds the candles of the whole UTC days overlapping [from, to) from the trades table
+func BackfillCandles(symbol, from, to string) {
+	cnf := configs.ConfigFromEnv()
+	c := wiring.NewContainer(cnf)
+	cmd := application.BackfillCandlesCommand{Symbol: symbol, To: time.Now()}
+	var err error
+	if cmd.From, err = time.Parse(time.RFC3339, from); err != nil {
+		logrus.Fatalln(err)
+	}
+	if to != "" {
+		if cmd.To, err = time.Parse(time.RFC3339, to); err != nil {
+			logrus.Fatalln(err)
+		}
+	}
+	report, err := c.NewCandleCommandHandler().Backfill(lib.Terminable(), cmd)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	logrus.WithField("symbol", report.Symbol).
+		WithField("from", report.From).
+		WithField("to", report.To).
+		WithField("trades", report.Trades).
+		WithField("candles", report.Candles).
+		Infoln("backfill finished")
+}
+
+func init() {
+	flags := backfillCandlesCmd.Flags()
+	flags.StringVar(&backfillCandlesOptions.symbol, "symbol", "", "symbol of the trades")
+	flags.StringVar(&backfillCandlesOptions.from, "from", "", "RFC 3339 time of the first trade")
+	flags.StringVar(&backfillCandlesOptions.to, "to", "", "RFC 3339 time after the last trade, now when empty")
+	_ = backfillCandlesCmd.MarkFlagRequired("symbol")
+	_ = backfillCandlesCmd.MarkFlagRequired("from")
+	rootCmd.AddCommand(backfillCandlesCmd)
+}
+
+var backfillCandlesCmd = &cobra.Command{
+	Use:   "backfill-candles",
+	Short: "Rebuild the candles of a symbol from its trades",
+	Run: func(cmd *cobra.Command, args []string) {
+		BackfillCandles(backfillCandlesOptions.symbol, backfillCandlesOptions.from, backfillCandlesOptions.to)
+	},
+}
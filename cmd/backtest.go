@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"time"
+	configs "tradeTornado/config"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/modules/order/infrastructure"
+	"tradeTornado/internal/service/wiring"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type backtestFlags struct {
+	input            string
+	format           string
+	output           string
+	start            string
+	step             time.Duration
+	snapshotInterval time.Duration
+	depthLevels      int
+	tickSize         string
+	lotSize          string
+	minOrderQuantity string
+	allocation       string
+	minAllocation    string
+	makerFeeBps      int
+	takerFeeBps      int
+	stpMode          string
+}
+
+var backtestOptions backtestFlags
+
+// Backtest runs the input through the matching core offline and writes trades.jsonl, books.jsonl and summary.json
+func Backtest(options backtestFlags) {
+	cnf := configs.ConfigFromEnv()
+	c := wiring.NewContainer(cnf)
+	settings, format, err := options.settings(cnf)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	input, err := os.Open(options.input)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	defer input.Close()
+	files, err := infrastructure.NewBacktestFiles(options.output)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	summary, err := c.NewBacktester(settings, files).Run(lib.Terminable(), input, format)
+	if err == nil {
+		err = files.WriteSummary(summary)
+	}
+	if err = errors.Join(err, files.Close()); err != nil {
+		logrus.Fatalln(err)
+	}
+	logrus.WithField("orders", summary.Orders).WithField("trades", summary.Trades).WithField("output", options.output).Infoln("backtest finished")
+}
+
+// settings parses the decimals once the container set the decimal scale
+func (bf backtestFlags) settings(cnf configs.Configs) (application.BacktestSettings, application.BacktestInputFormat, error) {
+	format, err := application.ParseBacktestInputFormat(bf.format)
+	if err != nil {
+		return application.BacktestSettings{}, "", err
+	}
+	start, err := time.Parse(time.RFC3339, bf.start)
+	if err != nil {
+		return application.BacktestSettings{}, "", err
+	}
+	algorithm, err := order.ParseAllocationAlgorithm(bf.allocation)
+	if err != nil {
+		return application.BacktestSettings{}, "", err
+	}
+	stpMode, err := order.ParseSTPMode(bf.stpMode)
+	if err != nil {
+		return application.BacktestSettings{}, "", err
+	}
+	decimals := make([]lib.Decimal, 4)
+	for i, value := range []string{bf.tickSize, bf.lotSize, bf.minOrderQuantity, bf.minAllocation} {
+		if decimals[i], err = lib.ParseDecimal(value); err != nil {
+			return application.BacktestSettings{}, "", err
+		}
+	}
+	return application.BacktestSettings{
+		TradingRules:     order.TradingRules{TickSize: decimals[0], LotSize: decimals[1], MinOrderQuantity: decimals[2]},
+		Allocation:       order.AllocationRules{Algorithm: algorithm, MinAllocation: decimals[3], LotSize: decimals[1]},
+		MakerFeeBps:      bf.makerFeeBps,
+		TakerFeeBps:      bf.takerFeeBps,
+		STPMode:          stpMode,
+		FeeAccountID:     cnf.FeeCollectionAccount,
+		Start:            start,
+		Step:             bf.step,
+		SnapshotInterval: bf.snapshotInterval,
+		DepthLevels:      bf.depthLevels,
+	}, format, nil
+}
+
+func init() {
+	flags := backtestCmd.Flags()
+	flags.StringVar(&backtestOptions.input, "input", "", "JSON lines file of order events or journal entries")
+	flags.StringVar(&backtestOptions.format, "format", string(application.EventsBacktestInputFormat), "input format, events or journal")
+	flags.StringVar(&backtestOptions.output, "output", "backtest", "directory of the output files")
+	flags.StringVar(&backtestOptions.start, "start", "1970-01-01T00:00:00Z", "time of the first input without a time")
+	flags.DurationVar(&backtestOptions.step, "step", time.Millisecond, "clock step between inputs without a time")
+	flags.DurationVar(&backtestOptions.snapshotInterval, "snapshot-interval", time.Minute, "simulated time between book snapshots, zero only snapshots at the end")
+	flags.IntVar(&backtestOptions.depthLevels, "depth", 10, "price levels of each side in a snapshot")
+	flags.StringVar(&backtestOptions.tickSize, "tick-size", "1", "price increment of every symbol")
+	flags.StringVar(&backtestOptions.lotSize, "lot-size", "1", "quantity increment of every symbol")
+	flags.StringVar(&backtestOptions.minOrderQuantity, "min-order-quantity", "0", "minimum order quantity of every symbol")
+	flags.StringVar(&backtestOptions.allocation, "allocation", string(order.FIFOAllocationAlgorithm), "allocation algorithm of every symbol")
+	flags.StringVar(&backtestOptions.minAllocation, "min-allocation", "0", "minimum pro-rata allocation")
+	flags.IntVar(&backtestOptions.makerFeeBps, "maker-fee-bps", 0, "maker fee rate of every account")
+	flags.IntVar(&backtestOptions.takerFeeBps, "taker-fee-bps", 0, "taker fee rate of every account")
+	flags.StringVar(&backtestOptions.stpMode, "stp-mode", "", "self-trade prevention mode of every account")
+	_ = backtestCmd.MarkFlagRequired("input")
+	rootCmd.AddCommand(backtestCmd)
+}
+
+var backtestCmd = &cobra.Command{
+	Use:   "backtest",
+	Short: "Run historical order flow through the matching core without Kafka and Postgres",
+	Run: func(cmd *cobra.Command, args []string) {
+		Backtest(backtestOptions)
+	},
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	configs "tradeTornado/config"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/service/wiring"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var exportOptions struct {
+	resource string
+	symbol   string
+	from     string
+	to       string
+	format   string
+	output   string
+}
+
+// Export writes the orders or trades created in [from, to) oldest first, the same rows the listings stream
+func Export(resource, symbol, from, to, formatName, output string) {
+	cnf := configs.ConfigFromEnv()
+	c := wiring.NewContainer(cnf)
+	if resource != "orders" && resource != "trades" {
+		logrus.Fatalf("unknown resource %s, expected orders or trades", resource)
+	}
+	format, err := lib.ParseExportFormat(formatName)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	criteria, err := exportCriteria(symbol, from, to)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	if output == "" {
+		output = fmt.Sprintf("%s.%s", resource, format.Extension())
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	ctx := lib.Terminable()
+	switch resource {
+	case "orders":
+		err = c.NewOrdereQueryHandler().ExportOrders(ctx, *criteria, format, file)
+	case "trades":
+		criteria.AddSort(lib.NewSort("id", lib.ASC))
+		err = c.NewTradeQueryHandler().ExportTrades(ctx, application.ListTradesQuery{Criteria: *criteria}, format, file)
+	}
+	if err = errors.Join(err, file.Close()); err != nil {
+		logrus.Fatalln(err)
+	}
+	logrus.WithField("resource", resource).WithField("output", output).Infoln("export finished")
+}
+
+func exportCriteria(symbol, from, to string) (*lib.Criteria, error) {
+	fromTime, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return nil, err
+	}
+	toTime := time.Now()
+	if to != "" {
+		if toTime, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, err
+		}
+	}
+	criteria := lib.NewCriteria()
+	criteria.AddFilter(lib.Filter{Field: "created_at", Operator: lib.GTEOperator, Value: []string{fromTime.UTC().Format(time.RFC3339Nano)}})
+	criteria.AddFilter(lib.Filter{Field: "created_at", Operator: lib.LTOperator, Value: []string{toTime.UTC().Format(time.RFC3339Nano)}})
+	if symbol != "" {
+		criteria.AddFilter(lib.Filter{Field: "symbol", Operator: lib.EqualOperator, Value: []string{symbol}})
+	}
+	criteria.AddSort(lib.NewSort("created_at", lib.ASC))
+	return criteria, nil
+}
+
+func init() {
+	flags := exportCmd.Flags()
+	flags.StringVar(&exportOptions.resource, "resource", "", "orders or trades")
+	flags.StringVar(&exportOptions.symbol, "symbol", "", "symbol of the rows, every symbol when empty")
+	flags.StringVar(&exportOptions.from, "from", "", "RFC 3339 time of the first row")
+	flags.StringVar(&exportOptions.to, "to", "", "RFC 3339 time after the last row, now when empty")
+	flags.StringVar(&exportOptions.format, "format", "csv", "csv or ndjson")
+	flags.StringVar(&exportOptions.output, "output", "", "file to write, <resource>.<format> when empty")
+	_ = exportCmd.MarkFlagRequired("resource")
+	_ = exportCmd.MarkFlagRequired("from")
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the orders or trades of a time range as CSV or JSON lines",
+	Run: func(cmd *cobra.Command, args []string) {
+		Export(exportOptions.resource, exportOptions.symbol, exportOptions.from, exportOptions.to, exportOptions.format, exportOptions.output)
+	},
+}
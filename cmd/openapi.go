@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	configs "tradeTornado/config"
+	"tradeTornado/internal/service/wiring"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var openAPIOptions struct {
+	output string
+}
+
+// OpenAPI writes the document the api server serves at /openapi.json
+func OpenAPI(output string) {
+	cnf := configs.ConfigFromEnv()
+	c := wiring.NewContainer(cnf)
+	document, err := json.MarshalIndent(c.OpenAPI(), "", "  ")
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	if err = os.WriteFile(output, append(document, '\n'), 0o644); err != nil {
+		logrus.Fatalln(err)
+	}
+	logrus.WithField("output", output).Infoln("openapi document written")
+}
+
+func init() {
+	openAPICmd.Flags().StringVar(&openAPIOptions.output, "output", "openapi.json", "file to write")
+	rootCmd.AddCommand(openAPICmd)
+}
+
+var openAPICmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Write the OpenAPI document of the api",
+	Run: func(cmd *cobra.Command, args []string) {
+		OpenAPI(openAPIOptions.output)
+	},
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	configs "tradeTornado/config"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/service/wiring"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var replayUntil uint64
+
+// Replay prints the report with the books at the last replayed sequence, it fails when the events diverged
+func Replay(until uint64) {
+	cnf := configs.ConfigFromEnv()
+	c := wiring.NewContainer(cnf)
+	report, err := c.NewJournalReplayer().Replay(lib.Terminable(), until)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logrus.Fatalln(err)
+	}
+	if len(report.Divergences) > 0 {
+		logrus.Fatalf("%d journal entries diverged", len(report.Divergences))
+	}
+}
+
+func init() {
+	replayCmd.Flags().Uint64Var(&replayUntil, "until", 0, "last sequence to replay, the whole journal when zero")
+	rootCmd.AddCommand(replayCmd)
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Replay the journal and verify the events",
+	Run: func(cmd *cobra.Command, args []string) {
+		Replay(replayUntil)
+	},
+}
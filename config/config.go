@@ -7,18 +7,38 @@ import (
 	"github.com/spf13/cast"
 )
 
+const (
+	ShardedMatcherMode       = "sharded"
+	ActiveStandbyMatcherMode = "active_standby"
+)
+
 type Configs struct {
-	AppName                  string
-	MasterDatabase           provider.PostgresConfig
-	SlaveDatabase            provider.PostgresConfig
-	MetricConfig             *provider.PrometheusConfig
-	IsProduction             bool
-	KafkaConsumerConfig      provider.KafkaConsumerConfig
-	KafkaProducerConfig      provider.KafkaProducerConfig
-	OrderCreateTopic         string
-	OrderMatchedTopic        string
-	OrderCreateConsumerGroup string
-	ServerConfigs            provider.ServerConfigs
+	AppName                      string
+	MasterDatabase               provider.PostgresConfig
+	SlaveDatabase                provider.PostgresConfig
+	MetricConfig                 *provider.PrometheusConfig
+	IsProduction                 bool
+	KafkaConsumerConfig          provider.KafkaConsumerConfig
+	KafkaProducerConfig          provider.KafkaProducerConfig
+	OrderCreateTopic             string
+	OrderMatchedTopic            string
+	OrderRejectedTopic           string
+	OrderSelfTradePreventedTopic string
+	OrderCreateConsumerGroup     string
+	FeeCollectionAccount         string
+	SessionSchedulerIntervalMS   int
+	DecimalScale                 int
+	// MatcherMode is sharded, every instance matches its partitions, or active_standby where only the leader matches
+	MatcherMode              string
+	LeaderLockKey            int64
+	LeaderElectionIntervalMS int
+	// JournalFollowIntervalMS is how often a standby reads the journal of the leader
+	JournalFollowIntervalMS int
+	MarketDataConsumerGroup string
+	TickerConsumerGroup     string
+	TickerTopic             string
+	TickerPublishIntervalMS int
+	ServerConfigs           provider.ServerConfigs
 }
 
 func ConfigFromEnv() Configs {
@@ -56,9 +76,22 @@ func ConfigFromEnv() Configs {
 		KafkaProducerConfig: provider.KafkaProducerConfig{
 			Brokers: lib.GetEnv("KAFKA_BROKERS", "localhost:29092"),
 		},
-		OrderCreateTopic:         lib.GetEnv("KAFKA_ORDER_CREATE_TOPIC", "order-events"),
-		OrderMatchedTopic:        lib.GetEnv("KAFKA_ORDER_MATCH_TOPIC", "order-matches"),
-		OrderCreateConsumerGroup: lib.GetEnv("KAFKA_ORDER_CREATE_CONSUMER_GROUP", "matcher"),
+		OrderCreateTopic:             lib.GetEnv("KAFKA_ORDER_CREATE_TOPIC", "order-events"),
+		OrderMatchedTopic:            lib.GetEnv("KAFKA_ORDER_MATCH_TOPIC", "order-matches"),
+		OrderRejectedTopic:           lib.GetEnv("KAFKA_ORDER_REJECT_TOPIC", "order-rejections"),
+		OrderSelfTradePreventedTopic: lib.GetEnv("KAFKA_ORDER_STP_TOPIC", "order-self-trade-preventions"),
+		OrderCreateConsumerGroup:     lib.GetEnv("KAFKA_ORDER_CREATE_CONSUMER_GROUP", "matcher"),
+		FeeCollectionAccount:         lib.GetEnv("FEE_COLLECTION_ACCOUNT", "fee-collector"),
+		SessionSchedulerIntervalMS:   cast.ToInt(lib.GetEnv("SESSION_SCHEDULER_INTERVAL_MS", "1000")),
+		DecimalScale:                 cast.ToInt(lib.GetEnv("DECIMAL_SCALE", "8")),
+		MatcherMode:                  lib.GetEnv("MATCHER_MODE", ShardedMatcherMode),
+		LeaderLockKey:                cast.ToInt64(lib.GetEnv("LEADER_LOCK_KEY", "7421")),
+		LeaderElectionIntervalMS:     cast.ToInt(lib.GetEnv("LEADER_ELECTION_INTERVAL_MS", "2000")),
+		JournalFollowIntervalMS:      cast.ToInt(lib.GetEnv("JOURNAL_FOLLOW_INTERVAL_MS", "500")),
+		MarketDataConsumerGroup:      lib.GetEnv("KAFKA_MARKETDATA_CONSUMER_GROUP", "marketdata"),
+		TickerConsumerGroup:          lib.GetEnv("KAFKA_TICKER_CONSUMER_GROUP", "marketdata-ticker"),
+		TickerTopic:                  lib.GetEnv("KAFKA_TICKER_TOPIC", "tickers"),
+		TickerPublishIntervalMS:      cast.ToInt(lib.GetEnv("TICKER_PUBLISH_INTERVAL_MS", "1000")),
 		ServerConfigs: provider.ServerConfigs{
 			Port:           lib.GetEnv("API_PORT", "8080"),
 			Name:           lib.GetEnv("API_NAME", "order-matcher"),
@@ -0,0 +1,229 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by requests with rules beyond their bindings, it is called once a request is bound
+type Validator interface {
+	Validate() error
+}
+
+var criteriaType = reflect.TypeOf(Criteria{})
+
+// Bind fills target, a pointer to a struct, from the request:
+//
+//	ID     uint         `path:"id"`
+//	Levels int          `query:"levels" default:"20"`
+//	Symbol string       `query:"symbol,required"`
+//	Accept string       `header:"Accept"`
+//	Filter lib.Criteria // the criteria query parameters
+//
+// The other exported fields are read from the JSON body of POST, PUT and PATCH requests, parameters are bound after
+// the body so they win. Every field that can not be bound is reported in an ErrorNotification
+func Bind(r *Request, target any) error {
+	value := reflect.ValueOf(target).Elem()
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("can not bind a request to %s", value.Type())
+	}
+	fields := requestFields(value.Type())
+	if len(fields.body) > 0 && hasBody(r.Method) {
+		if err := bindBody(r.Body, target); err != nil {
+			return err
+		}
+	}
+	validation := NewErrorNotification()
+	for _, field := range fields.params {
+		raw, ok := field.lookup(r)
+		if !ok {
+			if field.required {
+				validation.Add(field.name, errors.New("is required"))
+			}
+			continue
+		}
+		if err := setField(value.FieldByIndex(field.index), raw); err != nil {
+			validation.Add(field.name, err)
+		}
+	}
+	if err := validation.Err(); err != nil {
+		return err
+	}
+	for _, index := range fields.criteria {
+		criteria, err := ParseCriteria(r.Query)
+		if err != nil {
+			return err
+		}
+		value.FieldByIndex(index).Set(reflect.ValueOf(*criteria))
+	}
+	if validator, ok := target.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+type paramSource string
+
+const (
+	pathParam   paramSource = "path"
+	queryParam  paramSource = "query"
+	headerParam paramSource = "header"
+)
+
+// requestParam is a field bound from the path, the query or a header
+type requestParam struct {
+	source       paramSource
+	name         string
+	required     bool
+	defaultValue string
+	hasDefault   bool
+	index        []int
+	fieldType    reflect.Type
+}
+
+func (rp requestParam) lookup(r *Request) (string, bool) {
+	var raw string
+	switch rp.source {
+	case pathParam:
+		raw = r.Params[rp.name]
+	case queryParam:
+		raw = r.Query.Get(rp.name)
+	case headerParam:
+		raw = r.Header.Get(rp.name)
+	}
+	if raw == "" {
+		return rp.defaultValue, rp.hasDefault
+	}
+	return raw, true
+}
+
+type requestFieldSet struct {
+	params   []requestParam
+	criteria [][]int
+	body     []reflect.StructField
+}
+
+// requestFields sorts the fields of a request by where they are bound from, embedded structs included
+func requestFields(requestType reflect.Type) requestFieldSet {
+	var fields requestFieldSet
+	for _, field := range reflect.VisibleFields(requestType) {
+		if !field.IsExported() || (field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		if field.Type == criteriaType {
+			fields.criteria = append(fields.criteria, field.Index)
+			continue
+		}
+		param, ok := fieldParam(field)
+		if ok {
+			fields.params = append(fields.params, param)
+			continue
+		}
+		if field.Tag.Get("json") != "-" {
+			fields.body = append(fields.body, field)
+		}
+	}
+	return fields
+}
+
+func fieldParam(field reflect.StructField) (requestParam, bool) {
+	for _, source := range []paramSource{pathParam, queryParam, headerParam} {
+		tag, ok := field.Tag.Lookup(string(source))
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		param := requestParam{source: source, name: parts[0], index: field.Index, fieldType: field.Type}
+		for _, option := range parts[1:] {
+			param.required = param.required || option == "required"
+		}
+		// path parameters are always present in a matched route
+		param.required = param.required || source == pathParam
+		param.defaultValue, param.hasDefault = field.Tag.Lookup("default")
+		return param, true
+	}
+	return requestParam{}, false
+}
+
+func hasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+func bindBody(body io.Reader, target any) error {
+	validation := NewErrorNotification()
+	if body == nil {
+		validation.Add("body", errors.New("is required"))
+		return validation.Err()
+	}
+	if err := json.NewDecoder(body).Decode(target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			validation.Add("body", errors.New("is required"))
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			validation.Add(typeErr.Field, fmt.Errorf("should be %s", typeErr.Type))
+		default:
+			validation.Add("body", err)
+		}
+		return validation.Err()
+	}
+	return nil
+}
+
+// setField converts raw to the type of the field, decimals keep their scale and times are RFC 3339
+func setField(field reflect.Value, raw string) error {
+	switch field.Type() {
+	case decimalType:
+		decimal, err := ParseDecimal(raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(decimal))
+		return nil
+	case timeType:
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return errors.New("should be an RFC 3339 time")
+		}
+		field.Set(reflect.ValueOf(at))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("should be an integer")
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("should be a non negative integer")
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return errors.New("should be a number")
+		}
+		field.SetFloat(v)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("should be true or false")
+		}
+		field.SetBool(v)
+	default:
+		return fmt.Errorf("can not bind %s", field.Type())
+	}
+	return nil
+}
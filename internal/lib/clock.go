@@ -0,0 +1,40 @@
+package lib
+
+import (
+	"sync"
+	"time"
+)
+
+// IClock is the time source of code that has to run the same way online and offline
+type IClock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SimulatedClock only moves when it is set, replays and backtests set it to the time of every input
+type SimulatedClock struct {
+	lock sync.RWMutex
+	now  time.Time
+}
+
+func NewSimulatedClock(now time.Time) *SimulatedClock {
+	return &SimulatedClock{now: now}
+}
+
+func (sc *SimulatedClock) Now() time.Time {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+	return sc.now
+}
+
+// Set may move the clock backwards, inputs of independent symbols are not always journaled in time order
+func (sc *SimulatedClock) Set(now time.Time) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.now = now
+}
@@ -3,10 +3,10 @@ package lib
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
 	"strings"
 
-	"github.com/gin-gonic/gin"
 	"github.com/spf13/cast"
 	"gorm.io/gorm"
 )
@@ -14,14 +14,20 @@ import (
 type FilterOperator string
 
 var filterOperatorMap = map[string]FilterOperator{
-	"Equal":   EqualOperator,
-	"GT":      GTOperator,
-	"GTE":     GTEOperator,
-	"LT":      LTOperator,
-	"LTE":     LTEOperator,
-	"In":      InOperator,
-	"Between": BetweenOperator,
-	"Contain": ContainOperator,
+	"Equal":      EqualOperator,
+	"NotEqual":   NotEqualOperator,
+	"GT":         GTOperator,
+	"GTE":        GTEOperator,
+	"LT":         LTOperator,
+	"LTE":        LTEOperator,
+	"In":         InOperator,
+	"NotIn":      NotInOperator,
+	"Between":    BetweenOperator,
+	"Contain":    ContainOperator,
+	"IContain":   IContainOperator,
+	"StartsWith": StartsWithOperator,
+	"IsNull":     IsNullOperator,
+	"IsNotNull":  IsNotNullOperator,
 }
 
 func FilterOperatorFromStringName(name string) (FilterOperator, error) {
@@ -35,16 +41,27 @@ func FilterOperatorFromStringName(name string) (FilterOperator, error) {
 }
 
 const (
-	EqualOperator   FilterOperator = "="
-	GTOperator      FilterOperator = ">"
-	GTEOperator     FilterOperator = ">="
-	LTOperator      FilterOperator = "<"
-	LTEOperator     FilterOperator = "<="
-	InOperator      FilterOperator = "IN"
-	BetweenOperator FilterOperator = "between"
-	ContainOperator FilterOperator = "Contain"
+	EqualOperator      FilterOperator = "="
+	NotEqualOperator   FilterOperator = "<>"
+	GTOperator         FilterOperator = ">"
+	GTEOperator        FilterOperator = ">="
+	LTOperator         FilterOperator = "<"
+	LTEOperator        FilterOperator = "<="
+	InOperator         FilterOperator = "IN"
+	NotInOperator      FilterOperator = "NOT IN"
+	BetweenOperator    FilterOperator = "between"
+	ContainOperator    FilterOperator = "Contain"
+	IContainOperator   FilterOperator = "IContain"
+	StartsWithOperator FilterOperator = "StartsWith"
+	IsNullOperator     FilterOperator = "IS NULL"
+	IsNotNullOperator  FilterOperator = "IS NOT NULL"
 )
 
+// isPattern reports whether the operator matches text, it is only allowed on text fields
+func (fo FilterOperator) isPattern() bool {
+	return fo == ContainOperator || fo == IContainOperator || fo == StartsWithOperator
+}
+
 type Filter struct {
 	Field    string
 	Value    []string
@@ -66,11 +83,16 @@ func (fil Filter) validate() error {
 		if len(fil.Value) != 2 {
 			return fmt.Errorf("value length should be 2 for operator %s", fil.Operator)
 		}
-	case InOperator:
-		return nil
-		// Other operators
+	case InOperator, NotInOperator:
+		if len(fil.Value) == 0 {
+			return fmt.Errorf("value length should be at least 1 for operator %s", fil.Operator)
+		}
+	case IsNullOperator, IsNotNullOperator:
+		if len(fil.Value) != 0 {
+			return fmt.Errorf("operator %s takes no value", fil.Operator)
+		}
 	default:
-		if len(fil.Value) > 1 {
+		if len(fil.Value) != 1 {
 			return fmt.Errorf("value length should be 1 for operator %s", fil.Operator)
 		}
 	}
@@ -84,6 +106,21 @@ const (
 	Or  LogicalOperator = "OR"
 )
 
+// FilterGroup combines its filters and sub groups with one operator, an empty operator is AND
+type FilterGroup struct {
+	Operator LogicalOperator
+	Filters  []Filter
+	Groups   []FilterGroup
+}
+
+func NewFilterGroup(opr LogicalOperator) FilterGroup {
+	return FilterGroup{Operator: opr}
+}
+
+func (fg FilterGroup) isEmpty() bool {
+	return len(fg.Filters) == 0 && len(fg.Groups) == 0
+}
+
 type Pagination struct {
 	Offset uint
 	Limit  uint
@@ -103,6 +140,17 @@ const (
 	DESC SortOperator = "DESC"
 )
 
+// SortOperatorFromStringName accepts ASC or DESC in any case, the operator ends up in the ORDER BY clause as is
+func SortOperatorFromStringName(field, name string) (SortOperator, error) {
+	operator := SortOperator(strings.ToUpper(name))
+	if operator != ASC && operator != DESC {
+		validation := NewErrorNotification()
+		validation.Add(field, fmt.Errorf("sort operator should be %s or %s", ASC, DESC))
+		return "", validation.Err()
+	}
+	return operator, nil
+}
+
 type Sort struct {
 	Field    string
 	Operator SortOperator
@@ -115,11 +163,20 @@ func NewSort(f string, opr SortOperator) Sort {
 	}
 }
 
+// Criteria Operator combines the filters and the groups. Cursor continues a listing after the page it was given with,
+// the offset is ignored meanwhile. SkipTotal saves the count of the matching rows.
+// Fields projects the rows to some of their fields, GroupBy and Aggregates summarize them instead
 type Criteria struct {
 	Filters    []Filter
+	Groups     []FilterGroup
 	Operator   LogicalOperator
 	Pagination *Pagination
 	Sorts      []Sort
+	Cursor     string
+	SkipTotal  bool
+	Fields     []string
+	GroupBy    []string
+	Aggregates []Aggregate
 }
 
 func NewCriteria() *Criteria {
@@ -135,6 +192,25 @@ func (cr *Criteria) AddFilter(f Filter) *Criteria {
 	return cr
 }
 
+func (cr *Criteria) AddGroup(g FilterGroup) *Criteria {
+	cr.Groups = append(cr.Groups, g)
+	return cr
+}
+
+func (cr *Criteria) AddAggregate(a Aggregate) *Criteria {
+	cr.Aggregates = append(cr.Aggregates, a)
+	return cr
+}
+
+// IsProjection reports whether the rows are not listed whole
+func (cr *Criteria) IsProjection() bool {
+	return len(cr.Fields) > 0 || cr.IsAggregation()
+}
+
+func (cr *Criteria) IsAggregation() bool {
+	return len(cr.GroupBy) > 0 || len(cr.Aggregates) > 0
+}
+
 func (cr *Criteria) SetPagination(pg *Pagination) {
 	cr.Pagination = pg
 }
@@ -147,13 +223,13 @@ func (cr *Criteria) AddSort(sr Sort) {
 	cr.Sorts = append(cr.Sorts, sr)
 }
 
-func ParseCriteriaFromRequest(c *gin.Context) (*Criteria, error) {
+func ParseCriteria(query url.Values) (*Criteria, error) {
 	criteria := NewCriteria()
 
-	filters := c.QueryArray("filters")
+	filters := query["filters"]
 	for _, filterStr := range filters {
 		parts := strings.Split(filterStr, ",")
-		if len(parts) < 3 {
+		if len(parts) < 2 {
 			return nil, errors.New("invalid filter format, expected field,operator,value")
 		}
 
@@ -171,14 +247,34 @@ func ParseCriteriaFromRequest(c *gin.Context) (*Criteria, error) {
 		criteria.AddFilter(filter)
 	}
 
-	offset := c.Query("offset")
-	limit := c.Query("limit")
-	if offset != "" && limit != "" {
+	// where is a nested filter expression, JSON or compact
+	if where := query.Get("where"); where != "" {
+		group, err := ParseFilterGroup(where)
+		if err != nil {
+			return nil, err
+		}
+		criteria.AddGroup(group)
+	}
+
+	offset := query.Get("offset")
+	limit := query.Get("limit")
+	criteria.Cursor = query.Get("cursor")
+	if limit != "" {
 		pg := NewPagination(cast.ToUint(offset), cast.ToUint(limit))
 		criteria.SetPagination(pg)
 	}
 
-	sorts := c.QueryArray("sorts")
+	if withTotal := query.Get("withTotal"); withTotal != "" {
+		counted, err := cast.ToBoolE(withTotal)
+		if err != nil {
+			validation := NewErrorNotification()
+			validation.Add("withTotal", errors.New("should be true or false"))
+			return nil, validation.Err()
+		}
+		criteria.SkipTotal = !counted
+	}
+
+	sorts := query["sorts"]
 	for _, sortStr := range sorts {
 		parts := strings.Split(sortStr, ",")
 		if len(parts) != 2 {
@@ -186,13 +282,26 @@ func ParseCriteriaFromRequest(c *gin.Context) (*Criteria, error) {
 		}
 
 		field := parts[0]
-		operator := SortOperator(parts[1])
+		operator, err := SortOperatorFromStringName(field, parts[1])
+		if err != nil {
+			return nil, err
+		}
 		sort := NewSort(field, operator)
 		criteria.AddSort(sort)
 	}
 
+	criteria.Fields = queryList(query, "fields")
+	criteria.GroupBy = queryList(query, "groupBy")
+	for _, expression := range queryList(query, "agg") {
+		aggregate, err := ParseAggregate(expression)
+		if err != nil {
+			return nil, err
+		}
+		criteria.AddAggregate(aggregate)
+	}
+
 	// Parse Logical Operator
-	operator := c.Query("operator")
+	operator := query.Get("operator")
 	if operator != "" {
 		criteria.SetOperator(LogicalOperator(operator))
 	}
@@ -200,6 +309,19 @@ func ParseCriteriaFromRequest(c *gin.Context) (*Criteria, error) {
 	return criteria, nil
 }
 
+// queryList reads comma separated values of a repeatable query parameter
+func queryList(query url.Values, key string) []string {
+	var values []string
+	for _, raw := range query[key] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
 type ApplyGormCriteria func(qr *gorm.DB, structType any, criteria *Criteria) (*gorm.DB, error)
 
 func GenericApplyGormCriteria(qr *gorm.DB, structType any, criteria *Criteria) (*gorm.DB, error) {
@@ -207,47 +329,167 @@ func GenericApplyGormCriteria(qr *gorm.DB, structType any, criteria *Criteria) (
 	if err != nil {
 		return nil, err
 	}
+	qr, err = applyCursor(qr, structType, criteria)
+	if err != nil {
+		return nil, err
+	}
 	qr, err = applySorts(qr, structType, criteria)
 	if err != nil {
 		return nil, err
 	}
-	return applyPagination(qr, criteria)
+	qr, err = applyPagination(qr, criteria)
+	if err != nil {
+		return nil, err
+	}
+	return applyProjection(qr, structType, criteria)
 }
 
+// applyFilters adds the filters and groups of criteria as one parenthesized condition, so its operator never leaks into
+// the conditions the query already has
 func applyFilters(qr *gorm.DB, structType any, criteria *Criteria) (*gorm.DB, error) {
-	for _, f := range criteria.Filters {
-		fieldName, err := getFieldName(f.Field, structType)
+	condition, args, err := groupCondition(structType, FilterGroup{Operator: criteria.Operator, Filters: criteria.Filters, Groups: criteria.Groups})
+	if err != nil {
+		return nil, err
+	}
+	if condition != "" {
+		qr = qr.Where(condition, args...)
+	}
+	return qr, nil
+}
+
+func groupCondition(structType any, group FilterGroup) (string, []any, error) {
+	operator := LogicalOperator(strings.ToUpper(string(group.Operator)))
+	if operator == "" {
+		operator = And
+	}
+	if operator != And && operator != Or {
+		validation := NewErrorNotification()
+		validation.Add("operator", fmt.Errorf("should be %s or %s", And, Or))
+		return "", nil, validation.Err()
+	}
+	conditions := make([]string, 0, len(group.Filters)+len(group.Groups))
+	var args []any
+	for _, f := range group.Filters {
+		condition, filterArgs, err := filterCondition(structType, f)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
-		if f.Operator == ContainOperator {
-			f.Value[0] = "%" + f.Value[0] + "%"
-			f.Operator = "Like"
+		conditions = append(conditions, condition)
+		args = append(args, filterArgs...)
+	}
+	for _, sub := range group.Groups {
+		if sub.isEmpty() {
+			continue
 		}
-		if criteria.Operator == And {
-			if f.Operator == BetweenOperator && len(f.Value) == 2 {
-				qr = qr.Where(fmt.Sprintf("%s %s ? and ?", fieldName, f.Operator), f.Value[0], f.Value[1])
-			} else {
-				qr = qr.Where(fmt.Sprintf("%s %s ?", fieldName, f.Operator), f.Value)
-			}
-		} else {
-			if f.Operator == BetweenOperator && len(f.Value) == 2 {
-				qr = qr.Or(fmt.Sprintf("%s %s ? and ?", fieldName, f.Operator), f.Value[0], f.Value[1])
-			} else {
-				qr = qr.Or(fmt.Sprintf("%s %s ?", fieldName, f.Operator), f.Value)
-			}
+		condition, groupArgs, err := groupCondition(structType, sub)
+		if err != nil {
+			return "", nil, err
 		}
+		conditions = append(conditions, condition)
+		args = append(args, groupArgs...)
 	}
-	return qr, nil
+	switch len(conditions) {
+	case 0:
+		return "", nil, nil
+	case 1:
+		return conditions[0], args, nil
+	}
+	return "(" + strings.Join(conditions, fmt.Sprintf(" %s ", operator)) + ")", args, nil
 }
 
+func filterCondition(structType any, f Filter) (string, []any, error) {
+	if err := f.validate(); err != nil {
+		validation := NewErrorNotification()
+		validation.Add(f.Field, err)
+		return "", nil, validation.Err()
+	}
+	column, fieldType, err := getFilterField(f.Field, structType)
+	if err != nil {
+		return "", nil, err
+	}
+	values, err := filterValues(f, fieldType)
+	if err != nil {
+		return "", nil, err
+	}
+	switch f.Operator {
+	case IsNullOperator, IsNotNullOperator:
+		return fmt.Sprintf("%s %s", column, f.Operator), nil, nil
+	case BetweenOperator:
+		return fmt.Sprintf("%s between ? and ?", column), values, nil
+	case InOperator, NotInOperator:
+		return fmt.Sprintf("%s %s ?", column, f.Operator), []any{values}, nil
+	case ContainOperator:
+		return fmt.Sprintf("%s LIKE ?", column), []any{"%" + escapeLike(f.Value[0]) + "%"}, nil
+	case IContainOperator:
+		return fmt.Sprintf("%s ILIKE ?", column), []any{"%" + escapeLike(f.Value[0]) + "%"}, nil
+	case StartsWithOperator:
+		return fmt.Sprintf("%s LIKE ?", column), []any{escapeLike(f.Value[0]) + "%"}, nil
+	case EqualOperator, NotEqualOperator, GTOperator, GTEOperator, LTOperator, LTEOperator:
+		return fmt.Sprintf("%s %s ?", column, f.Operator), values, nil
+	}
+	validation := NewErrorNotification()
+	validation.Add("filter_operator", errors.New("invalid filter operator"))
+	return "", nil, validation.Err()
+}
+
+// escapeLike keeps the wildcards of a pattern value literal
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+var decimalType = reflect.TypeOf(Decimal(0))
+
+// filterValues converts the raw values to the type of the field, decimals are compared as numbers instead of text.
+// Pattern operators are only allowed on text fields
+func filterValues(f Filter, fieldType reflect.Type) ([]any, error) {
+	values := make([]any, len(f.Value))
+	for i, raw := range f.Value {
+		values[i] = raw
+	}
+	validation := NewErrorNotification()
+	if f.Operator.isPattern() && fieldType.Kind() != reflect.String {
+		validation.Add(f.Field, fmt.Errorf("%s is only supported on text fields", f.Operator))
+		return nil, validation.Err()
+	}
+	if fieldType != decimalType {
+		return values, nil
+	}
+	for i, raw := range f.Value {
+		value, err := ParseDecimal(raw)
+		if err != nil {
+			validation.Add(f.Field, err)
+			return nil, validation.Err()
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// applySorts ends the sorts of a paged listing with the primary key so pages and cursors are stable
 func applySorts(qr *gorm.DB, structType any, criteria *Criteria) (*gorm.DB, error) {
+	if criteria.IsAggregation() {
+		return applyAggregationSorts(qr, structType, criteria)
+	}
+	if criteria.Pagination != nil || criteria.Cursor != "" {
+		columns, err := keysetSorts(structType, criteria)
+		if err != nil {
+			return nil, err
+		}
+		for _, kc := range columns {
+			qr = qr.Order(kc.String())
+		}
+		return qr, nil
+	}
 	for _, sr := range criteria.Sorts {
 		sortField, err := getFieldName(sr.Field, structType)
 		if err != nil {
 			return nil, err
 		}
-		qr = qr.Order(fmt.Sprintf("%s %s", sortField, sr.Operator))
+		operator, err := SortOperatorFromStringName(sr.Field, string(sr.Operator))
+		if err != nil {
+			return nil, err
+		}
+		qr = qr.Order(fmt.Sprintf("%s %s", sortField, operator))
 	}
 	return qr, nil
 }
@@ -257,7 +499,10 @@ func applyPagination(qr *gorm.DB, criteria *Criteria) (*gorm.DB, error) {
 		if criteria.Pagination.Limit == 0 {
 			criteria.Pagination.Limit = 100
 		}
-		qr = qr.Limit(int(criteria.Pagination.Limit)).Offset(int(criteria.Pagination.Offset))
+		qr = qr.Limit(int(criteria.Pagination.Limit))
+		if criteria.Cursor == "" {
+			qr = qr.Offset(int(criteria.Pagination.Offset))
+		}
 	}
 	return qr, nil
 }
@@ -295,26 +540,31 @@ func getFieldValue(structType interface{}, i int) interface{} {
 }
 
 func getFieldName(field string, structType any) (string, error) {
-	fieldName, ok, err := getCriteriaName(field, structType)
+	fieldName, _, err := getFilterField(field, structType)
+	return fieldName, err
+}
+
+func getFilterField(field string, structType any) (string, reflect.Type, error) {
+	fieldName, fieldType, ok, err := getCriteriaName(field, structType)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	if !ok {
-		return "", fmt.Errorf("field %s dosn't have the criteria tag, you cant filter it", field)
+		return "", nil, fmt.Errorf("field %s dosn't have the criteria tag, you cant filter it", field)
 	}
-	return fieldName, nil
+	return fieldName, fieldType, nil
 }
 
-func getCriteriaName(field string, structType any) (string, bool, error) {
+func getCriteriaName(field string, structType any) (string, reflect.Type, bool, error) {
 	for i := 0; i < reflect.TypeOf(structType).NumField(); i++ {
 		gormTag := reflect.TypeOf(structType).Field(i).Tag.Get("gorm")
 		if isFieldGormEmbedded(gormTag) {
-			columnName, ok, err := getCriteriaName(field, getFieldValue(structType, i))
+			columnName, fieldType, ok, err := getCriteriaName(field, getFieldValue(structType, i))
 			if err != nil {
-				return "", false, err
+				return "", nil, false, err
 			}
 			if ok {
-				return columnName, true, nil
+				return columnName, fieldType, true, nil
 			} else {
 				continue
 			}
@@ -322,19 +572,45 @@ func getCriteriaName(field string, structType any) (string, bool, error) {
 
 		if reflect.TypeOf(structType).Field(i).Tag.Get("criteria") == field {
 			if gormTag == "" {
-				return "", false, fmt.Errorf("field %s doesn't have gorm tag, you cant filter it", field)
+				return "", nil, false, fmt.Errorf("field %s doesn't have gorm tag, you cant filter it", field)
 			}
 			if !isFieldIndexed(gormTag) {
-				return "", false, fmt.Errorf("field %s is not indexed, you cant filter it", field)
+				return "", nil, false, fmt.Errorf("field %s is not indexed, you cant filter it", field)
 			}
 			columnName, err := getFieldColumnName(gormTag)
 			if err != nil {
-				return "", false, err
+				return "", nil, false, err
 			}
-			return columnName, true, nil
+			return columnName, reflect.TypeOf(structType).Field(i).Type, true, nil
+		}
+	}
+	return "", nil, false, nil
+}
+
+// CriteriaFields lists the names criteria can filter, sort and project structType by, the criteria tagged fields with
+// an index, embedded structs included
+func CriteriaFields(structType any) []string {
+	return criteriaFields(reflect.TypeOf(structType))
+}
+
+func criteriaFields(structType reflect.Type) []string {
+	var fields []string
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		gormTag := field.Tag.Get("gorm")
+		if isFieldGormEmbedded(gormTag) {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			fields = append(fields, criteriaFields(embedded)...)
+			continue
+		}
+		if name := field.Tag.Get("criteria"); name != "" && isFieldIndexed(gormTag) {
+			fields = append(fields, name)
 		}
 	}
-	return "", false, nil
+	return fields
 }
 
 func getFieldColumnName(gormTag string) (string, error) {
@@ -56,6 +56,27 @@ func (suite *GenericGormCriteraTestSuit) TestGetFieldName() {
 	suite.NotNil(err)
 }
 
+func (suite *GenericGormCriteraTestSuit) TestDecimalFilterValues() {
+	name, fieldType, err := getFilterField("price", mockStruct{})
+	suite.Require().NoError(err)
+	suite.Equal("price", name)
+	filter, err := NewFilter("price", BetweenOperator, "1.5", "2")
+	suite.Require().NoError(err)
+	values, err := filterValues(filter, fieldType)
+	suite.Require().NoError(err)
+	suite.Equal([]any{MustParseDecimal("1.5"), NewDecimalFromInt(2)}, values)
+
+	filter, err = NewFilter("price", EqualOperator, "abc")
+	suite.Require().NoError(err)
+	_, err = filterValues(filter, fieldType)
+	suite.ErrorIs(err, NewErrorNotification())
+
+	filter, err = NewFilter("price", ContainOperator, "1")
+	suite.Require().NoError(err)
+	_, err = filterValues(filter, fieldType)
+	suite.ErrorIs(err, NewErrorNotification())
+}
+
 func (suite *GenericGormCriteraTestSuit) TestGetFieldColumnName() {
 	gormTag := "history:no;column:armin;test:yes"
 	name, err := getFieldColumnName(gormTag)
@@ -64,7 +85,8 @@ func (suite *GenericGormCriteraTestSuit) TestGetFieldColumnName() {
 }
 
 type mockStruct struct {
-	Name   string `criteria:"name"`
-	UserId string `criteria:"user_id" gorm:"column:user_id;uniqueIndex:type_user_id_user_feedback_unique_index"`
-	Type   string `criteria:"type" gorm:"column:type;uniqueIndex:type_user_id_user_feedback_unique_index"`
+	Name   string  `criteria:"name"`
+	UserId string  `criteria:"user_id" gorm:"column:user_id;uniqueIndex:type_user_id_user_feedback_unique_index"`
+	Type   string  `criteria:"type" gorm:"column:type;uniqueIndex:type_user_id_user_feedback_unique_index"`
+	Price  Decimal `criteria:"price" gorm:"column:price;index"`
 }
@@ -0,0 +1,210 @@
+package lib
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxDecimalScale keeps one integer digit available in an int64
+const MaxDecimalScale = 18
+
+var (
+	decimalScale  = 8
+	decimalFactor = int64(100000000)
+)
+
+// SetDecimalScale sets the number of fractional digits of every Decimal, it has to be called before any value is created
+func SetDecimalScale(scale int) error {
+	if scale < 0 || scale > MaxDecimalScale {
+		return fmt.Errorf("decimal scale should be between 0 and %d", MaxDecimalScale)
+	}
+	decimalScale = scale
+	decimalFactor = int64(math.Pow10(scale))
+	return nil
+}
+
+func DecimalScale() int {
+	return decimalScale
+}
+
+// Decimal is a fixed-point number stored as an integer count of 10^-scale units, addition, subtraction and
+// comparison use the plain operators and stay exact. Two Decimals must only be multiplied or divided with Mul and Div
+type Decimal int64
+
+func NewDecimalFromInt(v int64) Decimal {
+	return Decimal(v * decimalFactor)
+}
+
+// ParseDecimal reads a decimal literal, more fractional digits than the scale are rejected instead of rounded
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	// at most one leading sign, any other sign is left to fail as a digit
+	negative := strings.HasPrefix(s, "-")
+	if negative || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	integer, fraction, _ := strings.Cut(s, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if integer == "" && fraction == "" && !strings.Contains(s, "0") {
+		return 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	if len(fraction) > decimalScale {
+		return 0, fmt.Errorf("decimal %q has more than %d fractional digits", s, decimalScale)
+	}
+	digits := integer + fraction + strings.Repeat("0", decimalScale-len(fraction))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("decimal %q is out of range", s)
+	}
+	if negative {
+		v = -v
+	}
+	return Decimal(v), nil
+}
+
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Mul is the product truncated toward zero to the scale, it saturates instead of overflowing
+func (d Decimal) Mul(o Decimal) Decimal {
+	product := new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(int64(o)))
+	return saturate(product.Quo(product, big.NewInt(decimalFactor)))
+}
+
+// Div is the quotient truncated toward zero to the scale, it panics on a zero divisor like integer division
+func (d Decimal) Div(o Decimal) Decimal {
+	if o == 0 {
+		panic("decimal division by zero")
+	}
+	dividend := new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(decimalFactor))
+	return saturate(dividend.Quo(dividend, big.NewInt(int64(o))))
+}
+
+// MulFracCeil is d*o*num/den computed exactly and rounded toward positive infinity to the scale, den should be
+// positive. It saturates instead of overflowing
+func (d Decimal) MulFracCeil(o Decimal, num, den int64) Decimal {
+	product := new(big.Int).Mul(big.NewInt(int64(d)), big.NewInt(int64(o)))
+	product.Mul(product, big.NewInt(num))
+	quotient, remainder := new(big.Int).QuoRem(product, new(big.Int).Mul(big.NewInt(decimalFactor), big.NewInt(den)), new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return saturate(quotient)
+}
+
+func (d Decimal) MulInt(n int64) Decimal {
+	return d.Mul(NewDecimalFromInt(n))
+}
+
+func (d Decimal) DivInt(n int64) Decimal {
+	return d.Div(NewDecimalFromInt(n))
+}
+
+func (d Decimal) Abs() Decimal {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (d Decimal) IsZero() bool {
+	return d == 0
+}
+
+// IsMultipleOf reports whether d is a whole number of steps, a non positive step accepts every value
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	return step <= 0 || d%step == 0
+}
+
+// IntPart drops the fractional digits
+func (d Decimal) IntPart() int64 {
+	return int64(d) / decimalFactor
+}
+
+func (d Decimal) String() string {
+	v := int64(d)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	integer := strconv.FormatInt(v/decimalFactor, 10)
+	if decimalScale == 0 {
+		return sign + integer
+	}
+	fraction := strings.TrimRight(fmt.Sprintf("%0*d", decimalScale, v%decimalFactor), "0")
+	if fraction == "" {
+		return sign + integer
+	}
+	return sign + integer + "." + fraction
+}
+
+// MarshalJSON writes a JSON number so integer payloads stay compatible
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts numbers and quoted strings
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	parsed, err := ParseDecimal(strings.Trim(s, `"`))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d *Decimal) Scan(src any) error {
+	var err error
+	switch v := src.(type) {
+	case nil:
+		*d = 0
+	case int64:
+		*d = NewDecimalFromInt(v)
+	case float64:
+		*d, err = ParseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+	case []byte:
+		*d, err = ParseDecimal(string(v))
+	case string:
+		*d, err = ParseDecimal(v)
+	default:
+		err = fmt.Errorf("can not scan %T into decimal", src)
+	}
+	return err
+}
+
+// Value is the decimal literal so the column keeps the real number whatever the scale is
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (Decimal) GormDataType() string {
+	return "numeric"
+}
+
+func saturate(v *big.Int) Decimal {
+	if !v.IsInt64() {
+		if v.Sign() < 0 {
+			return Decimal(math.MinInt64)
+		}
+		return Decimal(math.MaxInt64)
+	}
+	return Decimal(v.Int64())
+}
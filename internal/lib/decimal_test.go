@@ -0,0 +1,91 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DecimalTestSuite struct {
+	suite.Suite
+}
+
+func TestDecimalTestSuite(t *testing.T) {
+	suite.Run(t, new(DecimalTestSuite))
+}
+
+func (suite *DecimalTestSuite) TestParseAndString() {
+	for input, expected := range map[string]string{
+		"0":           "0",
+		"0.0":         "0",
+		"1":           "1",
+		"-1.5":        "-1.5",
+		"0.0001":      "0.0001",
+		"12.34000000": "12.34",
+		".5":          "0.5",
+		"+5":          "5",
+		"-5":          "-5",
+	} {
+		d, err := ParseDecimal(input)
+		suite.Require().NoError(err, input)
+		suite.Equal(expected, d.String(), input)
+	}
+	for _, input := range []string{"", "-", "1.2.3", "abc", "0.000000001", "99999999999999999999", "+-5", "--5", "-+5", "++5", "5-"} {
+		_, err := ParseDecimal(input)
+		suite.Error(err, input)
+	}
+}
+
+func (suite *DecimalTestSuite) TestArithmeticIsExact() {
+	a, b := MustParseDecimal("0.1"), MustParseDecimal("0.2")
+	suite.Equal(MustParseDecimal("0.3"), a+b)
+	suite.Equal(MustParseDecimal("0.02"), a.Mul(b))
+	suite.Equal(MustParseDecimal("0.5"), a.Div(b))
+	suite.Equal(MustParseDecimal("0.33333333"), NewDecimalFromInt(1).DivInt(3))
+	suite.Equal(MustParseDecimal("-0.03"), MustParseDecimal("-0.1").Mul(MustParseDecimal("0.3")))
+	suite.True(MustParseDecimal("1.5").IsMultipleOf(MustParseDecimal("0.25")))
+	suite.False(MustParseDecimal("1.6").IsMultipleOf(MustParseDecimal("0.25")))
+}
+
+func (suite *DecimalTestSuite) TestMulFracCeilRoundsUpTheExactProduct() {
+	price, quantity := MustParseDecimal("100.00000001"), MustParseDecimal("0.3")
+	suite.Equal(MustParseDecimal("0.03000001"), price.MulFracCeil(quantity, 10, 10000))
+	suite.Equal(MustParseDecimal("-0.03"), price.MulFracCeil(quantity, -10, 10000))
+	suite.Equal(MustParseDecimal("0.03"), NewDecimalFromInt(100).MulFracCeil(quantity, 10, 10000))
+	suite.Equal(MustParseDecimal("0.00000001"), MustParseDecimal("0.00000001").MulFracCeil(MustParseDecimal("0.00000001"), 1, 1))
+	suite.Equal(Decimal(0), NewDecimalFromInt(100).MulFracCeil(quantity, 0, 10000))
+}
+
+func (suite *DecimalTestSuite) TestJSON() {
+	var payload struct {
+		Price    Decimal `json:"price"`
+		Quantity Decimal `json:"quantity"`
+	}
+	suite.Require().NoError(json.Unmarshal([]byte(`{"price": 10, "quantity": "0.25"}`), &payload))
+	suite.Equal(NewDecimalFromInt(10), payload.Price)
+	suite.Equal(MustParseDecimal("0.25"), payload.Quantity)
+	bts, err := json.Marshal(payload)
+	suite.Require().NoError(err)
+	suite.JSONEq(`{"price": 10, "quantity": 0.25}`, string(bts))
+}
+
+func (suite *DecimalTestSuite) TestScanAndValue() {
+	var d Decimal
+	suite.NoError(d.Scan([]byte("1.25")))
+	suite.Equal(MustParseDecimal("1.25"), d)
+	suite.NoError(d.Scan(int64(3)))
+	suite.Equal(NewDecimalFromInt(3), d)
+	value, err := MustParseDecimal("-0.5").Value()
+	suite.NoError(err)
+	suite.Equal("-0.5", value)
+}
+
+func (suite *DecimalTestSuite) TestScale() {
+	defer func() { suite.Require().NoError(SetDecimalScale(8)) }()
+	suite.Require().NoError(SetDecimalScale(2))
+	suite.Equal(Decimal(150), MustParseDecimal("1.5"))
+	_, err := ParseDecimal("1.505")
+	suite.Error(err)
+	suite.Error(SetDecimalScale(19))
+}
@@ -0,0 +1,242 @@
+package lib
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportFormat is the media type of an export, rows are written one at a time in either format
+type ExportFormat string
+
+const (
+	CSVExportFormat    ExportFormat = "text/csv"
+	NDJSONExportFormat ExportFormat = "application/x-ndjson"
+)
+
+var exportFormatNames = map[string]ExportFormat{
+	"csv":    CSVExportFormat,
+	"ndjson": NDJSONExportFormat,
+}
+
+// ParseExportFormat reads csv or ndjson
+func ParseExportFormat(name string) (ExportFormat, error) {
+	format, ok := exportFormatNames[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unknown export format %s, expected csv or ndjson", name)
+	}
+	return format, nil
+}
+
+// Extension is the file extension of the format without the dot
+func (ef ExportFormat) Extension() string {
+	for name, format := range exportFormatNames {
+		if format == ef {
+			return name
+		}
+	}
+	return ""
+}
+
+// NegotiateExportFormat picks the export format the Accept header prefers, ok is false when JSON or any type is
+// preferred so listings answer JSON by default
+func NegotiateExportFormat(accept string) (ExportFormat, bool) {
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	for _, mr := range ranges {
+		switch ExportFormat(mr.mediaType) {
+		case CSVExportFormat, NDJSONExportFormat:
+			return ExportFormat(mr.mediaType), true
+		}
+		if mr.mediaType == "application/json" || strings.HasSuffix(mr.mediaType, "/*") {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// RowWriter writes the rows of an export, a row is a Row or a struct whose exported fields are the columns.
+// Close flushes the rows still buffered
+type RowWriter interface {
+	Write(row any) error
+	Close() error
+}
+
+// NewRowWriter starts an export of rows with the columns, a CSV export begins with them as its header
+func NewRowWriter(format ExportFormat, w io.Writer, columns []string) (RowWriter, error) {
+	switch format {
+	case CSVExportFormat:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+		return &csvRowWriter{writer: writer, columns: columns}, nil
+	case NDJSONExportFormat:
+		buffer := bufio.NewWriter(w)
+		return &ndjsonRowWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %s", format)
+}
+
+// ExportColumns are the exported fields of the struct, fields of embedded structs included
+func ExportColumns(structType any) []string {
+	var columns []string
+	structFields(reflect.TypeOf(structType), func(field reflect.StructField, _ []int) {
+		columns = append(columns, field.Name)
+	})
+	return columns
+}
+
+func structFields(structType reflect.Type, each func(field reflect.StructField, index []int)) {
+	for structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			continue
+		}
+		each(field, field.Index)
+	}
+}
+
+type csvRowWriter struct {
+	writer  *csv.Writer
+	columns []string
+}
+
+func (cw *csvRowWriter) Write(row any) error {
+	record := make([]string, 0, len(cw.columns))
+	if projected, ok := row.(Row); ok {
+		for _, column := range cw.columns {
+			record = append(record, csvValue(projected[column]))
+		}
+		return cw.writer.Write(record)
+	}
+	value := reflect.Indirect(reflect.ValueOf(row))
+	structFields(value.Type(), func(_ reflect.StructField, index []int) {
+		record = append(record, csvValue(value.FieldByIndex(index).Interface()))
+	})
+	return cw.writer.Write(record)
+}
+
+func (cw *csvRowWriter) Close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+// csvValue writes times as RFC 3339 and NULL as an empty cell
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
+type ndjsonRowWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (nw *ndjsonRowWriter) Write(row any) error {
+	return nw.encoder.Encode(row)
+}
+
+func (nw *ndjsonRowWriter) Close() error {
+	return nw.buffer.Flush()
+}
+
+// ExportResponse streams the rows export writes in the format
+func ExportResponse(format ExportFormat, export func(w io.Writer) error) *Response {
+	return StreamResponse(string(format), export)
+}
+
+// GenericStreamGormCriteria reads the rows of criteria one at a time without paging them unless the criteria does,
+// every row is scanned into row, a pointer to structType, before each is called
+func GenericStreamGormCriteria(qr *gorm.DB, structType any, criteria Criteria, row any, each func() error) error {
+	qr, err := GenericApplyGormCriteria(qr, structType, &criteria)
+	if err != nil {
+		return err
+	}
+	rows, err := qr.Model(structType).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	target := reflect.ValueOf(row).Elem()
+	for rows.Next() {
+		target.SetZero()
+		if err := qr.ScanRows(rows, row); err != nil {
+			return err
+		}
+		if err := each(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GenericStreamProjectionGormCriteria reads the projected rows of criteria one at a time
+func GenericStreamProjectionGormCriteria(qr *gorm.DB, structType any, criteria Criteria, each func(row Row) error) error {
+	columns, err := projectedColumns(structType, &criteria)
+	if err != nil {
+		return err
+	}
+	qr, err = GenericApplyGormCriteria(qr, structType, &criteria)
+	if err != nil {
+		return err
+	}
+	rows, err := qr.Model(structType).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		row, err := scanProjectedRow(rows.Scan, columns)
+		if err != nil {
+			return err
+		}
+		if err := each(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
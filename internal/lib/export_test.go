@@ -0,0 +1,66 @@
+package lib
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExportTestSuite struct {
+	suite.Suite
+}
+
+func TestExportTestSuite(t *testing.T) {
+	suite.Run(t, new(ExportTestSuite))
+}
+
+func (suite *ExportTestSuite) TestNegotiateExportFormat() {
+	negotiated := map[string]ExportFormat{
+		"text/csv":                                 CSVExportFormat,
+		"application/x-ndjson":                     NDJSONExportFormat,
+		"text/csv; charset=utf-8":                  CSVExportFormat,
+		"application/json;q=0.5, text/csv":         CSVExportFormat,
+		"text/html, application/x-ndjson;q=0.9":    NDJSONExportFormat,
+		"text/csv;q=0, application/x-ndjson;q=0.1": NDJSONExportFormat,
+	}
+	for accept, expected := range negotiated {
+		format, ok := NegotiateExportFormat(accept)
+		suite.True(ok, accept)
+		suite.Equal(expected, format, accept)
+	}
+	for _, accept := range []string{"", "*/*", "application/json", "application/json, text/csv;q=0.5", "text/*, text/csv;q=0.1", "text/csv;q=0"} {
+		_, ok := NegotiateExportFormat(accept)
+		suite.False(ok, accept)
+	}
+}
+
+func (suite *ExportTestSuite) TestCSVWritesProjectedRowsInTheirColumnOrder() {
+	var buffer bytes.Buffer
+	writer, err := NewRowWriter(CSVExportFormat, &buffer, []string{"side", "sum_quantity", "count"})
+	suite.Require().NoError(err)
+	suite.Require().NoError(writer.Write(Row{"count": int64(2), "side": "buy", "sum_quantity": NewDecimalFromInt(3)}))
+	suite.Require().NoError(writer.Write(Row{"count": int64(0), "side": "sell, short", "sum_quantity": nil}))
+	suite.Require().NoError(writer.Close())
+	suite.Equal("side,sum_quantity,count\nbuy,3,2\n\"sell, short\",,0\n", buffer.String())
+}
+
+func (suite *ExportTestSuite) TestExportColumnsFlattenEmbeddedStructs() {
+	type summary struct {
+		ID    uint
+		Price Decimal
+	}
+	type detail struct {
+		summary
+		Open   Decimal
+		hidden bool
+	}
+	suite.Equal([]string{"ID", "Price", "Open"}, ExportColumns(detail{}))
+
+	var buffer bytes.Buffer
+	writer, err := NewRowWriter(CSVExportFormat, &buffer, ExportColumns(detail{}))
+	suite.Require().NoError(err)
+	suite.Require().NoError(writer.Write(&detail{summary: summary{ID: 1, Price: NewDecimalFromInt(5)}, Open: NewDecimalFromInt(2)}))
+	suite.Require().NoError(writer.Close())
+	suite.Equal("ID,Price,Open\n1,5,2\n", buffer.String())
+}
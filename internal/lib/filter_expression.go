@@ -0,0 +1,376 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ParseFilterGroup reads a nested filter expression, either JSON or compact.
+//
+// JSON mirrors FilterGroup with the operator names of the filters parameter:
+//
+//	{"operator":"OR","filters":[{"field":"matched","operator":"Equal","value":["true"]}],
+//	 "groups":[{"operator":"AND","filters":[{"field":"side","operator":"Equal","value":["buy"]}]}]}
+//
+// The compact syntax combines comparisons with AND, OR and parentheses, AND binds tighter:
+//
+//	(side=buy AND price>5) OR matched=true
+//
+// Comparisons are =, !=, >, >=, <, <=, [NOT] IN (a, b), BETWEEN a AND b, IS [NOT] NULL, CONTAINS, ICONTAINS and
+// STARTSWITH. Values with spaces or symbols are quoted with ' or ", a quote is doubled inside them
+func ParseFilterGroup(expression string) (FilterGroup, error) {
+	expression = strings.TrimSpace(expression)
+	if strings.HasPrefix(expression, "{") {
+		return parseJSONFilterGroup(expression)
+	}
+	tokens, err := tokenizeFilterExpression(expression)
+	if err != nil {
+		return FilterGroup{}, whereError(err)
+	}
+	parser := &filterExpressionParser{tokens: tokens}
+	group, err := parser.parseOr()
+	if err == nil && !parser.done() {
+		err = fmt.Errorf("unexpected %q", parser.peek().text)
+	}
+	if err != nil {
+		return FilterGroup{}, whereError(err)
+	}
+	return group, nil
+}
+
+type jsonFilter struct {
+	Field    string   `json:"field"`
+	Operator string   `json:"operator"`
+	Value    []string `json:"value"`
+}
+
+type jsonFilterGroup struct {
+	Operator string            `json:"operator"`
+	Filters  []jsonFilter      `json:"filters"`
+	Groups   []jsonFilterGroup `json:"groups"`
+}
+
+func parseJSONFilterGroup(expression string) (FilterGroup, error) {
+	var raw jsonFilterGroup
+	if err := json.Unmarshal([]byte(expression), &raw); err != nil {
+		return FilterGroup{}, whereError(err)
+	}
+	return raw.filterGroup()
+}
+
+func (jg jsonFilterGroup) filterGroup() (FilterGroup, error) {
+	group := NewFilterGroup(LogicalOperator(strings.ToUpper(jg.Operator)))
+	for _, jf := range jg.Filters {
+		operator, err := FilterOperatorFromStringName(jf.Operator)
+		if err != nil {
+			return FilterGroup{}, err
+		}
+		filter, err := NewFilter(jf.Field, operator, jf.Value...)
+		if err != nil {
+			return FilterGroup{}, whereError(err)
+		}
+		group.Filters = append(group.Filters, filter)
+	}
+	for _, sub := range jg.Groups {
+		subGroup, err := sub.filterGroup()
+		if err != nil {
+			return FilterGroup{}, err
+		}
+		group.Groups = append(group.Groups, subGroup)
+	}
+	return group, nil
+}
+
+func whereError(err error) error {
+	validation := NewErrorNotification()
+	validation.Add("where", err)
+	return validation.Err()
+}
+
+type filterTokenKind int
+
+const (
+	wordFilterToken filterTokenKind = iota
+	quotedFilterToken
+	symbolFilterToken
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+// is reports whether the token is the keyword or symbol, keywords are case-insensitive and never quoted
+func (ft filterToken) is(text string) bool {
+	return ft.kind != quotedFilterToken && strings.EqualFold(ft.text, text)
+}
+
+var filterComparisons = map[string]FilterOperator{
+	"=":  EqualOperator,
+	"!=": NotEqualOperator,
+	"<>": NotEqualOperator,
+	">":  GTOperator,
+	">=": GTEOperator,
+	"<":  LTOperator,
+	"<=": LTEOperator,
+}
+
+var filterPatterns = map[string]FilterOperator{
+	"CONTAINS":   ContainOperator,
+	"ICONTAINS":  IContainOperator,
+	"STARTSWITH": StartsWithOperator,
+}
+
+func tokenizeFilterExpression(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, filterToken{kind: symbolFilterToken, text: string(r)})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			end := i + 1
+			if end < len(runes) && strings.ContainsRune("=>", runes[end]) {
+				end++
+			}
+			symbol := string(runes[i:end])
+			if _, ok := filterComparisons[symbol]; !ok {
+				return nil, fmt.Errorf("unknown comparison %q", symbol)
+			}
+			tokens = append(tokens, filterToken{kind: symbolFilterToken, text: symbol})
+			i = end
+		case r == '\'' || r == '"':
+			var value strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, errors.New("unterminated quoted value")
+				}
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						value.WriteRune(r)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: quotedFilterToken, text: value.String()})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()=!<>,'\"", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: wordFilterToken, text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+type filterExpressionParser struct {
+	tokens   []filterToken
+	position int
+}
+
+func (p *filterExpressionParser) done() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *filterExpressionParser) peek() filterToken {
+	if p.done() {
+		return filterToken{kind: symbolFilterToken}
+	}
+	return p.tokens[p.position]
+}
+
+func (p *filterExpressionParser) next() (filterToken, error) {
+	if p.done() {
+		return filterToken{}, errors.New("unexpected end of expression")
+	}
+	token := p.tokens[p.position]
+	p.position++
+	return token, nil
+}
+
+func (p *filterExpressionParser) expect(text string) error {
+	token, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !token.is(text) {
+		return fmt.Errorf("expected %q, got %q", text, token.text)
+	}
+	return nil
+}
+
+func (p *filterExpressionParser) parseOr() (FilterGroup, error) {
+	return p.parseChain(Or, p.parseAnd)
+}
+
+func (p *filterExpressionParser) parseAnd() (FilterGroup, error) {
+	return p.parseChain(And, p.parseFactor)
+}
+
+// parseChain reads operands joined by operator, operands with the same operator are flattened into one group
+func (p *filterExpressionParser) parseChain(operator LogicalOperator, operand func() (FilterGroup, error)) (FilterGroup, error) {
+	first, err := operand()
+	if err != nil {
+		return FilterGroup{}, err
+	}
+	if !p.peek().is(string(operator)) {
+		return first, nil
+	}
+	chain := NewFilterGroup(operator)
+	chain.join(first)
+	for p.peek().is(string(operator)) {
+		p.position++
+		next, err := operand()
+		if err != nil {
+			return FilterGroup{}, err
+		}
+		chain.join(next)
+	}
+	return chain, nil
+}
+
+func (fg *FilterGroup) join(other FilterGroup) {
+	if other.Operator == fg.Operator || (len(other.Filters) == 1 && len(other.Groups) == 0) {
+		fg.Filters = append(fg.Filters, other.Filters...)
+		fg.Groups = append(fg.Groups, other.Groups...)
+		return
+	}
+	fg.Groups = append(fg.Groups, other)
+}
+
+func (p *filterExpressionParser) parseFactor() (FilterGroup, error) {
+	if p.peek().is("(") {
+		p.position++
+		group, err := p.parseOr()
+		if err != nil {
+			return FilterGroup{}, err
+		}
+		return group, p.expect(")")
+	}
+	filter, err := p.parseComparison()
+	if err != nil {
+		return FilterGroup{}, err
+	}
+	return FilterGroup{Operator: And, Filters: []Filter{filter}}, nil
+}
+
+func (p *filterExpressionParser) parseComparison() (Filter, error) {
+	field, err := p.next()
+	if err != nil {
+		return Filter{}, err
+	}
+	if field.kind != wordFilterToken {
+		return Filter{}, fmt.Errorf("expected a field, got %q", field.text)
+	}
+	token, err := p.next()
+	if err != nil {
+		return Filter{}, err
+	}
+	if operator, ok := filterComparisons[token.text]; ok && token.kind == symbolFilterToken {
+		value, err := p.parseValue()
+		if err != nil {
+			return Filter{}, err
+		}
+		return NewFilter(field.text, operator, value)
+	}
+	if token.kind == quotedFilterToken {
+		return Filter{}, fmt.Errorf("expected a comparison after %s, got %q", field.text, token.text)
+	}
+	if operator, ok := filterPatterns[strings.ToUpper(token.text)]; ok {
+		value, err := p.parseValue()
+		if err != nil {
+			return Filter{}, err
+		}
+		return NewFilter(field.text, operator, value)
+	}
+	switch {
+	case token.is("IS"):
+		operator := IsNullOperator
+		if p.peek().is("NOT") {
+			p.position++
+			operator = IsNotNullOperator
+		}
+		if err := p.expect("NULL"); err != nil {
+			return Filter{}, err
+		}
+		return NewFilter(field.text, operator)
+	case token.is("NOT"):
+		if err := p.expect("IN"); err != nil {
+			return Filter{}, err
+		}
+		values, err := p.parseList()
+		if err != nil {
+			return Filter{}, err
+		}
+		return NewFilter(field.text, NotInOperator, values...)
+	case token.is("IN"):
+		values, err := p.parseList()
+		if err != nil {
+			return Filter{}, err
+		}
+		return NewFilter(field.text, InOperator, values...)
+	case token.is("BETWEEN"):
+		from, err := p.parseValue()
+		if err != nil {
+			return Filter{}, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return Filter{}, err
+		}
+		to, err := p.parseValue()
+		if err != nil {
+			return Filter{}, err
+		}
+		return NewFilter(field.text, BetweenOperator, from, to)
+	}
+	return Filter{}, fmt.Errorf("expected a comparison after %s, got %q", field.text, token.text)
+}
+
+func (p *filterExpressionParser) parseValue() (string, error) {
+	token, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if token.kind == symbolFilterToken {
+		return "", fmt.Errorf("expected a value, got %q", token.text)
+	}
+	return token.text, nil
+}
+
+func (p *filterExpressionParser) parseList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		token, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if token.is(")") {
+			return values, nil
+		}
+		if !token.is(",") {
+			return nil, fmt.Errorf("expected \",\" or \")\", got %q", token.text)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type filterRow struct {
+	ID      uint    `gorm:"primarykey;column:id"`
+	Side    string  `criteria:"side" gorm:"column:side;index"`
+	Price   Decimal `criteria:"price" gorm:"column:price;index"`
+	Matched bool    `criteria:"matched" gorm:"column:matched;index"`
+	Note    *string `criteria:"note" gorm:"column:note;index"`
+}
+
+type FilterExpressionTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+}
+
+func TestFilterExpressionTestSuite(t *testing.T) {
+	suite.Run(t, new(FilterExpressionTestSuite))
+}
+
+func (suite *FilterExpressionTestSuite) SetupTest() {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	suite.Require().NoError(err)
+	suite.db = db
+}
+
+func (suite *FilterExpressionTestSuite) where(criteria *Criteria) (string, error) {
+	var err error
+	sql := suite.db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var qr *gorm.DB
+		if qr, err = GenericApplyGormCriteria(tx.Table("rows").Where("id > ?", 0), filterRow{}, criteria); err != nil {
+			return tx
+		}
+		return qr.Find(&[]*filterRow{})
+	})
+	return sql, err
+}
+
+func (suite *FilterExpressionTestSuite) TestCompactExpressionNestsGroups() {
+	group, err := ParseFilterGroup("(side=buy AND price>5) OR matched = true")
+	suite.Require().NoError(err)
+	suite.Equal(Or, group.Operator)
+	suite.Equal([]Filter{{Field: "matched", Operator: EqualOperator, Value: []string{"true"}}}, group.Filters)
+	suite.Require().Len(group.Groups, 1)
+	suite.Equal(And, group.Groups[0].Operator)
+	suite.Len(group.Groups[0].Filters, 2)
+
+	sql, err := suite.where(NewCriteria().AddGroup(group))
+	suite.Require().NoError(err)
+	suite.Contains(sql, `WHERE id > 0 AND ((matched = 'true' OR (side = 'buy' AND price > '5')))`)
+}
+
+func (suite *FilterExpressionTestSuite) TestJSONExpressionMatchesTheCompactOne() {
+	compact, err := ParseFilterGroup("(side=buy AND price>5) OR matched=true")
+	suite.Require().NoError(err)
+	group, err := ParseFilterGroup(`{"operator":"or","filters":[{"field":"matched","operator":"Equal","value":["true"]}],
+		"groups":[{"operator":"AND","filters":[{"field":"side","operator":"Equal","value":["buy"]},{"field":"price","operator":"GT","value":["5"]}]}]}`)
+	suite.Require().NoError(err)
+	suite.Equal(compact, group)
+}
+
+func (suite *FilterExpressionTestSuite) TestOrFiltersStayInsideTheirGroup() {
+	criteria := NewCriteria()
+	criteria.SetOperator(Or)
+	criteria.AddFilter(Filter{Field: "side", Operator: EqualOperator, Value: []string{"buy"}})
+	criteria.AddFilter(Filter{Field: "side", Operator: EqualOperator, Value: []string{"sell"}})
+
+	sql, err := suite.where(criteria)
+	suite.Require().NoError(err)
+	suite.Contains(sql, `WHERE id > 0 AND ((side = 'buy' OR side = 'sell'))`)
+}
+
+func (suite *FilterExpressionTestSuite) TestOperators() {
+	group, err := ParseFilterGroup(`side NOT IN (buy, 'sell side') AND note IS NOT NULL AND side STARTSWITH 'b_%' AND side icontains 'it''s' AND price != 3 AND price BETWEEN 1 AND 2`)
+	suite.Require().NoError(err)
+
+	sql, err := suite.where(NewCriteria().AddGroup(group))
+	suite.Require().NoError(err)
+	suite.Contains(sql, `(side NOT IN ('buy','sell side') AND note IS NOT NULL AND side LIKE 'b\_\%%' AND side ILIKE '%it''s%' AND price <> '3' AND price between '1' and '2')`)
+}
+
+func (suite *FilterExpressionTestSuite) TestInvalidExpressions() {
+	var notification *ErrorNotification
+	for _, expression := range []string{"side=", "(side=buy", "side ~ buy", "side=buy OR", "side IS buy", `{"filters":[{"field":"side","operator":"Like"}]}`} {
+		_, err := ParseFilterGroup(expression)
+		suite.ErrorAs(err, &notification, expression)
+	}
+
+	group, err := ParseFilterGroup("price CONTAINS 5")
+	suite.Require().NoError(err)
+	_, err = suite.where(NewCriteria().AddGroup(group))
+	suite.ErrorAs(err, &notification)
+
+	group, err = ParseFilterGroup("unknown = 5")
+	suite.Require().NoError(err)
+	_, err = suite.where(NewCriteria().AddGroup(group))
+	suite.Error(err)
+}
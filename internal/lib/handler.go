@@ -0,0 +1,150 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// Request is an http request apart from the framework serving it, Params are the values of the :name segments of the
+// route path
+type Request struct {
+	Context context.Context
+	Method  string
+	Path    string
+	Params  map[string]string
+	Query   url.Values
+	Header  http.Header
+	Body    io.Reader
+}
+
+// Response is answered as JSON unless it streams. A stream only starts the response with its first write so an error
+// before it is still answered as an error
+type Response struct {
+	Status int
+	Header http.Header
+	Body   any
+	Stream func(w io.Writer) error
+}
+
+func JSONResponse(status int, body any) *Response {
+	return &Response{Status: status, Body: body}
+}
+
+// StreamResponse answers the rows stream writes with the content type
+func StreamResponse(contentType string, stream func(w io.Writer) error) *Response {
+	return &Response{Status: http.StatusOK, Header: http.Header{"Content-Type": []string{contentType}}, Stream: stream}
+}
+
+// ErrorBody is the response of a failed request, Errors has the message of every field of an ErrorNotification
+type ErrorBody struct {
+	Error  string            `json:"error"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ErrorResponse answers err with the status of HttpStatusFromError
+func ErrorResponse(err error) *Response {
+	body := ErrorBody{Error: err.Error()}
+	var notification *ErrorNotification
+	if errors.As(err, &notification) {
+		body.Errors = make(map[string]string, len(notification.Errs))
+		for field, fieldErr := range notification.Errs {
+			body.Errors[field] = fieldErr.Error()
+		}
+	}
+	return JSONResponse(HttpStatusFromError(err), body)
+}
+
+// HandlerFunc answers a request, routes are served through one
+type HandlerFunc func(r *Request) *Response
+
+// Middleware wraps the routes of a controller, it can answer a request itself or change the response of next
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// ControllerHandler serves the route behind the middlewares of its controller, the first middleware is the outermost
+func ControllerHandler(controller IController, route Route) HandlerFunc {
+	handler := HandlerFunc(route.Serve)
+	middlewares := controller.GetMiddlewares()
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Route is a typed handler with the path it is served on, Request and Response are the types requests are bound to
+// and successful responses are written from. Criteria is the struct the criteria of the request is applied to and
+// Exports tells the route streams its rows on an Accept of an ExportFormat, both are only documented
+type Route struct {
+	Method   string
+	Path     string
+	Status   int
+	Request  reflect.Type
+	Response reflect.Type
+	Criteria reflect.Type
+	Exports  bool
+	handle   func(r *Request) (any, error)
+}
+
+// Handle routes requests bound to a Req to handle. The Res it returns is answered as JSON with the status of the
+// route, 200 unless WithStatus changes it, a *Response is answered as is and an error through ErrorResponse
+func Handle[Req, Res any](method, path string, handle func(ctx context.Context, req Req) (Res, error)) Route {
+	return Route{
+		Method:   method,
+		Path:     path,
+		Status:   http.StatusOK,
+		Request:  reflect.TypeOf((*Req)(nil)).Elem(),
+		Response: reflect.TypeOf((*Res)(nil)).Elem(),
+		handle: func(r *Request) (any, error) {
+			var req Req
+			if err := Bind(r, &req); err != nil {
+				return nil, err
+			}
+			return handle(r.Context, req)
+		},
+	}
+}
+
+// WithStatus answers successful requests with status, no body is written for 204
+func (rt Route) WithStatus(status int) Route {
+	rt.Status = status
+	return rt
+}
+
+// WithResponse documents successful responses with the type of response, for handlers returning any
+func (rt Route) WithResponse(response any) Route {
+	rt.Response = reflect.TypeOf(response)
+	return rt
+}
+
+// WithCriteria documents the fields of structType the criteria of the request can use
+func (rt Route) WithCriteria(structType any) Route {
+	rt.Criteria = reflect.TypeOf(structType)
+	return rt
+}
+
+// WithExports documents the export formats the route streams
+func (rt Route) WithExports() Route {
+	rt.Exports = true
+	return rt
+}
+
+// Serve handles the request without any router, controllers are tested through it
+func (rt Route) Serve(r *Request) *Response {
+	if r.Context == nil {
+		r.Context = context.Background()
+	}
+	res, err := rt.handle(r)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+	if response, ok := res.(*Response); ok {
+		return response
+	}
+	if rt.Status == http.StatusNoContent {
+		return &Response{Status: http.StatusNoContent}
+	}
+	return JSONResponse(rt.Status, res)
+}
@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type amendRequest struct {
+	ID       uint    `path:"id"`
+	Levels   int     `query:"levels" default:"20"`
+	Symbol   string  `query:"symbol,required"`
+	Accept   string  `header:"Accept"`
+	Price    Decimal `json:"price"`
+	Note     string  `json:"note"`
+	Criteria Criteria
+}
+
+func (ar amendRequest) Validate() error {
+	validation := NewErrorNotification()
+	validation.UintShouldBeGT("id", ar.ID, 0)
+	return validation.Err()
+}
+
+type amendResponse struct {
+	Request amendRequest
+}
+
+type testController struct {
+	routes      []Route
+	middlewares []Middleware
+}
+
+func (tc testController) GetRoutes() []Route {
+	return tc.routes
+}
+
+func (tc testController) GetRoot() string {
+	return "orders"
+}
+
+func (tc testController) GetMiddlewares() []Middleware {
+	return tc.middlewares
+}
+
+type HandlerTestSuite struct {
+	suite.Suite
+	amend Route
+}
+
+func TestHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HandlerTestSuite))
+}
+
+func (suite *HandlerTestSuite) SetupTest() {
+	suite.amend = Handle(http.MethodPut, ":id", func(_ context.Context, req amendRequest) (*amendResponse, error) {
+		return &amendResponse{Request: req}, nil
+	})
+}
+
+func (suite *HandlerTestSuite) errorBody(response *Response) ErrorBody {
+	body, ok := response.Body.(ErrorBody)
+	suite.Require().True(ok, "%#v", response.Body)
+	return body
+}
+
+func (suite *HandlerTestSuite) TestBindsPathQueryHeaderBodyAndCriteria() {
+	response := suite.amend.Serve(&Request{
+		Method: http.MethodPut,
+		Params: map[string]string{"id": "7"},
+		Query:  url.Values{"symbol": {"BTC-USD"}, "filters": {"side,Equal,buy"}},
+		Header: http.Header{"Accept": {"text/csv"}},
+		Body:   strings.NewReader(`{"price":"101.5","note":"late","ID":9}`),
+	})
+	suite.Equal(http.StatusOK, response.Status)
+	req := response.Body.(*amendResponse).Request
+	suite.Equal(uint(7), req.ID)
+	suite.Equal(20, req.Levels)
+	suite.Equal("BTC-USD", req.Symbol)
+	suite.Equal("text/csv", req.Accept)
+	suite.Equal("101.5", req.Price.String())
+	suite.Equal("late", req.Note)
+	suite.Equal([]Filter{{Field: "side", Operator: EqualOperator, Value: []string{"buy"}}}, req.Criteria.Filters)
+}
+
+func (suite *HandlerTestSuite) TestReportsEveryFieldThatCanNotBeBound() {
+	response := suite.amend.Serve(&Request{
+		Method: http.MethodPut,
+		Params: map[string]string{"id": "-1"},
+		Query:  url.Values{"levels": {"many"}},
+		Body:   strings.NewReader(`{}`),
+	})
+	suite.Equal(http.StatusBadRequest, response.Status)
+	suite.Equal(map[string]string{
+		"id":     "should be a non negative integer",
+		"levels": "should be an integer",
+		"symbol": "is required",
+	}, suite.errorBody(response).Errors)
+
+	response = suite.amend.Serve(&Request{
+		Method: http.MethodPut,
+		Params: map[string]string{"id": "0"},
+		Query:  url.Values{"symbol": {"BTC-USD"}},
+		Body:   strings.NewReader(`{"note":1}`),
+	})
+	suite.Equal(map[string]string{"note": "should be string"}, suite.errorBody(response).Errors)
+
+	response = suite.amend.Serve(&Request{
+		Method: http.MethodPut,
+		Params: map[string]string{"id": "0"},
+		Query:  url.Values{"symbol": {"BTC-USD"}},
+		Body:   strings.NewReader(`{}`),
+	})
+	suite.Equal(map[string]string{"id": "should be grater than 0"}, suite.errorBody(response).Errors)
+}
+
+func (suite *HandlerTestSuite) TestErrorsAreMappedToTheirStatus() {
+	route := Handle(http.MethodGet, "", func(_ context.Context, _ struct{}) (struct{}, error) {
+		return struct{}{}, NewNotFoundError("order")
+	})
+	response := route.Serve(&Request{Method: http.MethodGet})
+	suite.Equal(http.StatusNotFound, response.Status)
+	suite.Equal(ErrorBody{Error: "order not found"}, response.Body)
+
+	route = Handle(http.MethodDelete, "", func(_ context.Context, _ struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}).WithStatus(http.StatusNoContent)
+	response = route.Serve(&Request{Method: http.MethodDelete})
+	suite.Equal(http.StatusNoContent, response.Status)
+	suite.Nil(response.Body)
+}
+
+func (suite *HandlerTestSuite) TestHTTPHandlerMatchesStaticSegmentsFirst() {
+	depth := Handle(http.MethodGet, "depth", func(_ context.Context, _ struct{}) (string, error) {
+		return "depth", nil
+	})
+	get := Handle(http.MethodGet, ":id", func(_ context.Context, req struct {
+		ID uint `path:"id"`
+	}) (uint, error) {
+		return req.ID, nil
+	})
+	handler := NewHTTPHandler(testController{routes: []Route{get, depth, suite.amend}})
+
+	serve := func(method, target string, body io.Reader) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, target, body))
+		return recorder
+	}
+	recorder := serve(http.MethodGet, "/orders/depth", nil)
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal(`"depth"`, recorder.Body.String())
+	suite.Equal(`3`, serve(http.MethodGet, "/orders/3", nil).Body.String())
+
+	recorder = serve(http.MethodPut, "/orders/3", strings.NewReader(`{"price":"1.25"}`))
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal("application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	var body ErrorBody
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &body))
+	suite.Equal(map[string]string{"symbol": "is required"}, body.Errors)
+
+	suite.Equal(http.StatusMethodNotAllowed, serve(http.MethodPost, "/orders/3", nil).Code)
+	suite.Equal(http.StatusNotFound, serve(http.MethodGet, "/orders/3/events", nil).Code)
+}
+
+func (suite *HandlerTestSuite) TestStreamsAreAnsweredAsErrorsUntilTheyWrite() {
+	failAfter := func(rows int) Route {
+		return Handle(http.MethodGet, "", func(_ context.Context, _ struct{}) (*Response, error) {
+			return StreamResponse("text/csv", func(w io.Writer) error {
+				for i := 0; i < rows; i++ {
+					if _, err := io.WriteString(w, "row\n"); err != nil {
+						return err
+					}
+				}
+				validation := NewErrorNotification()
+				validation.Add("where", errors.New("invalid"))
+				return validation.Err()
+			}), nil
+		})
+	}
+	recorder := httptest.NewRecorder()
+	suite.Require().NoError(WriteResponse(recorder, failAfter(0).Serve(&Request{Method: http.MethodGet})))
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal("application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	recorder = httptest.NewRecorder()
+	suite.Error(WriteResponse(recorder, failAfter(2).Serve(&Request{Method: http.MethodGet})))
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("text/csv", recorder.Header().Get("Content-Type"))
+	suite.Equal("row\nrow\n", recorder.Body.String())
+}
+
+func (suite *HandlerTestSuite) TestMiddlewaresWrapTheRoutesOfTheController() {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(r *Request) *Response {
+				calls = append(calls, name)
+				return next(r)
+			}
+		}
+	}
+	authorize := func(next HandlerFunc) HandlerFunc {
+		return func(r *Request) *Response {
+			if r.Header.Get("X-Account") == "" {
+				return JSONResponse(http.StatusUnauthorized, ErrorBody{Error: "unauthorized"})
+			}
+			return next(r)
+		}
+	}
+	handler := NewHTTPHandler(testController{routes: []Route{suite.amend}, middlewares: []Middleware{trace("outer"), trace("inner"), authorize}})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/orders/3?symbol=BTC-USD", strings.NewReader(`{}`)))
+	suite.Equal(http.StatusUnauthorized, recorder.Code)
+	suite.Equal([]string{"outer", "inner"}, calls)
+
+	request := httptest.NewRequest(http.MethodPut, "/orders/3?symbol=BTC-USD", strings.NewReader(`{}`))
+	request.Header.Set("X-Account", "maker")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	suite.Equal(http.StatusOK, recorder.Code)
+}
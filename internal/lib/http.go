@@ -1,10 +1,184 @@
 package lib
 
-import "github.com/gin-gonic/gin"
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
 
-// TODO: use local request and response instead of gin.HandlerFunc, dependency in lib!
+// IController groups typed routes under a root path, the middlewares wrap every route of the controller. Servers
+// adapt them to a framework, NewHTTPHandler serves them with net/http alone
 type IController interface {
-	GetRouters() []func() (method string, url string, handler gin.HandlerFunc)
+	GetRoutes() []Route
 	GetRoot() string
-	GetMiddlewares() []gin.HandlerFunc
+	GetMiddlewares() []Middleware
+}
+
+// HttpStatusFromError maps domain errors to http status codes, unknown errors are internal errors
+func HttpStatusFromError(err error) int {
+	var notFound NotFound
+	if errors.As(err, &notFound) {
+		return http.StatusNotFound
+	}
+	var notification *ErrorNotification
+	if errors.As(err, &notification) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// NewRequest reads r, params are the values of the :name segments of the matched route
+func NewRequest(r *http.Request, params map[string]string) *Request {
+	return &Request{
+		Context: r.Context(),
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Params:  params,
+		Query:   r.URL.Query(),
+		Header:  r.Header,
+		Body:    r.Body,
+	}
+}
+
+type httpRoute struct {
+	segments []string
+	route    Route
+	handler  HandlerFunc
+}
+
+type httpHandler struct {
+	routes []httpRoute
+}
+
+// NewHTTPHandler serves the routes of the controllers with net/http alone, a path matches a route segment by
+// segment and static segments win over parameters
+func NewHTTPHandler(controllers ...IController) http.Handler {
+	handler := &httpHandler{}
+	for _, controller := range controllers {
+		for _, route := range controller.GetRoutes() {
+			handler.routes = append(handler.routes, httpRoute{
+				segments: pathSegments(controller.GetRoot(), route.Path),
+				route:    route,
+				handler:  ControllerHandler(controller, route),
+			})
+		}
+	}
+	return handler
+}
+
+func (hh *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := pathSegments(r.URL.Path)
+	var matched *httpRoute
+	var matchedParams map[string]string
+	pathMatched := false
+	for i := range hh.routes {
+		params, ok := matchSegments(hh.routes[i].segments, segments)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if hh.routes[i].route.Method != r.Method {
+			continue
+		}
+		if matched == nil || len(params) < len(matchedParams) {
+			matched, matchedParams = &hh.routes[i], params
+		}
+	}
+	if matched == nil {
+		status := http.StatusNotFound
+		if pathMatched {
+			status = http.StatusMethodNotAllowed
+		}
+		_ = WriteResponse(w, JSONResponse(status, ErrorBody{Error: strings.ToLower(http.StatusText(status))}))
+		return
+	}
+	// a late stream error has nowhere to go, the client sees a truncated response
+	_ = WriteResponse(w, matched.handler(NewRequest(r, matchedParams)))
+}
+
+func pathSegments(paths ...string) []string {
+	var segments []string
+	for _, path := range paths {
+		for _, segment := range strings.Split(path, "/") {
+			if segment != "" {
+				segments = append(segments, segment)
+			}
+		}
+	}
+	return segments
+}
+
+func matchSegments(pattern, segments []string) (map[string]string, bool) {
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, ":") {
+			params[segment[1:]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// WriteResponse writes the response with any framework, the error of a stream that already started is returned
+func WriteResponse(w http.ResponseWriter, response *Response) error {
+	for key, values := range response.Header {
+		w.Header()[key] = values
+	}
+	if response.Stream != nil {
+		return writeStream(w, response)
+	}
+	if response.Body == nil || response.Status == http.StatusNoContent {
+		w.WriteHeader(response.Status)
+		return nil
+	}
+	bts, err := json.Marshal(response.Body)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(response.Status)
+	_, err = w.Write(bts)
+	return err
+}
+
+func writeStream(w http.ResponseWriter, response *Response) error {
+	stream := &streamWriter{w: w, status: response.Status}
+	err := response.Stream(stream)
+	if err == nil {
+		stream.start()
+		return nil
+	}
+	if stream.started {
+		return err
+	}
+	for key := range response.Header {
+		w.Header().Del(key)
+	}
+	return WriteResponse(w, ErrorResponse(err))
+}
+
+// streamWriter sends the status with the first bytes of a stream
+type streamWriter struct {
+	w       http.ResponseWriter
+	status  int
+	started bool
+}
+
+func (sw *streamWriter) start() {
+	if !sw.started {
+		sw.started = true
+		sw.w.WriteHeader(sw.status)
+	}
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	sw.start()
+	return sw.w.Write(p)
 }
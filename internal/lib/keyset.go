@@ -0,0 +1,266 @@
+package lib
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+// Page describes a listed page, Total is zero when the criteria skips the count and NextCursor is empty when no row
+// can follow the page
+type Page struct {
+	Total      int
+	NextCursor string
+}
+
+// cursorToken is the opaque cursor given to clients, it keeps the sort key values of the last row of a page with
+// the sorts they belong to so a cursor of another listing is refused
+type cursorToken struct {
+	Sorts  []string `json:"s"`
+	Values []string `json:"v"`
+}
+
+type keysetColumn struct {
+	column    string
+	operator  SortOperator
+	fieldType reflect.Type
+}
+
+func (kc keysetColumn) String() string {
+	return fmt.Sprintf("%s %s", kc.column, kc.operator)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// GenericListGormCriteria finds the page of criteria into rows, a pointer to a slice of structType. query starts a new
+// query with the conditions every row shares, it is called again for the count
+func GenericListGormCriteria(query func() *gorm.DB, structType any, criteria Criteria, rows any) (Page, error) {
+	qr, err := GenericApplyGormCriteria(query(), structType, &criteria)
+	if err != nil {
+		return Page{}, err
+	}
+	if err := qr.Find(rows).Error; err != nil {
+		return Page{}, err
+	}
+	var page Page
+	if page.NextCursor, err = NextCursor(structType, &criteria, rows); err != nil {
+		return Page{}, err
+	}
+	if criteria.SkipTotal {
+		return page, nil
+	}
+	criteria.Pagination, criteria.Cursor = nil, ""
+	countQ, err := GenericApplyGormCriteria(query(), structType, &criteria)
+	if err != nil {
+		return Page{}, err
+	}
+	var total int64
+	if err := countQ.Model(structType).Count(&total).Error; err != nil {
+		return Page{}, err
+	}
+	page.Total = int(total)
+	return page, nil
+}
+
+// NextCursor encodes the sort key values of the last row of a full page, rows is the slice or a pointer to the slice
+// the criteria listed
+func NextCursor(structType any, criteria *Criteria, rows any) (string, error) {
+	listed := reflect.Indirect(reflect.ValueOf(rows))
+	if criteria.IsProjection() || criteria.Pagination == nil || listed.Len() == 0 || listed.Len() < int(criteria.Pagination.Limit) {
+		return "", nil
+	}
+	columns, err := keysetSorts(structType, criteria)
+	if err != nil {
+		return "", err
+	}
+	last := reflect.Indirect(listed.Index(listed.Len() - 1))
+	token := cursorToken{}
+	for _, kc := range columns {
+		value, ok := columnValue(last, kc.column)
+		if !ok {
+			return "", fmt.Errorf("column %s is not a field of %s", kc.column, last.Type())
+		}
+		token.Sorts = append(token.Sorts, kc.String())
+		token.Values = append(token.Values, encodeCursorValue(value))
+	}
+	bts, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bts), nil
+}
+
+// keysetSorts resolves the sorts of criteria to columns, the primary key ends them so every row has its own position
+func keysetSorts(structType any, criteria *Criteria) ([]keysetColumn, error) {
+	columns := make([]keysetColumn, 0, len(criteria.Sorts)+1)
+	direction := ASC
+	for _, sr := range criteria.Sorts {
+		column, fieldType, err := getFilterField(sr.Field, structType)
+		if err != nil {
+			return nil, err
+		}
+		operator, err := SortOperatorFromStringName(sr.Field, string(sr.Operator))
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, keysetColumn{column: column, operator: operator, fieldType: fieldType})
+		direction = operator
+	}
+	column, fieldType, ok := getPrimaryKey(structType)
+	if !ok {
+		return columns, nil
+	}
+	for _, kc := range columns {
+		if kc.column == column {
+			return columns, nil
+		}
+	}
+	return append(columns, keysetColumn{column: column, operator: direction, fieldType: fieldType}), nil
+}
+
+// applyCursor keeps the rows after the cursor in the order of the sorts
+func applyCursor(qr *gorm.DB, structType any, criteria *Criteria) (*gorm.DB, error) {
+	if criteria.Cursor == "" {
+		return qr, nil
+	}
+	if criteria.IsProjection() {
+		validation := NewErrorNotification()
+		validation.Add("cursor", errors.New("projections are paged by offset"))
+		return nil, validation.Err()
+	}
+	columns, err := keysetSorts(structType, criteria)
+	if err != nil {
+		return nil, err
+	}
+	values, err := decodeCursor(criteria.Cursor, columns)
+	if err != nil {
+		return nil, err
+	}
+	conditions := make([]string, 0, len(columns))
+	var args []any
+	for i, kc := range columns {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = ?", columns[j].column))
+			args = append(args, values[j])
+		}
+		comparison := GTOperator
+		if kc.operator == DESC {
+			comparison = LTOperator
+		}
+		parts = append(parts, fmt.Sprintf("%s %s ?", kc.column, comparison))
+		args = append(args, values[i])
+		conditions = append(conditions, "("+strings.Join(parts, " and ")+")")
+	}
+	return qr.Where("("+strings.Join(conditions, " or ")+")", args...), nil
+}
+
+func decodeCursor(cursor string, columns []keysetColumn) ([]any, error) {
+	validation := NewErrorNotification()
+	bts, err := base64.RawURLEncoding.DecodeString(cursor)
+	var token cursorToken
+	if err == nil {
+		err = json.Unmarshal(bts, &token)
+	}
+	if err != nil || len(token.Values) != len(token.Sorts) {
+		validation.Add("cursor", errors.New("invalid cursor"))
+		return nil, validation.Err()
+	}
+	if len(token.Sorts) != len(columns) {
+		validation.Add("cursor", errors.New("cursor does not belong to the sorts"))
+		return nil, validation.Err()
+	}
+	values := make([]any, len(columns))
+	for i, kc := range columns {
+		if token.Sorts[i] != kc.String() {
+			validation.Add("cursor", errors.New("cursor does not belong to the sorts"))
+			return nil, validation.Err()
+		}
+		if values[i], err = decodeCursorValue(token.Values[i], kc.fieldType); err != nil {
+			validation.Add("cursor", err)
+			return nil, validation.Err()
+		}
+	}
+	return values, nil
+}
+
+func encodeCursorValue(value any) string {
+	if at, ok := value.(time.Time); ok {
+		return at.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+func decodeCursorValue(raw string, fieldType reflect.Type) (any, error) {
+	switch fieldType {
+	case decimalType:
+		return ParseDecimal(raw)
+	case timeType:
+		return time.Parse(time.RFC3339Nano, raw)
+	}
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cast.ToInt64E(raw)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cast.ToUint64E(raw)
+	case reflect.Float32, reflect.Float64:
+		return cast.ToFloat64E(raw)
+	case reflect.Bool:
+		return cast.ToBoolE(raw)
+	}
+	return raw, nil
+}
+
+// getPrimaryKey returns the column of the first primary key field, embedded structs included
+func getPrimaryKey(structType any) (string, reflect.Type, bool) {
+	for i := 0; i < reflect.TypeOf(structType).NumField(); i++ {
+		field := reflect.TypeOf(structType).Field(i)
+		gormTag := field.Tag.Get("gorm")
+		if isFieldGormEmbedded(gormTag) {
+			if column, fieldType, ok := getPrimaryKey(getFieldValue(structType, i)); ok {
+				return column, fieldType, true
+			}
+			continue
+		}
+		if !hasGormTag(gormTag, "primarykey") {
+			continue
+		}
+		if column, err := getFieldColumnName(gormTag); err == nil {
+			return column, field.Type, true
+		}
+	}
+	return "", nil, false
+}
+
+// columnValue reads the field of row stored in column, embedded structs included
+func columnValue(row reflect.Value, column string) (any, bool) {
+	for i := 0; i < row.NumField(); i++ {
+		gormTag := row.Type().Field(i).Tag.Get("gorm")
+		if isFieldGormEmbedded(gormTag) {
+			if value, ok := columnValue(reflect.Indirect(row.Field(i)), column); ok {
+				return value, true
+			}
+			continue
+		}
+		if name, err := getFieldColumnName(gormTag); err == nil && name == column {
+			return row.Field(i).Interface(), true
+		}
+	}
+	return nil, false
+}
+
+func hasGormTag(gormTag, name string) bool {
+	for _, tag := range strings.Split(gormTag, ";") {
+		if strings.EqualFold(strings.Split(tag, ":")[0], name) {
+			return true
+		}
+	}
+	return false
+}
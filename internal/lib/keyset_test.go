@@ -0,0 +1,137 @@
+package lib
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type keysetRow struct {
+	ID        uint      `gorm:"primarykey;column:id"`
+	Price     Decimal   `criteria:"price" gorm:"column:price;index"`
+	CreatedAt time.Time `criteria:"created_at" gorm:"column:created_at;index"`
+}
+
+type KeysetTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+}
+
+func TestKeysetTestSuite(t *testing.T) {
+	suite.Run(t, new(KeysetTestSuite))
+}
+
+func (suite *KeysetTestSuite) SetupTest() {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	suite.Require().NoError(err)
+	suite.db = db
+}
+
+func (suite *KeysetTestSuite) toSQL(criteria *Criteria) (string, error) {
+	var err error
+	sql := suite.db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var qr *gorm.DB
+		if qr, err = GenericApplyGormCriteria(tx.Table("rows"), keysetRow{}, criteria); err != nil {
+			return tx
+		}
+		return qr.Find(&[]*keysetRow{})
+	})
+	return sql, err
+}
+
+func (suite *KeysetTestSuite) TestNextCursorContinuesAfterTheLastRow() {
+	criteria := NewCriteria()
+	criteria.AddSort(NewSort("price", DESC))
+	criteria.SetPagination(NewPagination(0, 2))
+	sql, err := suite.toSQL(criteria)
+	suite.Require().NoError(err)
+	suite.Contains(sql, "ORDER BY price DESC,id DESC LIMIT 2")
+
+	rows := []*keysetRow{{ID: 9, Price: NewDecimalFromInt(102)}, {ID: 7, Price: NewDecimalFromInt(101)}}
+	cursor, err := NextCursor(keysetRow{}, criteria, rows)
+	suite.Require().NoError(err)
+	suite.NotEmpty(cursor)
+
+	criteria.Cursor = cursor
+	criteria.SetPagination(NewPagination(40, 2))
+	sql, err = suite.toSQL(criteria)
+	suite.Require().NoError(err)
+	suite.Contains(sql, "WHERE ((price < '101') or (price = '101' and id < 7)) ORDER BY price DESC,id DESC LIMIT 2")
+	suite.NotContains(sql, "OFFSET")
+}
+
+func (suite *KeysetTestSuite) TestNextCursorKeepsTimes() {
+	at := time.Date(2026, 1, 2, 9, 0, 0, 123456000, time.UTC)
+	criteria := NewCriteria()
+	criteria.AddSort(NewSort("created_at", ASC))
+	criteria.SetPagination(NewPagination(0, 1))
+	cursor, err := NextCursor(keysetRow{}, criteria, &[]keysetRow{{ID: 3, CreatedAt: at}})
+	suite.Require().NoError(err)
+
+	values, err := decodeCursor(cursor, []keysetColumn{
+		{column: "created_at", operator: ASC, fieldType: timeType},
+		{column: "id", operator: ASC, fieldType: reflect.TypeOf(uint(0))},
+	})
+	suite.Require().NoError(err)
+	suite.Equal([]any{at, uint64(3)}, values)
+}
+
+func (suite *KeysetTestSuite) TestLastPageHasNoCursor() {
+	criteria := NewCriteria()
+	criteria.SetPagination(NewPagination(0, 2))
+	cursor, err := NextCursor(keysetRow{}, criteria, []*keysetRow{{ID: 1}})
+	suite.Require().NoError(err)
+	suite.Empty(cursor)
+}
+
+func (suite *KeysetTestSuite) TestCursorOfOtherSortsIsRefused() {
+	criteria := NewCriteria()
+	criteria.AddSort(NewSort("price", DESC))
+	criteria.SetPagination(NewPagination(0, 1))
+	cursor, err := NextCursor(keysetRow{}, criteria, []*keysetRow{{ID: 1, Price: NewDecimalFromInt(5)}})
+	suite.Require().NoError(err)
+
+	other := NewCriteria()
+	other.AddSort(NewSort("price", ASC))
+	other.Cursor = cursor
+	_, err = suite.toSQL(other)
+	var notification *ErrorNotification
+	suite.ErrorAs(err, &notification)
+
+	other.Cursor = "not a cursor"
+	_, err = suite.toSQL(other)
+	suite.ErrorAs(err, &notification)
+}
+
+func (suite *KeysetTestSuite) TestALimitAloneStartsTheFirstPage() {
+	criteria, err := ParseCriteria(url.Values{"limit": {"2"}, "sorts": {"price,desc"}})
+	suite.Require().NoError(err)
+	suite.Equal(NewPagination(0, 2), criteria.Pagination)
+	sql, err := suite.toSQL(criteria)
+	suite.Require().NoError(err)
+	suite.Contains(sql, "ORDER BY price DESC,id DESC LIMIT 2")
+
+	cursor, err := NextCursor(keysetRow{}, criteria, []*keysetRow{{ID: 9, Price: NewDecimalFromInt(102)}, {ID: 7, Price: NewDecimalFromInt(101)}})
+	suite.Require().NoError(err)
+	suite.NotEmpty(cursor)
+}
+
+func (suite *KeysetTestSuite) TestSortOperatorsAreCheckedOnEveryListing() {
+	_, err := ParseCriteria(url.Values{"sorts": {"price,ASC;DROP TABLE rows"}})
+	var notification *ErrorNotification
+	suite.ErrorAs(err, &notification)
+
+	for _, pagination := range []*Pagination{nil, NewPagination(0, 2)} {
+		criteria := NewCriteria()
+		criteria.AddSort(NewSort("price", "ASC;DROP TABLE rows"))
+		criteria.Pagination = pagination
+		sql, err := suite.toSQL(criteria)
+		suite.ErrorAs(err, &notification)
+		suite.NotContains(sql, "DROP")
+	}
+}
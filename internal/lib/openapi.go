@@ -0,0 +1,396 @@
+package lib
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenAPI is an OpenAPI 3 document of typed routes. Parameters and bodies are read from the bindings of the request
+// types, named structs are described once under the components and referenced
+type OpenAPI struct {
+	document openAPIDocument
+	names    map[reflect.Type]string
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Default              any                       `json:"default,omitempty"`
+	Minimum              *int                      `json:"minimum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	responseType      = reflect.TypeOf(&Response{})
+	errorBodyType     = reflect.TypeOf(ErrorBody{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+var contentHeaders = map[string]bool{"Accept": true, "Content-Type": true, "Authorization": true}
+
+func NewOpenAPI(title, version string) *OpenAPI {
+	return &OpenAPI{
+		document: openAPIDocument{
+			OpenAPI:    "3.0.3",
+			Info:       openAPIInfo{Title: title, Version: version},
+			Paths:      map[string]map[string]*openAPIOperation{},
+			Components: openAPIComponents{Schemas: map[string]*openAPISchema{}},
+		},
+		names: map[reflect.Type]string{},
+	}
+}
+
+func (o *OpenAPI) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.document)
+}
+
+func (o *OpenAPI) AddController(controller IController) {
+	for _, route := range controller.GetRoutes() {
+		o.AddRoute(controller.GetRoot(), route)
+	}
+}
+
+// AddRoute documents the route served under root, the operations of a path are tagged with the root
+func (o *OpenAPI) AddRoute(root string, route Route) {
+	operationPath := openAPIPath(root, route.Path)
+	operations, ok := o.document.Paths[operationPath]
+	if !ok {
+		operations = map[string]*openAPIOperation{}
+		o.document.Paths[operationPath] = operations
+	}
+	operations[strings.ToLower(route.Method)] = o.operation(root, route)
+}
+
+// openAPIPath joins root and path and writes the :name segments as {name}
+func openAPIPath(root, routePath string) string {
+	segments := strings.Split(strings.Trim(root+"/"+routePath, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func (o *OpenAPI) operation(root string, route Route) *openAPIOperation {
+	operation := &openAPIOperation{Tags: []string{root}, Responses: map[string]*openAPIResponse{}}
+	var fields requestFieldSet
+	if route.Request.Kind() == reflect.Struct {
+		fields = requestFields(route.Request)
+	}
+	for _, param := range fields.params {
+		// OpenAPI describes these headers by the content and security of the operation instead
+		if param.source == headerParam && contentHeaders[http.CanonicalHeaderKey(param.name)] {
+			continue
+		}
+		operation.Parameters = append(operation.Parameters, &openAPIParameter{
+			Name:     param.name,
+			In:       string(param.source),
+			Required: param.required,
+			Schema:   o.paramSchema(param),
+		})
+	}
+	if len(fields.criteria) > 0 {
+		operation.Parameters = append(operation.Parameters, criteriaParameters(route.Criteria)...)
+	}
+	if len(fields.body) > 0 && hasBody(route.Method) {
+		operation.RequestBody = &openAPIRequestBody{Required: true, Content: jsonContent(o.bodySchema(route.Request, fields))}
+	}
+
+	operation.Responses[strconv.Itoa(route.Status)] = o.successResponse(route)
+	errorContent := jsonContent(o.schemaOf(errorBodyType))
+	_, validates := reflect.New(route.Request).Interface().(Validator)
+	if len(operation.Parameters) > 0 || operation.RequestBody != nil || validates {
+		operation.Responses[strconv.Itoa(http.StatusBadRequest)] = &openAPIResponse{
+			Description: "The request is invalid, errors has the message of every invalid field",
+			Content:     errorContent,
+		}
+	}
+	operation.Responses["default"] = &openAPIResponse{Description: "The request failed", Content: errorContent}
+	return operation
+}
+
+// paramSchema the default of a parameter is documented as the value it is bound to
+func (o *OpenAPI) paramSchema(param requestParam) *openAPISchema {
+	schema := o.schemaOf(param.fieldType)
+	if param.hasDefault {
+		value := reflect.New(param.fieldType).Elem()
+		if err := setField(value, param.defaultValue); err == nil {
+			schema.Default = value.Interface()
+		}
+	}
+	return schema
+}
+
+// bodySchema references the request type when the whole of it is the body, otherwise it describes the body fields
+func (o *OpenAPI) bodySchema(requestType reflect.Type, fields requestFieldSet) *openAPISchema {
+	if len(fields.params) == 0 && len(fields.criteria) == 0 {
+		return o.schemaOf(requestType)
+	}
+	body := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	for _, field := range fields.body {
+		name, quoted := jsonField(field)
+		body.Properties[name] = o.fieldSchema(field.Type, quoted)
+	}
+	return body
+}
+
+func (o *OpenAPI) successResponse(route Route) *openAPIResponse {
+	response := &openAPIResponse{Description: http.StatusText(route.Status)}
+	if route.Status == http.StatusNoContent {
+		return response
+	}
+	// a *Response has no fixed shape unless WithResponse tells it
+	schema := &openAPISchema{}
+	if route.Response != responseType {
+		schema = o.schemaOf(route.Response)
+	}
+	response.Content = jsonContent(schema)
+	if route.Exports {
+		for _, format := range []ExportFormat{CSVExportFormat, NDJSONExportFormat} {
+			response.Content[string(format)] = &openAPIMediaType{Schema: &openAPISchema{Type: "string"}}
+		}
+	}
+	return response
+}
+
+func jsonContent(schema *openAPISchema) map[string]*openAPIMediaType {
+	return map[string]*openAPIMediaType{"application/json": {Schema: schema}}
+}
+
+// criteriaParameters are the query parameters ParseCriteria reads, the fields they take are listed when the struct the
+// criteria is applied to is known
+func criteriaParameters(structType reflect.Type) []*openAPIParameter {
+	field := `\w+`
+	if structType != nil {
+		if fields := criteriaFields(structType); len(fields) > 0 {
+			field = "(" + strings.Join(fields, "|") + ")"
+		}
+	}
+	operators := make([]string, 0, len(filterOperatorMap))
+	for name := range filterOperatorMap {
+		operators = append(operators, name)
+	}
+	sort.Strings(operators)
+	functions := make([]string, 0, len(aggregateFunctions))
+	for function := range aggregateFunctions {
+		functions = append(functions, string(function))
+	}
+	sort.Strings(functions)
+
+	list := func(name, description, pattern string) *openAPIParameter {
+		return &openAPIParameter{
+			Name:        name,
+			In:          "query",
+			Description: description,
+			Schema:      &openAPISchema{Type: "array", Items: &openAPISchema{Type: "string", Pattern: pattern}},
+		}
+	}
+	value := func(name, description string, schema *openAPISchema) *openAPIParameter {
+		return &openAPIParameter{Name: name, In: "query", Description: description, Schema: schema}
+	}
+	zero := 0
+	return []*openAPIParameter{
+		list("filters", "Filters in the format of field,operator,value, combined with operator", fmt.Sprintf(`^%s,(%s)(,.*)?$`, field, strings.Join(operators, "|"))),
+		value("where", "A nested filter expression, JSON or compact", &openAPISchema{Type: "string"}),
+		value("operator", "Logical operator for combining filters", &openAPISchema{Type: "string", Enum: []string{string(And), string(Or)}}),
+		list("sorts", "Sorts in the format of field,direction", fmt.Sprintf(`^%s,(%s|%s)$`, field, ASC, DESC)),
+		value("offset", "Offset of the page, limit is needed with it", &openAPISchema{Type: "integer", Minimum: &zero}),
+		value("limit", "Limit of the page", &openAPISchema{Type: "integer", Minimum: &zero}),
+		value("cursor", "The nextCursor of the previous page, instead of offset", &openAPISchema{Type: "string"}),
+		value("withTotal", "Whether the rows are counted", &openAPISchema{Type: "boolean", Default: true}),
+		list("fields", "Comma separated fields the rows are projected to", fmt.Sprintf(`^%[1]s(,%[1]s)*$`, field)),
+		list("groupBy", "Comma separated fields the rows are grouped by", fmt.Sprintf(`^%[1]s(,%[1]s)*$`, field)),
+		list("agg", fmt.Sprintf("Comma separated aggregates of the groups as function(field), one of %s", strings.Join(functions, ", ")), ""),
+	}
+}
+
+// schemaOf describes the JSON encoding/json writes for t
+func (o *OpenAPI) schemaOf(t reflect.Type) *openAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case decimalType:
+		return &openAPISchema{Type: "number", Format: "decimal"}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &openAPISchema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &openAPISchema{Type: "string"}
+	}
+	zero := 0
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16:
+		return &openAPISchema{Type: "integer", Format: "int32", Minimum: &zero}
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64", Minimum: &zero}
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: o.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: o.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return o.objectSchema(t)
+		}
+		return o.component(t)
+	}
+	// interfaces have no fixed shape
+	return &openAPISchema{}
+}
+
+func (o *OpenAPI) fieldSchema(t reflect.Type, quoted bool) *openAPISchema {
+	if quoted {
+		return &openAPISchema{Type: "string"}
+	}
+	return o.schemaOf(t)
+}
+
+// component references the schema of a named struct, a name taken by a struct of another package is qualified by the
+// package
+func (o *OpenAPI) component(t reflect.Type) *openAPISchema {
+	name, ok := o.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := o.document.Components.Schemas[name]; taken {
+			name = path.Base(t.PkgPath()) + "." + name
+		}
+		o.names[t] = name
+		// the name is kept before the fields are described so a struct can refer to itself
+		o.document.Components.Schemas[name] = &openAPISchema{}
+		o.document.Components.Schemas[name] = o.objectSchema(t)
+	}
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+func (o *OpenAPI) objectSchema(t reflect.Type) *openAPISchema {
+	object := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	o.addProperties(object, t)
+	return object
+}
+
+// addProperties adds the fields encoding/json writes for t, the fields of an embedded struct are added unless t has a
+// field of the same name
+func (o *OpenAPI) addProperties(object *openAPISchema, t reflect.Type) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && strings.Split(tag, ",")[0] == "" && fieldType.Kind() == reflect.Struct {
+			embedded = append(embedded, fieldType)
+			continue
+		}
+		if !field.IsExported() || fieldType.Kind() == reflect.Func || fieldType.Kind() == reflect.Chan {
+			continue
+		}
+		name, quoted := jsonField(field)
+		object.Properties[name] = o.fieldSchema(field.Type, quoted)
+	}
+	for _, embeddedType := range embedded {
+		promoted := &openAPISchema{Properties: map[string]*openAPISchema{}}
+		o.addProperties(promoted, embeddedType)
+		for name, schema := range promoted.Properties {
+			if _, ok := object.Properties[name]; !ok {
+				object.Properties[name] = schema
+			}
+		}
+	}
+}
+
+// jsonField is the name encoding/json writes field as and whether its value is quoted
+func jsonField(field reflect.StructField) (string, bool) {
+	parts := strings.Split(field.Tag.Get("json"), ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	quoted := false
+	for _, option := range parts[1:] {
+		quoted = quoted || option == "string"
+	}
+	return name, quoted
+}
@@ -0,0 +1,163 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type documentedAudit struct {
+	CreatedAt time.Time `criteria:"created_at" gorm:"column:created_at;index"`
+	UpdatedAt time.Time `criteria:"updated_at" gorm:"column:updated_at"`
+}
+
+type documentedOrder struct {
+	ID     uint            `criteria:"id" gorm:"primarykey;column:id"`
+	Symbol string          `criteria:"symbol" gorm:"column:symbol;index"`
+	Price  Decimal         `gorm:"column:price"`
+	Audit  documentedAudit `gorm:"embedded"`
+}
+
+type documentedBase struct {
+	ID    uint   `json:"id"`
+	Label string `json:"label"`
+}
+
+type documentedOrderDto struct {
+	documentedBase
+	Label    int                 `json:"label"`
+	Price    Decimal             `json:"price"`
+	Size     int64               `json:"size,string"`
+	Tags     []string            `json:"tags,omitempty"`
+	Extra    map[string]any      `json:"extra"`
+	Parent   *documentedOrderDto `json:"parent"`
+	Secret   string              `json:"-"`
+	internal string
+}
+
+type documentedListing struct {
+	Criteria Criteria
+	Accept   string `header:"Accept"`
+}
+
+type OpenAPITestSuite struct {
+	suite.Suite
+	openAPI *OpenAPI
+}
+
+func TestOpenAPITestSuite(t *testing.T) {
+	suite.Run(t, new(OpenAPITestSuite))
+}
+
+func (suite *OpenAPITestSuite) SetupTest() {
+	suite.openAPI = NewOpenAPI("api", "1.0.0")
+}
+
+// document is the JSON the document is written as, read back
+func (suite *OpenAPITestSuite) document() map[string]any {
+	raw, err := json.Marshal(suite.openAPI)
+	suite.Require().NoError(err)
+	var document map[string]any
+	suite.Require().NoError(json.Unmarshal(raw, &document))
+	return document
+}
+
+func (suite *OpenAPITestSuite) operation(document map[string]any, path, method string) map[string]any {
+	operations, ok := document["paths"].(map[string]any)[path].(map[string]any)
+	suite.Require().True(ok, "%s is not documented", path)
+	operation, ok := operations[method].(map[string]any)
+	suite.Require().True(ok, "%s %s is not documented", method, path)
+	return operation
+}
+
+func (suite *OpenAPITestSuite) parameters(operation map[string]any) map[string]map[string]any {
+	parameters := map[string]map[string]any{}
+	for _, parameter := range operation["parameters"].([]any) {
+		parameters[parameter.(map[string]any)["name"].(string)] = parameter.(map[string]any)
+	}
+	return parameters
+}
+
+func (suite *OpenAPITestSuite) TestCriteriaFieldsAreTheIndexedCriteriaTags() {
+	suite.Equal([]string{"id", "symbol", "created_at"}, CriteriaFields(documentedOrder{}))
+}
+
+func (suite *OpenAPITestSuite) TestDocumentsTheBindingsOfARoute() {
+	suite.openAPI.AddController(testController{routes: []Route{
+		Handle(http.MethodPut, ":id", func(_ context.Context, req amendRequest) (*amendResponse, error) {
+			return nil, nil
+		}),
+		Handle(http.MethodDelete, ":id", func(_ context.Context, _ struct {
+			ID uint `path:"id"`
+		}) (struct{}, error) {
+			return struct{}{}, nil
+		}).WithStatus(http.StatusNoContent),
+	}})
+	document := suite.document()
+	suite.Equal("3.0.3", document["openapi"])
+
+	amend := suite.operation(document, "/orders/{id}", "put")
+	suite.Equal([]any{"orders"}, amend["tags"])
+	parameters := suite.parameters(amend)
+	suite.Equal(map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "integer", "format": "int64", "minimum": float64(0)}}, parameters["id"])
+	suite.Equal(map[string]any{"name": "levels", "in": "query", "schema": map[string]any{"type": "integer", "format": "int64", "default": float64(20)}}, parameters["levels"])
+	suite.Equal(true, parameters["symbol"]["required"])
+	suite.NotContains(parameters, "Accept")
+	suite.Contains(parameters, "filters")
+
+	body := amend["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	suite.Equal(map[string]any{
+		"price": map[string]any{"type": "number", "format": "decimal"},
+		"note":  map[string]any{"type": "string"},
+	}, body["properties"])
+
+	responses := amend["responses"].(map[string]any)
+	suite.Contains(responses, "200")
+	suite.Contains(responses, "400")
+	suite.Contains(responses, "default")
+
+	deleted := suite.operation(document, "/orders/{id}", "delete")["responses"].(map[string]any)["204"]
+	suite.Equal(map[string]any{"description": "No Content"}, deleted)
+}
+
+func (suite *OpenAPITestSuite) TestDocumentsTheCriteriaFieldsAndExports() {
+	suite.openAPI.AddRoute("orders", Handle(http.MethodGet, "", func(_ context.Context, _ documentedListing) (any, error) {
+		return nil, nil
+	}).WithResponse([]documentedOrderDto{}).WithCriteria(documentedOrder{}).WithExports())
+	listing := suite.operation(suite.document(), "/orders", "get")
+
+	parameters := suite.parameters(listing)
+	suite.NotContains(parameters, "Accept")
+	sorts := parameters["sorts"]["schema"].(map[string]any)["items"].(map[string]any)
+	suite.Equal(`^(id|symbol|created_at),(ASC|DESC)$`, sorts["pattern"])
+	suite.Equal(map[string]any{"type": "boolean", "default": true}, parameters["withTotal"]["schema"])
+
+	content := listing["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)
+	suite.Equal(map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/documentedOrderDto"}}, content["application/json"].(map[string]any)["schema"])
+	suite.Contains(content, "text/csv")
+	suite.Contains(content, "application/x-ndjson")
+}
+
+func (suite *OpenAPITestSuite) TestDescribesStructsAsEncodingJSONWritesThem() {
+	suite.openAPI.AddRoute("orders", Handle(http.MethodGet, "", func(_ context.Context, _ struct{}) (*documentedOrderDto, error) {
+		return nil, nil
+	}))
+	schemas := suite.document()["components"].(map[string]any)["schemas"].(map[string]any)
+	suite.Contains(schemas, "ErrorBody")
+	suite.Equal(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":     map[string]any{"type": "integer", "format": "int64", "minimum": float64(0)},
+			"label":  map[string]any{"type": "integer", "format": "int64"},
+			"price":  map[string]any{"type": "number", "format": "decimal"},
+			"size":   map[string]any{"type": "string"},
+			"tags":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"extra":  map[string]any{"type": "object", "additionalProperties": map[string]any{}},
+			"parent": map[string]any{"$ref": "#/components/schemas/documentedOrderDto"},
+		},
+	}, schemas["documentedOrderDto"])
+}
@@ -0,0 +1,44 @@
+package lib
+
+// PartitionForKey is the partition the Java client and librdkafka's murmur2 partitioners pick for key, consumers
+// use it to know which keys their assignment covers
+func PartitionForKey(key string, partitionCount int) int32 {
+	if partitionCount <= 0 {
+		return 0
+	}
+	return int32((murmur2([]byte(key)) & 0x7fffffff) % uint32(partitionCount))
+}
+
+func murmur2(data []byte) uint32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := length &^ 3
+	switch length % 4 {
+	case 3:
+		h ^= uint32(data[tail+2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[tail+1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[tail])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
@@ -0,0 +1,40 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PartitionerTestSuite struct {
+	suite.Suite
+}
+
+func TestPartitionerTestSuite(t *testing.T) {
+	suite.Run(t, new(PartitionerTestSuite))
+}
+
+// the expected hashes are the ones of the Java client
+func (suite *PartitionerTestSuite) TestMurmur2MatchesJavaClient() {
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	for key, hash := range cases {
+		suite.Equal(hash, int32(murmur2([]byte(key))), key)
+	}
+}
+
+func (suite *PartitionerTestSuite) TestPartitionForKey() {
+	suite.Equal(PartitionForKey("BTC-USD", 12), PartitionForKey("BTC-USD", 12))
+	for _, key := range []string{"BTC-USD", "ETH-USD", "abc"} {
+		partition := PartitionForKey(key, 3)
+		suite.GreaterOrEqual(partition, int32(0))
+		suite.Less(partition, int32(3))
+	}
+	suite.Equal(int32(0), PartitionForKey("BTC-USD", 0))
+}
@@ -0,0 +1,296 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type AggregateFunction string
+
+const (
+	CountAggregate AggregateFunction = "count"
+	SumAggregate   AggregateFunction = "sum"
+	AvgAggregate   AggregateFunction = "avg"
+	MinAggregate   AggregateFunction = "min"
+	MaxAggregate   AggregateFunction = "max"
+)
+
+var aggregateFunctions = map[AggregateFunction]bool{
+	CountAggregate: true,
+	SumAggregate:   true,
+	AvgAggregate:   true,
+	MinAggregate:   true,
+	MaxAggregate:   true,
+}
+
+// Aggregate is a function over the rows of a group, count without a field counts the rows
+type Aggregate struct {
+	Function AggregateFunction
+	Field    string
+}
+
+var aggregateExpression = regexp.MustCompile(`^(\w+)(?:\(\s*(\w*|\*)\s*\))?$`)
+
+// ParseAggregate reads function(field), count and count(*) count the rows
+func ParseAggregate(expression string) (Aggregate, error) {
+	validation := NewErrorNotification()
+	match := aggregateExpression.FindStringSubmatch(strings.TrimSpace(expression))
+	if match == nil {
+		validation.Add("agg", fmt.Errorf("invalid aggregate %q, expected function(field)", expression))
+		return Aggregate{}, validation.Err()
+	}
+	aggregate := Aggregate{Function: AggregateFunction(strings.ToLower(match[1])), Field: strings.TrimPrefix(match[2], "*")}
+	if !aggregateFunctions[aggregate.Function] {
+		validation.Add("agg", fmt.Errorf("unknown aggregate function %s", match[1]))
+		return Aggregate{}, validation.Err()
+	}
+	if aggregate.Field == "" && aggregate.Function != CountAggregate {
+		validation.Add("agg", fmt.Errorf("%s needs a field", aggregate.Function))
+		return Aggregate{}, validation.Err()
+	}
+	return aggregate, nil
+}
+
+// Name is the column of the aggregate in a projected row, sum(quantity) is sum_quantity and count is count
+func (a Aggregate) Name() string {
+	if a.Field == "" {
+		return string(a.Function)
+	}
+	return fmt.Sprintf("%s_%s", a.Function, a.Field)
+}
+
+// Row is one projected row keyed by the criteria names of its fields and the names of its aggregates
+type Row map[string]any
+
+type projectedColumn struct {
+	name       string
+	expression string
+	scanType   reflect.Type
+}
+
+var int64Type = reflect.TypeOf(int64(0))
+
+// ProjectedColumns are the names of the columns a projection returns in their order
+func ProjectedColumns(structType any, criteria *Criteria) ([]string, error) {
+	columns, err := projectedColumns(structType, criteria)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(columns))
+	for _, pc := range columns {
+		names = append(names, pc.name)
+	}
+	return names, nil
+}
+
+// projectedColumns resolves the fields, or the grouped fields followed by the aggregates, to select expressions
+func projectedColumns(structType any, criteria *Criteria) ([]projectedColumn, error) {
+	validation := NewErrorNotification()
+	if len(criteria.Fields) > 0 && criteria.IsAggregation() {
+		validation.Add("fields", errors.New("fields can not be combined with groupBy or agg, the grouped fields are returned"))
+		return nil, validation.Err()
+	}
+	fields := criteria.Fields
+	if criteria.IsAggregation() {
+		fields = criteria.GroupBy
+	}
+	columns := make([]projectedColumn, 0, len(fields)+len(criteria.Aggregates))
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		column, fieldType, err := getFilterField(field, structType)
+		if err != nil {
+			return nil, err
+		}
+		if seen[field] {
+			validation.Add(field, errors.New("field is projected twice"))
+			return nil, validation.Err()
+		}
+		seen[field] = true
+		columns = append(columns, projectedColumn{name: field, expression: fmt.Sprintf("%s AS %s", column, field), scanType: fieldType})
+	}
+	for _, aggregate := range criteria.Aggregates {
+		pc, err := aggregateColumn(structType, aggregate)
+		if err != nil {
+			return nil, err
+		}
+		if seen[pc.name] {
+			validation.Add(pc.name, errors.New("aggregate is projected twice"))
+			return nil, validation.Err()
+		}
+		seen[pc.name] = true
+		columns = append(columns, pc)
+	}
+	return columns, nil
+}
+
+// aggregateColumn allows sum and avg on numbers and min and max on numbers, text and times. Averages are rounded
+// to the decimal scale
+func aggregateColumn(structType any, aggregate Aggregate) (projectedColumn, error) {
+	pc := projectedColumn{name: aggregate.Name(), expression: fmt.Sprintf("COUNT(*) AS %s", aggregate.Name()), scanType: int64Type}
+	if aggregate.Field == "" {
+		return pc, nil
+	}
+	column, fieldType, err := getFilterField(aggregate.Field, structType)
+	if err != nil {
+		return projectedColumn{}, err
+	}
+	validation := NewErrorNotification()
+	numeric := fieldType == decimalType || isNumberKind(fieldType.Kind())
+	switch aggregate.Function {
+	case CountAggregate:
+		pc.expression = fmt.Sprintf("COUNT(%s) AS %s", column, pc.name)
+	case SumAggregate:
+		if !numeric {
+			validation.Add(aggregate.Field, errors.New("sum is only supported on number fields"))
+			return projectedColumn{}, validation.Err()
+		}
+		pc.expression, pc.scanType = fmt.Sprintf("SUM(%s) AS %s", column, pc.name), fieldType
+		if fieldType != decimalType {
+			pc.scanType = int64Type
+		}
+	case AvgAggregate:
+		if !numeric {
+			validation.Add(aggregate.Field, errors.New("avg is only supported on number fields"))
+			return projectedColumn{}, validation.Err()
+		}
+		pc.expression, pc.scanType = fmt.Sprintf("ROUND(AVG(%s), %d) AS %s", column, DecimalScale(), pc.name), decimalType
+	case MinAggregate, MaxAggregate:
+		if fieldType.Kind() == reflect.Bool {
+			validation.Add(aggregate.Field, fmt.Errorf("%s is not supported on boolean fields", aggregate.Function))
+			return projectedColumn{}, validation.Err()
+		}
+		pc.expression, pc.scanType = fmt.Sprintf("%s(%s) AS %s", strings.ToUpper(string(aggregate.Function)), column, pc.name), fieldType
+	}
+	return pc, nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// applyProjection selects the projected columns, the rows are grouped by the grouped fields of an aggregation
+func applyProjection(qr *gorm.DB, structType any, criteria *Criteria) (*gorm.DB, error) {
+	if !criteria.IsProjection() {
+		return qr, nil
+	}
+	columns, err := projectedColumns(structType, criteria)
+	if err != nil {
+		return nil, err
+	}
+	expressions := make([]string, 0, len(columns))
+	for _, pc := range columns {
+		expressions = append(expressions, pc.expression)
+	}
+	qr = qr.Select(strings.Join(expressions, ", "))
+	if len(criteria.GroupBy) > 0 {
+		grouped := make([]string, 0, len(criteria.GroupBy))
+		for _, field := range criteria.GroupBy {
+			column, _, err := getFilterField(field, structType)
+			if err != nil {
+				return nil, err
+			}
+			grouped = append(grouped, column)
+		}
+		qr = qr.Group(strings.Join(grouped, ", "))
+	}
+	return qr, nil
+}
+
+// applyAggregationSorts orders groups by their grouped fields or their aggregates
+func applyAggregationSorts(qr *gorm.DB, structType any, criteria *Criteria) (*gorm.DB, error) {
+	aggregates := make(map[string]bool, len(criteria.Aggregates))
+	for _, aggregate := range criteria.Aggregates {
+		aggregates[aggregate.Name()] = true
+	}
+	grouped := make(map[string]bool, len(criteria.GroupBy))
+	for _, field := range criteria.GroupBy {
+		grouped[field] = true
+	}
+	for _, sr := range criteria.Sorts {
+		operator, err := SortOperatorFromStringName(sr.Field, string(sr.Operator))
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case aggregates[sr.Field]:
+			qr = qr.Order(fmt.Sprintf("%s %s", sr.Field, operator))
+		case grouped[sr.Field]:
+			column, _, err := getFilterField(sr.Field, structType)
+			if err != nil {
+				return nil, err
+			}
+			qr = qr.Order(fmt.Sprintf("%s %s", column, operator))
+		default:
+			validation := NewErrorNotification()
+			validation.Add(sr.Field, errors.New("only grouped fields and aggregates can be sorted"))
+			return nil, validation.Err()
+		}
+	}
+	return qr, nil
+}
+
+// GenericProjectGormCriteria lists the projected rows of criteria, query starts a new query with the conditions every
+// row shares. Projections are paged by offset, the total counts the groups of an aggregation
+func GenericProjectGormCriteria(query func() *gorm.DB, structType any, criteria Criteria) ([]Row, Page, error) {
+	rows := make([]Row, 0)
+	err := GenericStreamProjectionGormCriteria(query(), structType, criteria, func(row Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, Page{}, err
+	}
+	if criteria.SkipTotal {
+		return rows, Page{}, nil
+	}
+	criteria.Pagination, criteria.Sorts = nil, nil
+	if !criteria.IsAggregation() {
+		criteria.Fields = nil
+	}
+	countQ, err := GenericApplyGormCriteria(query(), structType, &criteria)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	var total int64
+	if criteria.IsAggregation() {
+		err = query().Table("(?) AS projected", countQ.Model(structType)).Count(&total).Error
+	} else {
+		err = countQ.Model(structType).Count(&total).Error
+	}
+	if err != nil {
+		return nil, Page{}, err
+	}
+	return rows, Page{Total: int(total)}, nil
+}
+
+// scanProjectedRow scans into the type of every column, NULL aggregates of empty groups stay nil
+func scanProjectedRow(scan func(dest ...any) error, columns []projectedColumn) (Row, error) {
+	holders := make([]any, len(columns))
+	for i, pc := range columns {
+		holders[i] = reflect.New(reflect.PointerTo(pc.scanType)).Interface()
+	}
+	if err := scan(holders...); err != nil {
+		return nil, err
+	}
+	row := make(Row, len(columns))
+	for i, pc := range columns {
+		value := reflect.ValueOf(holders[i]).Elem()
+		if value.IsNil() {
+			row[pc.name] = nil
+			continue
+		}
+		row[pc.name] = value.Elem().Interface()
+	}
+	return row, nil
+}
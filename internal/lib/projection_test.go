@@ -0,0 +1,137 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type projectionRow struct {
+	ID       uint    `gorm:"primarykey;column:id"`
+	Side     string  `criteria:"side" gorm:"column:side;index"`
+	Price    Decimal `criteria:"price" gorm:"column:price;index"`
+	Quantity Decimal `criteria:"quantity" gorm:"column:quantity;index"`
+	Matched  bool    `criteria:"matched" gorm:"column:matched;index"`
+}
+
+type ProjectionTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+}
+
+func TestProjectionTestSuite(t *testing.T) {
+	suite.Run(t, new(ProjectionTestSuite))
+}
+
+func (suite *ProjectionTestSuite) SetupTest() {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	suite.Require().NoError(err)
+	suite.db = db
+}
+
+func (suite *ProjectionTestSuite) toSQL(criteria *Criteria) (string, error) {
+	var err error
+	sql := suite.db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var qr *gorm.DB
+		if qr, err = GenericApplyGormCriteria(tx.Table("rows"), projectionRow{}, criteria); err != nil {
+			return tx
+		}
+		return qr.Find(&[]*projectionRow{})
+	})
+	return sql, err
+}
+
+func (suite *ProjectionTestSuite) TestParseAggregate() {
+	aggregate, err := ParseAggregate("SUM( quantity )")
+	suite.Require().NoError(err)
+	suite.Equal(Aggregate{Function: SumAggregate, Field: "quantity"}, aggregate)
+	suite.Equal("sum_quantity", aggregate.Name())
+
+	for _, expression := range []string{"count", "count(*)", "count()"} {
+		aggregate, err = ParseAggregate(expression)
+		suite.Require().NoError(err, expression)
+		suite.Equal(Aggregate{Function: CountAggregate}, aggregate)
+	}
+
+	for _, expression := range []string{"median(price)", "sum", "sum(price", "sum(price;drop)"} {
+		_, err = ParseAggregate(expression)
+		var notification *ErrorNotification
+		suite.ErrorAs(err, &notification, expression)
+	}
+}
+
+func (suite *ProjectionTestSuite) TestFieldsAreSelected() {
+	criteria := NewCriteria()
+	criteria.Fields = []string{"price", "quantity"}
+	sql, err := suite.toSQL(criteria)
+	suite.Require().NoError(err)
+	suite.Contains(sql, `SELECT price AS price, quantity AS quantity FROM "rows"`)
+
+	names, err := ProjectedColumns(projectionRow{}, criteria)
+	suite.Require().NoError(err)
+	suite.Equal([]string{"price", "quantity"}, names)
+}
+
+func (suite *ProjectionTestSuite) TestAggregatesAreGrouped() {
+	criteria := NewCriteria()
+	criteria.GroupBy = []string{"side", "price"}
+	criteria.AddAggregate(Aggregate{Function: SumAggregate, Field: "quantity"}).AddAggregate(Aggregate{Function: CountAggregate})
+	criteria.AddFilter(Filter{Field: "matched", Operator: EqualOperator, Value: []string{"true"}})
+	criteria.AddSort(NewSort("sum_quantity", DESC))
+	criteria.AddSort(NewSort("price", ASC))
+	criteria.SetPagination(NewPagination(10, 5))
+	sql, err := suite.toSQL(criteria)
+	suite.Require().NoError(err)
+	suite.Equal(`SELECT side AS side, price AS price, SUM(quantity) AS sum_quantity, COUNT(*) AS count FROM "rows" `+
+		`WHERE matched = 'true' GROUP BY side, price ORDER BY sum_quantity DESC,price ASC LIMIT 5 OFFSET 10`, sql)
+
+	names, err := ProjectedColumns(projectionRow{}, criteria)
+	suite.Require().NoError(err)
+	suite.Equal([]string{"side", "price", "sum_quantity", "count"}, names)
+}
+
+func (suite *ProjectionTestSuite) TestAverageIsRoundedToTheDecimalScale() {
+	criteria := NewCriteria()
+	criteria.AddAggregate(Aggregate{Function: AvgAggregate, Field: "price"})
+	sql, err := suite.toSQL(criteria)
+	suite.Require().NoError(err)
+	suite.Contains(sql, "SELECT ROUND(AVG(price), ")
+	suite.NotContains(sql, "GROUP BY")
+}
+
+func (suite *ProjectionTestSuite) TestInvalidProjectionsAreRejected() {
+	invalid := map[string]func(criteria *Criteria){
+		"sum of text": func(criteria *Criteria) {
+			criteria.AddAggregate(Aggregate{Function: SumAggregate, Field: "side"})
+		},
+		"max of boolean": func(criteria *Criteria) {
+			criteria.AddAggregate(Aggregate{Function: MaxAggregate, Field: "matched"})
+		},
+		"field without criteria tag": func(criteria *Criteria) {
+			criteria.Fields = []string{"id"}
+		},
+		"fields with an aggregation": func(criteria *Criteria) {
+			criteria.Fields = []string{"price"}
+			criteria.GroupBy = []string{"side"}
+		},
+		"sort on an ungrouped field": func(criteria *Criteria) {
+			criteria.GroupBy = []string{"side"}
+			criteria.AddSort(NewSort("price", ASC))
+		},
+		"cursor": func(criteria *Criteria) {
+			criteria.Fields = []string{"price"}
+			criteria.Cursor = "e30"
+		},
+		"duplicated field": func(criteria *Criteria) {
+			criteria.Fields = []string{"price", "price"}
+		},
+	}
+	for name, setup := range invalid {
+		criteria := NewCriteria()
+		setup(criteria)
+		_, err := suite.toSQL(criteria)
+		suite.Error(err, name)
+	}
+}
@@ -15,7 +15,7 @@ func GetEnv(key string, fallback string) string {
 }
 
 func Terminable() context.Context {
-	c := make(chan os.Signal)
+	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 	signal.Notify(c, os.Kill)
 	signal.Notify(c, syscall.SIGTERM)
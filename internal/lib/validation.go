@@ -95,3 +95,51 @@ func (n *ErrorNotification) NotNil(id string, v1 any) {
 		n.Add(id, errors.New("should not be nil"))
 	}
 }
+
+func (n *ErrorNotification) IntShouldBeBetween(id string, v, min, max int) {
+	if v < min || v > max {
+		n.Add(id, fmt.Errorf("should be between %d and %d", min, max))
+	}
+}
+
+func (n *ErrorNotification) IntShouldBeGTE(id string, v1, v2 int) {
+	if v1 < v2 {
+		n.Add(id, fmt.Errorf("should be grater than or equal to %d", v2))
+	}
+}
+
+func (n *ErrorNotification) IntShouldBeGT(id string, v1, v2 int) {
+	if v1 <= v2 {
+		n.Add(id, fmt.Errorf("should be grater than %d", v2))
+	}
+}
+
+func (n *ErrorNotification) DecimalShouldBePositive(id string, v Decimal) {
+	if v <= 0 {
+		n.Add(id, errors.New("should be grater than 0"))
+	}
+}
+
+func (n *ErrorNotification) DecimalShouldBeZero(id string, v Decimal) {
+	if v != 0 {
+		n.Add(id, errors.New("should be equal to 0"))
+	}
+}
+
+func (n *ErrorNotification) DecimalShouldNotBeNegative(id string, v Decimal) {
+	if v < 0 {
+		n.Add(id, errors.New("should be grater than or equal to 0"))
+	}
+}
+
+func (n *ErrorNotification) DecimalShouldBeGTE(id string, v1, v2 Decimal) {
+	if v1 < v2 {
+		n.Add(id, fmt.Errorf("should be grater than or equal to %s", v2))
+	}
+}
+
+func (n *ErrorNotification) DecimalShouldBeMultipleOf(id string, v, step Decimal) {
+	if !v.IsMultipleOf(step) {
+		n.Add(id, fmt.Errorf("should be a multiple of %s", step))
+	}
+}
@@ -0,0 +1,36 @@
+package account
+
+import (
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+)
+
+// Account keeps the trading preferences of an account owner
+type Account struct {
+	ID        string        `gorm:"primarykey;column:id"`
+	STPMode   order.STPMode `gorm:"column:stp_mode"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewAccount(id string) *Account {
+	return &Account{ID: id}
+}
+
+func (acc *Account) SetSTPMode(mode string) error {
+	stpMode, err := order.ParseSTPMode(mode)
+	if err != nil {
+		return err
+	}
+	acc.STPMode = stpMode
+	return acc.validate()
+}
+
+func (acc *Account) validate() error {
+	validation := lib.NewErrorNotification()
+
+	validation.StringNotEmpty("id", acc.ID)
+
+	return validation.Err()
+}
@@ -0,0 +1,37 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"tradeTornado/internal/modules/account"
+)
+
+type SetAccountCommand struct {
+	ID      string
+	STPMode string `json:"stpMode"`
+}
+
+type AccountCommandHandler struct {
+	accountRepository account.IAccountWriteRepository
+}
+
+func NewAccountCommandHandler(accountRepository account.IAccountWriteRepository) *AccountCommandHandler {
+	return &AccountCommandHandler{accountRepository: accountRepository}
+}
+
+func (ach *AccountCommandHandler) SetAccount(ctx context.Context, cmd SetAccountCommand) (*AccountDto, error) {
+	acc, err := ach.accountRepository.Get(ctx, cmd.ID)
+	if err != nil {
+		if !errors.Is(err, account.AccountNotFound) {
+			return nil, err
+		}
+		acc = account.NewAccount(cmd.ID)
+	}
+	if err := acc.SetSTPMode(cmd.STPMode); err != nil {
+		return nil, err
+	}
+	if err := ach.accountRepository.Save(ctx, acc); err != nil {
+		return nil, err
+	}
+	return toAccountDto(acc), nil
+}
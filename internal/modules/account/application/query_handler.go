@@ -0,0 +1,48 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"tradeTornado/internal/modules/account"
+	"tradeTornado/internal/modules/order"
+)
+
+type AccountDto struct {
+	ID      string
+	STPMode string
+}
+
+type AccountQueryHandler struct {
+	accountRepository account.IAccountReadRepository
+}
+
+func NewAccountQueryHandler(accountRepository account.IAccountReadRepository) *AccountQueryHandler {
+	return &AccountQueryHandler{accountRepository: accountRepository}
+}
+
+func (aqh *AccountQueryHandler) GetAccount(ctx context.Context, id string) (*AccountDto, error) {
+	acc, err := aqh.accountRepository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toAccountDto(acc), nil
+}
+
+// GetSTPMode allows self trades for accounts without preferences
+func (aqh *AccountQueryHandler) GetSTPMode(ctx context.Context, id string) (order.STPMode, error) {
+	acc, err := aqh.accountRepository.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, account.AccountNotFound) {
+			return order.NoneSTPMode, nil
+		}
+		return order.NoneSTPMode, err
+	}
+	return acc.STPMode, nil
+}
+
+func toAccountDto(acc *account.Account) *AccountDto {
+	return &AccountDto{
+		ID:      acc.ID,
+		STPMode: string(acc.STPMode),
+	}
+}
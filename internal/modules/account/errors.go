@@ -0,0 +1,9 @@
+package account
+
+import (
+	"tradeTornado/internal/lib"
+)
+
+var (
+	AccountNotFound = lib.NewNotFoundError("account")
+)
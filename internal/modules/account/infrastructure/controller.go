@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/account/application"
+)
+
+type AccountController struct {
+	commandHandler *application.AccountCommandHandler
+	queryHandler   *application.AccountQueryHandler
+}
+
+func NewAccountController(ch *application.AccountCommandHandler, qh *application.AccountQueryHandler) *AccountController {
+	return &AccountController{
+		commandHandler: ch,
+		queryHandler:   qh,
+	}
+}
+
+func (ac *AccountController) GetRoutes() []lib.Route {
+	return []lib.Route{
+		ac.getAccount(),
+		ac.setAccount(),
+	}
+}
+
+func (ac *AccountController) GetRoot() string {
+	return "admin/accounts"
+}
+
+func (ac *AccountController) GetMiddlewares() []lib.Middleware {
+	return nil
+}
+
+type getAccountRequest struct {
+	AccountID string `path:"accountID"`
+}
+
+func (ac *AccountController) getAccount() lib.Route {
+	return lib.Handle(http.MethodGet, ":accountID", func(ctx context.Context, req getAccountRequest) (*application.AccountDto, error) {
+		return ac.queryHandler.GetAccount(ctx, req.AccountID)
+	})
+}
+
+type setAccountRequest struct {
+	AccountID string `path:"accountID"`
+	application.SetAccountCommand
+}
+
+func (ac *AccountController) setAccount() lib.Route {
+	return lib.Handle(http.MethodPut, ":accountID", func(ctx context.Context, req setAccountRequest) (*application.AccountDto, error) {
+		cmd := req.SetAccountCommand
+		cmd.ID = req.AccountID
+		return ac.commandHandler.SetAccount(ctx, cmd)
+	})
+}
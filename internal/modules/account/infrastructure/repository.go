@@ -0,0 +1,40 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"tradeTornado/internal/modules/account"
+	"tradeTornado/internal/service/provider"
+
+	"gorm.io/gorm"
+)
+
+type AccountRepository struct {
+	session *provider.GormSession
+}
+
+func NewAccountRepository(session *provider.GormSession) *AccountRepository {
+	return &AccountRepository{
+		session: session,
+	}
+}
+
+func (c *AccountRepository) Save(ctx context.Context, acc *account.Account) error {
+	return c.session.Gorm().WithContext(ctx).Save(acc).Error
+}
+
+func (c *AccountRepository) Get(ctx context.Context, id string) (*account.Account, error) {
+	var acc *account.Account
+	if err := c.session.Gorm().WithContext(ctx).Where("id = ?", id).First(&acc).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, account.AccountNotFound
+		}
+		return nil, err
+	}
+	return acc, nil
+}
+
+func (c *AccountRepository) Migrate(ctx context.Context) error {
+	return c.session.Gorm().WithContext(ctx).AutoMigrate(&account.Account{})
+}
@@ -0,0 +1,14 @@
+package account
+
+import (
+	"context"
+)
+
+type IAccountWriteRepository interface {
+	Save(ctx context.Context, acc *Account) error
+	IAccountReadRepository
+}
+
+type IAccountReadRepository interface {
+	Get(ctx context.Context, id string) (*Account, error)
+}
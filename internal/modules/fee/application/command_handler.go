@@ -0,0 +1,65 @@
+package application
+
+import (
+	"context"
+	"tradeTornado/internal/modules/fee"
+)
+
+type SetFeeTierCommand struct {
+	Name         string
+	MakerRateBps int `json:"makerRateBps"`
+	TakerRateBps int `json:"takerRateBps"`
+}
+
+type AssignAccountFeeTierCommand struct {
+	AccountID string
+	TierName  string `json:"tierName"`
+}
+
+type FeeCommandHandler struct {
+	feeRepository fee.IFeeWriteRepository
+}
+
+func NewFeeCommandHandler(feeRepository fee.IFeeWriteRepository) *FeeCommandHandler {
+	return &FeeCommandHandler{feeRepository: feeRepository}
+}
+
+func (fch *FeeCommandHandler) SetTier(ctx context.Context, cmd SetFeeTierCommand) (*FeeTierDto, error) {
+	tier, err := fee.NewFeeTier(cmd.Name, cmd.MakerRateBps, cmd.TakerRateBps)
+	if err != nil {
+		return nil, err
+	}
+	if err := fch.feeRepository.SaveTier(ctx, tier); err != nil {
+		return nil, err
+	}
+	return toFeeTierDto(tier), nil
+}
+
+func (fch *FeeCommandHandler) DeleteTier(ctx context.Context, name string) error {
+	if _, err := fch.feeRepository.GetTier(ctx, name); err != nil {
+		return err
+	}
+	count, err := fch.feeRepository.CountAccountsInTier(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fee.FeeTierInUse
+	}
+	return fch.feeRepository.DeleteTier(ctx, name)
+}
+
+func (fch *FeeCommandHandler) AssignAccountTier(ctx context.Context, cmd AssignAccountFeeTierCommand) (*AccountFeeTierDto, error) {
+	accountTier, err := fee.NewAccountFeeTier(cmd.AccountID, cmd.TierName)
+	if err != nil {
+		return nil, err
+	}
+	tier, err := fch.feeRepository.GetTier(ctx, accountTier.TierName)
+	if err != nil {
+		return nil, err
+	}
+	if err := fch.feeRepository.SaveAccountTier(ctx, accountTier); err != nil {
+		return nil, err
+	}
+	return &AccountFeeTierDto{AccountID: accountTier.AccountID, Tier: toFeeTierDto(tier)}, nil
+}
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"tradeTornado/internal/modules/fee"
+)
+
+type FeeTierDto struct {
+	Name         string
+	MakerRateBps int
+	TakerRateBps int
+}
+
+type AccountFeeTierDto struct {
+	AccountID string
+	Tier      *FeeTierDto
+}
+
+type FeeQueryHandler struct {
+	feeRepository fee.IFeeReadRepository
+}
+
+func NewFeeQueryHandler(feeRepository fee.IFeeReadRepository) *FeeQueryHandler {
+	return &FeeQueryHandler{feeRepository: feeRepository}
+}
+
+func (fqh *FeeQueryHandler) ListTiers(ctx context.Context) ([]*FeeTierDto, error) {
+	tiers, err := fqh.feeRepository.ListTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*FeeTierDto, 0)
+	for _, tier := range tiers {
+		dtos = append(dtos, toFeeTierDto(tier))
+	}
+	return dtos, nil
+}
+
+func (fqh *FeeQueryHandler) GetAccountTier(ctx context.Context, accountID string) (*AccountFeeTierDto, error) {
+	tier, err := fqh.accountTier(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountFeeTierDto{AccountID: accountID, Tier: toFeeTierDto(tier)}, nil
+}
+
+// GetFeeRates returns the rates of the account tier, accounts are not charged when no tier is configured
+func (fqh *FeeQueryHandler) GetFeeRates(ctx context.Context, accountID string) (int, int, error) {
+	tier, err := fqh.accountTier(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, fee.FeeTierNotFound) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	return tier.MakerRateBps, tier.TakerRateBps, nil
+}
+
+// accountTier is the tier assigned to the account, the default tier when the account has none
+func (fqh *FeeQueryHandler) accountTier(ctx context.Context, accountID string) (*fee.FeeTier, error) {
+	tier, err := fqh.feeRepository.GetAccountTier(ctx, accountID)
+	if errors.Is(err, fee.AccountFeeTierNotFound) {
+		return fqh.feeRepository.GetTier(ctx, fee.DefaultFeeTierName)
+	}
+	return tier, err
+}
+
+func toFeeTierDto(tier *fee.FeeTier) *FeeTierDto {
+	return &FeeTierDto{
+		Name:         tier.Name,
+		MakerRateBps: tier.MakerRateBps,
+		TakerRateBps: tier.TakerRateBps,
+	}
+}
@@ -0,0 +1,97 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"tradeTornado/internal/modules/fee"
+	"tradeTornado/internal/modules/fee/application"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// memoryFees keeps the tiers and the assignments by name
+type memoryFees struct {
+	tiers       map[string]*fee.FeeTier
+	assignments map[string]string
+}
+
+func (mf *memoryFees) GetTier(_ context.Context, name string) (*fee.FeeTier, error) {
+	tier, ok := mf.tiers[name]
+	if !ok {
+		return nil, fee.FeeTierNotFound
+	}
+	return tier, nil
+}
+
+func (mf *memoryFees) ListTiers(context.Context) ([]*fee.FeeTier, error) {
+	var tiers []*fee.FeeTier
+	for _, tier := range mf.tiers {
+		tiers = append(tiers, tier)
+	}
+	return tiers, nil
+}
+
+func (mf *memoryFees) GetAccountTier(ctx context.Context, accountID string) (*fee.FeeTier, error) {
+	name, ok := mf.assignments[accountID]
+	if !ok {
+		return nil, fee.AccountFeeTierNotFound
+	}
+	return mf.GetTier(ctx, name)
+}
+
+func (mf *memoryFees) CountAccountsInTier(_ context.Context, name string) (int, error) {
+	count := 0
+	for _, assigned := range mf.assignments {
+		if assigned == name {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type FeeQueryHandlerTestSuite struct {
+	suite.Suite
+	fees    *memoryFees
+	handler *application.FeeQueryHandler
+}
+
+func TestFeeQueryHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(FeeQueryHandlerTestSuite))
+}
+
+func (suite *FeeQueryHandlerTestSuite) SetupTest() {
+	suite.fees = &memoryFees{tiers: make(map[string]*fee.FeeTier), assignments: make(map[string]string)}
+	suite.handler = application.NewFeeQueryHandler(suite.fees)
+}
+
+func (suite *FeeQueryHandlerTestSuite) addTier(name string, makerRateBps, takerRateBps int) {
+	tier, err := fee.NewFeeTier(name, makerRateBps, takerRateBps)
+	suite.Require().NoError(err)
+	suite.fees.tiers[name] = tier
+}
+
+func (suite *FeeQueryHandlerTestSuite) rates(accountID string) [2]int {
+	makerRateBps, takerRateBps, err := suite.handler.GetFeeRates(context.Background(), accountID)
+	suite.Require().NoError(err)
+	return [2]int{makerRateBps, takerRateBps}
+}
+
+func (suite *FeeQueryHandlerTestSuite) TestAccountsWithoutTierAreNotCharged() {
+	suite.Equal([2]int{0, 0}, suite.rates("retail"))
+	_, err := suite.handler.GetAccountTier(context.Background(), "retail")
+	suite.ErrorIs(err, fee.FeeTierNotFound)
+}
+
+func (suite *FeeQueryHandlerTestSuite) TestAccountsWithoutAssignmentUseTheDefaultTier() {
+	suite.addTier(fee.DefaultFeeTierName, 2, 10)
+	suite.addTier("vip", -1, 4)
+	suite.fees.assignments["market-maker"] = "vip"
+
+	suite.Equal([2]int{2, 10}, suite.rates("retail"))
+	suite.Equal([2]int{-1, 4}, suite.rates("market-maker"))
+
+	dto, err := suite.handler.GetAccountTier(context.Background(), "retail")
+	suite.Require().NoError(err)
+	suite.Equal(&application.AccountFeeTierDto{AccountID: "retail", Tier: &application.FeeTierDto{Name: fee.DefaultFeeTierName, MakerRateBps: 2, TakerRateBps: 10}}, dto)
+}
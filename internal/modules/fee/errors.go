@@ -0,0 +1,17 @@
+package fee
+
+import (
+	"errors"
+
+	"tradeTornado/internal/lib"
+)
+
+var (
+	FeeTierNotFound        = lib.NewNotFoundError("fee tier")
+	AccountFeeTierNotFound = lib.NewNotFoundError("account fee tier")
+	FeeTierInUse           = lib.NewErrorNotification()
+)
+
+func init() {
+	FeeTierInUse.Add("fee_tier", errors.New("fee tier is assigned to accounts"))
+}
@@ -0,0 +1,63 @@
+package fee
+
+import (
+	"time"
+	"tradeTornado/internal/lib"
+)
+
+const (
+	DefaultFeeTierName = "default"
+	maxRateBps         = 10000
+)
+
+// FeeTier is a named maker/taker schedule, rates are in basis points and negative rates are rebates
+type FeeTier struct {
+	Name         string `gorm:"primarykey;column:name"`
+	MakerRateBps int    `gorm:"column:maker_rate_bps"`
+	TakerRateBps int    `gorm:"column:taker_rate_bps"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func NewFeeTier(name string, makerRateBps, takerRateBps int) (*FeeTier, error) {
+	tier := &FeeTier{
+		Name:         name,
+		MakerRateBps: makerRateBps,
+		TakerRateBps: takerRateBps,
+	}
+	return tier, tier.validate()
+}
+
+func (tier *FeeTier) validate() error {
+	validation := lib.NewErrorNotification()
+
+	validation.StringNotEmpty("name", tier.Name)
+	validation.IntShouldBeBetween("maker_rate_bps", tier.MakerRateBps, -maxRateBps, maxRateBps)
+	validation.IntShouldBeBetween("taker_rate_bps", tier.TakerRateBps, -maxRateBps, maxRateBps)
+
+	return validation.Err()
+}
+
+// AccountFeeTier assigns an account to a fee tier, accounts without assignment use the default tier
+type AccountFeeTier struct {
+	AccountID string `gorm:"primarykey;column:account_id"`
+	TierName  string `gorm:"column:tier_name;index"`
+	UpdatedAt time.Time
+}
+
+func NewAccountFeeTier(accountID, tierName string) (*AccountFeeTier, error) {
+	accountTier := &AccountFeeTier{
+		AccountID: accountID,
+		TierName:  tierName,
+	}
+	return accountTier, accountTier.validate()
+}
+
+func (at *AccountFeeTier) validate() error {
+	validation := lib.NewErrorNotification()
+
+	validation.StringNotEmpty("account_id", at.AccountID)
+	validation.StringNotEmpty("tier_name", at.TierName)
+
+	return validation.Err()
+}
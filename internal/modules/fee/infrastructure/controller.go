@@ -0,0 +1,98 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/fee/application"
+)
+
+type FeeController struct {
+	commandHandler *application.FeeCommandHandler
+	queryHandler   *application.FeeQueryHandler
+}
+
+func NewFeeController(ch *application.FeeCommandHandler, qh *application.FeeQueryHandler) *FeeController {
+	return &FeeController{
+		commandHandler: ch,
+		queryHandler:   qh,
+	}
+}
+
+func (fc *FeeController) GetRoutes() []lib.Route {
+	return []lib.Route{
+		fc.listTiers(),
+		fc.setTier(),
+		fc.deleteTier(),
+		fc.getAccountTier(),
+		fc.assignAccountTier(),
+	}
+}
+
+func (fc *FeeController) GetRoot() string {
+	return "admin/fees"
+}
+
+func (fc *FeeController) GetMiddlewares() []lib.Middleware {
+	return nil
+}
+
+type listTiersResponse struct {
+	Tiers []*application.FeeTierDto `json:"tiers"`
+}
+
+func (fc *FeeController) listTiers() lib.Route {
+	return lib.Handle(http.MethodGet, "tiers", func(ctx context.Context, _ struct{}) (*listTiersResponse, error) {
+		tiers, err := fc.queryHandler.ListTiers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &listTiersResponse{Tiers: tiers}, nil
+	})
+}
+
+type tierRequest struct {
+	Name string `path:"name"`
+}
+
+type setTierRequest struct {
+	Name string `path:"name"`
+	application.SetFeeTierCommand
+}
+
+func (fc *FeeController) setTier() lib.Route {
+	return lib.Handle(http.MethodPut, "tiers/:name", func(ctx context.Context, req setTierRequest) (*application.FeeTierDto, error) {
+		cmd := req.SetFeeTierCommand
+		cmd.Name = req.Name
+		return fc.commandHandler.SetTier(ctx, cmd)
+	})
+}
+
+func (fc *FeeController) deleteTier() lib.Route {
+	return lib.Handle(http.MethodDelete, "tiers/:name", func(ctx context.Context, req tierRequest) (struct{}, error) {
+		return struct{}{}, fc.commandHandler.DeleteTier(ctx, req.Name)
+	}).WithStatus(http.StatusNoContent)
+}
+
+type accountTierRequest struct {
+	AccountID string `path:"accountID"`
+}
+
+func (fc *FeeController) getAccountTier() lib.Route {
+	return lib.Handle(http.MethodGet, "accounts/:accountID", func(ctx context.Context, req accountTierRequest) (*application.AccountFeeTierDto, error) {
+		return fc.queryHandler.GetAccountTier(ctx, req.AccountID)
+	})
+}
+
+type assignAccountTierRequest struct {
+	AccountID string `path:"accountID"`
+	application.AssignAccountFeeTierCommand
+}
+
+func (fc *FeeController) assignAccountTier() lib.Route {
+	return lib.Handle(http.MethodPut, "accounts/:accountID", func(ctx context.Context, req assignAccountTierRequest) (*application.AccountFeeTierDto, error) {
+		cmd := req.AssignAccountFeeTierCommand
+		cmd.AccountID = req.AccountID
+		return fc.commandHandler.AssignAccountTier(ctx, cmd)
+	})
+}
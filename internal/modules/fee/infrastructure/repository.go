@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"tradeTornado/internal/modules/fee"
+	"tradeTornado/internal/service/provider"
+
+	"gorm.io/gorm"
+)
+
+type FeeRepository struct {
+	session *provider.GormSession
+}
+
+func NewFeeRepository(session *provider.GormSession) *FeeRepository {
+	return &FeeRepository{
+		session: session,
+	}
+}
+
+func (c *FeeRepository) SaveTier(ctx context.Context, tier *fee.FeeTier) error {
+	return c.session.Gorm().WithContext(ctx).Save(tier).Error
+}
+
+func (c *FeeRepository) DeleteTier(ctx context.Context, name string) error {
+	return c.session.Gorm().WithContext(ctx).Delete(&fee.FeeTier{}, "name = ?", name).Error
+}
+
+func (c *FeeRepository) SaveAccountTier(ctx context.Context, accountTier *fee.AccountFeeTier) error {
+	return c.session.Gorm().WithContext(ctx).Save(accountTier).Error
+}
+
+func (c *FeeRepository) GetTier(ctx context.Context, name string) (*fee.FeeTier, error) {
+	var tier *fee.FeeTier
+	if err := c.session.Gorm().WithContext(ctx).Where("name = ?", name).First(&tier).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fee.FeeTierNotFound
+		}
+		return nil, err
+	}
+	return tier, nil
+}
+
+func (c *FeeRepository) ListTiers(ctx context.Context) ([]*fee.FeeTier, error) {
+	var tiers []*fee.FeeTier
+	if err := c.session.Gorm().WithContext(ctx).Order("name ASC").Find(&tiers).Error; err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+func (c *FeeRepository) GetAccountTier(ctx context.Context, accountID string) (*fee.FeeTier, error) {
+	var accountTier *fee.AccountFeeTier
+	err := c.session.Gorm().WithContext(ctx).Where("account_id = ?", accountID).First(&accountTier).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fee.AccountFeeTierNotFound
+		}
+		return nil, err
+	}
+	return c.GetTier(ctx, accountTier.TierName)
+}
+
+func (c *FeeRepository) CountAccountsInTier(ctx context.Context, name string) (int, error) {
+	var count int64
+	if err := c.session.Gorm().WithContext(ctx).Model(&fee.AccountFeeTier{}).Where("tier_name = ?", name).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (c *FeeRepository) Migrate(ctx context.Context) error {
+	return c.session.Gorm().WithContext(ctx).AutoMigrate(&fee.FeeTier{}, &fee.AccountFeeTier{})
+}
@@ -0,0 +1,20 @@
+package fee
+
+import (
+	"context"
+)
+
+type IFeeWriteRepository interface {
+	SaveTier(ctx context.Context, tier *FeeTier) error
+	DeleteTier(ctx context.Context, name string) error
+	SaveAccountTier(ctx context.Context, accountTier *AccountFeeTier) error
+	IFeeReadRepository
+}
+
+type IFeeReadRepository interface {
+	GetTier(ctx context.Context, name string) (*FeeTier, error)
+	ListTiers(ctx context.Context) ([]*FeeTier, error)
+	// GetAccountTier is the tier assigned to the account, AccountFeeTierNotFound when none is
+	GetAccountTier(ctx context.Context, accountID string) (*FeeTier, error)
+	CountAccountsInTier(ctx context.Context, name string) (int, error)
+}
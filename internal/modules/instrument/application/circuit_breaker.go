@@ -0,0 +1,25 @@
+package application
+
+import (
+	"context"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/instrument"
+)
+
+// CircuitBreaker moves instruments out of continuous trading when the matcher detects a volatility band breach
+type CircuitBreaker struct {
+	instrumentRepository instrument.IInstrumentWriteRepository
+	clock                lib.IClock
+}
+
+func NewCircuitBreaker(instrumentRepository instrument.IInstrumentWriteRepository, clock lib.IClock) *CircuitBreaker {
+	return &CircuitBreaker{instrumentRepository: instrumentRepository, clock: clock}
+}
+
+// Trip locks the instrument so a phase an operator sets meanwhile is neither lost nor overwritten, on the session of
+// the matcher the phase is stored in the transaction of the breaching trades
+func (cb *CircuitBreaker) Trip(ctx context.Context, symbol string) error {
+	return cb.instrumentRepository.Update(ctx, symbol, func(ctx context.Context, ins *instrument.Instrument) error {
+		return ins.TripCircuitBreaker(cb.clock.Now())
+	})
+}
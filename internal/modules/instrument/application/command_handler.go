@@ -0,0 +1,136 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/instrument"
+	"tradeTornado/internal/modules/order"
+
+	"github.com/sirupsen/logrus"
+)
+
+type SetInstrumentCommand struct {
+	Symbol                  string
+	TickSize                lib.Decimal `json:"tickSize"`
+	LotSize                 lib.Decimal `json:"lotSize"`
+	MinOrderQuantity        lib.Decimal `json:"minOrderQuantity"`
+	MaxOrderNotional        lib.Decimal `json:"maxOrderNotional"`
+	MaxOrderQuantity        lib.Decimal `json:"maxOrderQuantity"`
+	PriceCollarBps          int         `json:"priceCollarBps"`
+	MaxOpenOrders           int         `json:"maxOpenOrders"`
+	FatFingerBps            int         `json:"fatFingerBps"`
+	VolatilityBandBps       int         `json:"volatilityBandBps"`
+	VolatilityWindowSeconds int         `json:"volatilityWindowSeconds"`
+	VolatilityPauseSeconds  int         `json:"volatilityPauseSeconds"`
+	// VolatilityPhase is halted or auction, empty means halted
+	VolatilityPhase order.TradingPhase `json:"volatilityPhase"`
+	// AllocationAlgorithm is fifo, pro_rata or top_order_pro_rata, empty means fifo
+	AllocationAlgorithm string      `json:"allocationAlgorithm"`
+	MinAllocation       lib.Decimal `json:"minAllocation"`
+}
+
+type SetTradingPhaseCommand struct {
+	Symbol string
+	Phase  order.TradingPhase `json:"phase"`
+}
+
+type InstrumentCommandHandler struct {
+	instrumentRepository instrument.IInstrumentWriteRepository
+	phaseTransitionGen   func() instrument.PhaseTransition
+	orderCanceller       instrument.IOrderCanceller
+	clock                lib.IClock
+}
+
+func NewInstrumentCommandHandler(instrumentRepository instrument.IInstrumentWriteRepository, phaseTransitionGen func() instrument.PhaseTransition, orderCanceller instrument.IOrderCanceller, clock lib.IClock) *InstrumentCommandHandler {
+	return &InstrumentCommandHandler{instrumentRepository: instrumentRepository, phaseTransitionGen: phaseTransitionGen, orderCanceller: orderCanceller, clock: clock}
+}
+
+func (ich *InstrumentCommandHandler) SetInstrument(ctx context.Context, cmd SetInstrumentCommand) (*InstrumentDto, error) {
+	ins, err := ich.getOrNew(ctx, cmd.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if err := ins.SetTradingRules(cmd.TickSize, cmd.LotSize, cmd.MinOrderQuantity); err != nil {
+		return nil, err
+	}
+	if err := ins.SetRiskLimits(cmd.MaxOrderNotional, cmd.MaxOrderQuantity, cmd.PriceCollarBps, cmd.MaxOpenOrders, cmd.FatFingerBps); err != nil {
+		return nil, err
+	}
+	if err := ins.SetVolatilityBand(cmd.VolatilityBandBps, cmd.VolatilityWindowSeconds, cmd.VolatilityPauseSeconds, cmd.VolatilityPhase); err != nil {
+		return nil, err
+	}
+	if err := ins.SetAllocation(cmd.AllocationAlgorithm, cmd.MinAllocation); err != nil {
+		return nil, err
+	}
+	if err := ich.instrumentRepository.Save(ctx, ins); err != nil {
+		return nil, err
+	}
+	return toInstrumentDto(ins), nil
+}
+
+// SetTradingPhase moves the session of the symbol with its instrument locked. Leaving an auction or a halt uncrosses
+// the book and stores the new phase in the transaction of the uncross, so the matcher never finds an uncrossed book
+// in a call phase nor a crossed one in continuous trading. Stops triggered by the uncross may trip the circuit breaker again
+func (ich *InstrumentCommandHandler) SetTradingPhase(ctx context.Context, cmd SetTradingPhaseCommand) (*InstrumentDto, error) {
+	ins, err := ich.getOrNew(ctx, cmd.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	requiresUncross := ins.RequiresUncross(cmd.Phase)
+	transition := ich.phaseTransitionGen()
+	transit := func(ctx context.Context) error {
+		return transition.Instruments.Update(ctx, cmd.Symbol, func(ctx context.Context, locked *instrument.Instrument) error {
+			ins = locked
+			if locked.RequiresUncross(cmd.Phase) != requiresUncross {
+				validation := lib.NewErrorNotification()
+				validation.Add("phase", fmt.Errorf("%s moved to %s meanwhile", locked.Symbol, locked.Phase))
+				return validation.Err()
+			}
+			return locked.TransitionTo(cmd.Phase)
+		})
+	}
+	if requiresUncross {
+		err = transition.AuctionUncrosser.Uncross(ctx, cmd.Symbol, cmd.Phase, transit)
+	} else {
+		err = transit(ctx)
+	}
+	var tripped *order.CircuitBreakerTripped
+	if errors.As(err, &tripped) {
+		logrus.WithField("symbol", cmd.Symbol).Warningln(tripped)
+		err = ich.instrumentRepository.Update(ctx, cmd.Symbol, func(ctx context.Context, locked *instrument.Instrument) error {
+			ins = locked
+			return locked.TripCircuitBreaker(ich.clock.Now())
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toInstrumentDto(ins), nil
+}
+
+func (ich *InstrumentCommandHandler) getOrNew(ctx context.Context, symbol string) (*instrument.Instrument, error) {
+	ins, err := ich.instrumentRepository.Get(ctx, symbol)
+	if err != nil {
+		if !errors.Is(err, instrument.InstrumentNotFound) {
+			return nil, err
+		}
+		return instrument.NewInstrument(symbol), nil
+	}
+	return ins, nil
+}
+
+// Halt stops trading on the symbol until an operator resumes it
+func (ich *InstrumentCommandHandler) Halt(ctx context.Context, symbol string) (*InstrumentDto, error) {
+	return ich.SetTradingPhase(ctx, SetTradingPhaseCommand{Symbol: symbol, Phase: order.HaltedTradingPhase})
+}
+
+// Resume reopens continuous trading on the symbol through an uncross of the book
+func (ich *InstrumentCommandHandler) Resume(ctx context.Context, symbol string) (*InstrumentDto, error) {
+	return ich.SetTradingPhase(ctx, SetTradingPhaseCommand{Symbol: symbol, Phase: order.ContinuousTradingPhase})
+}
+
+func (ich *InstrumentCommandHandler) CancelAll(ctx context.Context, symbol string) (int, error) {
+	return ich.orderCanceller.CancelAll(ctx, symbol)
+}
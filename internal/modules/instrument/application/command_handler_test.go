@@ -0,0 +1,289 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/instrument"
+	"tradeTornado/internal/modules/instrument/application"
+	"tradeTornado/internal/modules/order"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// memoryInstruments keeps copies of the instruments, an update is only stored when its process succeeds and holds
+// the lock of every instrument until then
+type memoryInstruments struct {
+	lock        sync.Mutex
+	instruments map[string]instrument.Instrument
+}
+
+func (mi *memoryInstruments) Save(_ context.Context, ins *instrument.Instrument) error {
+	mi.lock.Lock()
+	defer mi.lock.Unlock()
+	mi.instruments[ins.Symbol] = *ins
+	return nil
+}
+
+func (mi *memoryInstruments) Update(ctx context.Context, symbol string, process func(ctx context.Context, ins *instrument.Instrument) error) error {
+	mi.lock.Lock()
+	defer mi.lock.Unlock()
+	ins, err := mi.get(symbol)
+	if errors.Is(err, instrument.InstrumentNotFound) {
+		ins, err = instrument.NewInstrument(symbol), nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := process(ctx, ins); err != nil {
+		return err
+	}
+	mi.instruments[symbol] = *ins
+	return nil
+}
+
+func (mi *memoryInstruments) Get(_ context.Context, symbol string) (*instrument.Instrument, error) {
+	mi.lock.Lock()
+	defer mi.lock.Unlock()
+	return mi.get(symbol)
+}
+
+func (mi *memoryInstruments) get(symbol string) (*instrument.Instrument, error) {
+	ins, ok := mi.instruments[symbol]
+	if !ok {
+		return nil, instrument.InstrumentNotFound
+	}
+	return &ins, nil
+}
+
+func (mi *memoryInstruments) List(context.Context) ([]*instrument.Instrument, error) {
+	mi.lock.Lock()
+	defer mi.lock.Unlock()
+	var instruments []*instrument.Instrument
+	for _, ins := range mi.instruments {
+		copied := ins
+		instruments = append(instruments, &copied)
+	}
+	return instruments, nil
+}
+
+func (mi *memoryInstruments) ListPhaseExpired(_ context.Context, now time.Time) ([]*instrument.Instrument, error) {
+	mi.lock.Lock()
+	defer mi.lock.Unlock()
+	var instruments []*instrument.Instrument
+	for _, ins := range mi.instruments {
+		if ins.PhaseUntil != nil && !ins.PhaseUntil.After(now) {
+			copied := ins
+			instruments = append(instruments, &copied)
+		}
+	}
+	return instruments, nil
+}
+
+// recordingUncrosser keeps the uncross only when commit succeeds, as the transaction of the matcher would
+type recordingUncrosser struct {
+	uncrossed []order.TradingPhase
+	before    func()
+	err       error
+}
+
+func (ru *recordingUncrosser) Uncross(ctx context.Context, _ string, next order.TradingPhase, commit func(ctx context.Context) error) error {
+	if ru.before != nil {
+		ru.before()
+	}
+	if err := commit(ctx); err != nil {
+		return err
+	}
+	ru.uncrossed = append(ru.uncrossed, next)
+	return ru.err
+}
+
+func (ru *recordingUncrosser) CancelAll(context.Context, string) (int, error) {
+	return 0, nil
+}
+
+// ownedSymbols are the symbols whose partitions are assigned to the instance
+type ownedSymbols map[string]bool
+
+func (os ownedSymbols) Owns(symbol string) bool {
+	return os[symbol]
+}
+
+type InstrumentCommandHandlerTestSuite struct {
+	suite.Suite
+	instruments *memoryInstruments
+	uncrosser   *recordingUncrosser
+	owned       ownedSymbols
+	clock       *lib.SimulatedClock
+	handler     *application.InstrumentCommandHandler
+	breaker     *application.CircuitBreaker
+}
+
+func TestInstrumentCommandHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(InstrumentCommandHandlerTestSuite))
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) SetupTest() {
+	suite.instruments = &memoryInstruments{instruments: make(map[string]instrument.Instrument)}
+	suite.uncrosser = &recordingUncrosser{}
+	suite.owned = ownedSymbols{"BTC-USD": true}
+	suite.clock = lib.NewSimulatedClock(time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC))
+	suite.handler = application.NewInstrumentCommandHandler(suite.instruments, func() instrument.PhaseTransition {
+		return instrument.PhaseTransition{Instruments: suite.instruments, AuctionUncrosser: suite.uncrosser}
+	}, suite.uncrosser, suite.clock)
+	suite.breaker = application.NewCircuitBreaker(suite.instruments, suite.clock)
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) setPhase(phase order.TradingPhase) (*application.InstrumentDto, error) {
+	return suite.handler.SetTradingPhase(context.Background(), application.SetTradingPhaseCommand{Symbol: "BTC-USD", Phase: phase})
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) phase() order.TradingPhase {
+	return suite.instrument().Phase
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) instrument() *instrument.Instrument {
+	ins, err := suite.instruments.Get(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	return ins
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) setVolatilityBand(pauseSeconds int, phase order.TradingPhase) {
+	_, err := suite.handler.SetInstrument(context.Background(), application.SetInstrumentCommand{Symbol: "BTC-USD",
+		VolatilityBandBps: 100, VolatilityWindowSeconds: 60, VolatilityPauseSeconds: pauseSeconds, VolatilityPhase: phase})
+	suite.Require().NoError(err)
+}
+
+// schedule runs the session scheduler until resumed returns true
+func (suite *InstrumentCommandHandlerTestSuite) schedule(resumed func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- application.NewTradingSessionScheduler(suite.instruments, suite.owned, suite.handler, time.Millisecond, suite.clock).Run(ctx)
+	}()
+	suite.Eventually(resumed, time.Second, time.Millisecond)
+	cancel()
+	suite.NoError(<-done)
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) TestLeavingAnAuctionStoresThePhaseWithTheUncross() {
+	_, err := suite.setPhase(order.AuctionTradingPhase)
+	suite.Require().NoError(err)
+	suite.Empty(suite.uncrosser.uncrossed)
+	suite.Equal(order.AuctionTradingPhase, suite.phase())
+
+	dto, err := suite.setPhase(order.ContinuousTradingPhase)
+	suite.Require().NoError(err)
+	suite.Equal(order.ContinuousTradingPhase, dto.Phase)
+	suite.Equal([]order.TradingPhase{order.ContinuousTradingPhase}, suite.uncrosser.uncrossed)
+	suite.Equal(order.ContinuousTradingPhase, suite.phase())
+
+	_, err = suite.setPhase(order.PreOpenTradingPhase)
+	suite.Error(err)
+	suite.Equal(order.ContinuousTradingPhase, suite.phase())
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) TestAPhaseMovedDuringTheUncrossRollsItBack() {
+	_, err := suite.setPhase(order.AuctionTradingPhase)
+	suite.Require().NoError(err)
+	suite.uncrosser.before = func() {
+		closed := suite.instruments.instruments["BTC-USD"]
+		closed.Phase = order.ClosedTradingPhase
+		suite.instruments.instruments["BTC-USD"] = closed
+	}
+
+	_, err = suite.setPhase(order.ContinuousTradingPhase)
+	suite.Error(err)
+	suite.Empty(suite.uncrosser.uncrossed)
+	suite.Equal(order.ClosedTradingPhase, suite.phase())
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) TestStopsBreachingTheBandTripTheBreakerAfterTheUncross() {
+	_, err := suite.handler.SetInstrument(context.Background(), application.SetInstrumentCommand{Symbol: "BTC-USD", VolatilityBandBps: 100, VolatilityPhase: order.AuctionTradingPhase})
+	suite.Require().NoError(err)
+	_, err = suite.handler.Halt(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	suite.uncrosser.err = &order.CircuitBreakerTripped{Symbol: "BTC-USD"}
+
+	dto, err := suite.handler.Resume(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	suite.Equal([]order.TradingPhase{order.ContinuousTradingPhase}, suite.uncrosser.uncrossed)
+	suite.Equal(order.AuctionTradingPhase, dto.Phase)
+	suite.Equal(order.AuctionTradingPhase, suite.phase())
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) TestABreachRunsAVolatilityAuctionUntilThePauseEnds() {
+	suite.setVolatilityBand(30, order.AuctionTradingPhase)
+	suite.Require().NoError(suite.breaker.Trip(context.Background(), "BTC-USD"))
+	suite.Equal(order.AuctionTradingPhase, suite.phase())
+	until := suite.clock.Now().Add(30 * time.Second)
+	suite.Equal(&until, suite.instrument().PhaseUntil)
+
+	suite.clock.Set(suite.clock.Now().Add(10 * time.Second))
+	suite.Require().NoError(suite.breaker.Trip(context.Background(), "BTC-USD"))
+	suite.Equal(&until, suite.instrument().PhaseUntil)
+	expired, err := suite.instruments.ListPhaseExpired(context.Background(), until.Add(-time.Nanosecond))
+	suite.Require().NoError(err)
+	suite.Empty(expired)
+
+	suite.schedule(func() bool {
+		suite.clock.Set(until)
+		return suite.phase() == order.ContinuousTradingPhase
+	})
+	suite.Equal([]order.TradingPhase{order.ContinuousTradingPhase}, suite.uncrosser.uncrossed)
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) TestABreachHaltsUntilThePauseEndsOrAnOperatorResumes() {
+	suite.setVolatilityBand(0, order.HaltedTradingPhase)
+	suite.Require().NoError(suite.breaker.Trip(context.Background(), "BTC-USD"))
+	suite.Equal(order.HaltedTradingPhase, suite.phase())
+	suite.Nil(suite.instrument().PhaseUntil)
+	expired, err := suite.instruments.ListPhaseExpired(context.Background(), suite.clock.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+	suite.Empty(expired)
+
+	dto, err := suite.handler.Resume(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	suite.Equal(order.ContinuousTradingPhase, dto.Phase)
+
+	suite.setVolatilityBand(60, order.HaltedTradingPhase)
+	suite.Require().NoError(suite.breaker.Trip(context.Background(), "BTC-USD"))
+	suite.Equal(order.HaltedTradingPhase, suite.phase())
+	suite.schedule(func() bool {
+		suite.clock.Set(suite.clock.Now().Add(time.Second))
+		return suite.phase() == order.ContinuousTradingPhase
+	})
+	suite.Equal([]order.TradingPhase{order.ContinuousTradingPhase, order.ContinuousTradingPhase}, suite.uncrosser.uncrossed)
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) TestAnOperatorHaltIsNotOverwrittenByTheBreaker() {
+	suite.setVolatilityBand(30, order.AuctionTradingPhase)
+	_, err := suite.handler.Halt(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.breaker.Trip(context.Background(), "BTC-USD"))
+	suite.Equal(order.HaltedTradingPhase, suite.phase())
+	suite.Nil(suite.instrument().PhaseUntil)
+}
+
+func (suite *InstrumentCommandHandlerTestSuite) TestTheSchedulerResumesOnlyTheOwnedSymbols() {
+	suite.setVolatilityBand(30, order.HaltedTradingPhase)
+	_, err := suite.handler.SetInstrument(context.Background(), application.SetInstrumentCommand{Symbol: "ETH-USD",
+		VolatilityBandBps: 100, VolatilityWindowSeconds: 60, VolatilityPauseSeconds: 30, VolatilityPhase: order.HaltedTradingPhase})
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.breaker.Trip(context.Background(), "BTC-USD"))
+	suite.Require().NoError(suite.breaker.Trip(context.Background(), "ETH-USD"))
+	suite.clock.Set(suite.clock.Now().Add(time.Minute))
+
+	// the owner of ETH-USD resumes it on its own instance
+	suite.schedule(func() bool {
+		return suite.phase() == order.ContinuousTradingPhase
+	})
+	eth, err := suite.instruments.Get(context.Background(), "ETH-USD")
+	suite.Require().NoError(err)
+	suite.Equal(order.HaltedTradingPhase, eth.Phase)
+	suite.Equal([]order.TradingPhase{order.ContinuousTradingPhase}, suite.uncrosser.uncrossed)
+}
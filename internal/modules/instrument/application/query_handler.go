@@ -0,0 +1,146 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/instrument"
+	"tradeTornado/internal/modules/order"
+)
+
+type InstrumentDto struct {
+	Symbol                  string
+	TickSize                lib.Decimal
+	LotSize                 lib.Decimal
+	MinOrderQuantity        lib.Decimal
+	MaxOrderNotional        lib.Decimal
+	MaxOrderQuantity        lib.Decimal
+	PriceCollarBps          int
+	MaxOpenOrders           int
+	FatFingerBps            int
+	VolatilityBandBps       int
+	VolatilityWindowSeconds int
+	VolatilityPauseSeconds  int
+	VolatilityPhase         order.TradingPhase
+	AllocationAlgorithm     order.AllocationAlgorithm
+	MinAllocation           lib.Decimal
+	Phase                   order.TradingPhase
+	PhaseUntil              *time.Time
+}
+
+type InstrumentQueryHandler struct {
+	instrumentRepository instrument.IInstrumentReadRepository
+}
+
+func NewInstrumentQueryHandler(instrumentRepository instrument.IInstrumentReadRepository) *InstrumentQueryHandler {
+	return &InstrumentQueryHandler{instrumentRepository: instrumentRepository}
+}
+
+func (iqh *InstrumentQueryHandler) ListInstruments(ctx context.Context) ([]*InstrumentDto, error) {
+	instruments, err := iqh.instrumentRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*InstrumentDto, 0)
+	for _, ins := range instruments {
+		dtos = append(dtos, toInstrumentDto(ins))
+	}
+	return dtos, nil
+}
+
+func (iqh *InstrumentQueryHandler) GetInstrument(ctx context.Context, symbol string) (*InstrumentDto, error) {
+	ins, err := iqh.instrumentRepository.Get(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return toInstrumentDto(ins), nil
+}
+
+// GetRiskLimits returns no limits for symbols without configuration
+func (iqh *InstrumentQueryHandler) GetRiskLimits(ctx context.Context, symbol string) (order.RiskLimits, error) {
+	ins, err := iqh.instrumentRepository.Get(ctx, symbol)
+	if err != nil {
+		if errors.Is(err, instrument.InstrumentNotFound) {
+			return order.RiskLimits{}, nil
+		}
+		return order.RiskLimits{}, err
+	}
+	return order.RiskLimits{
+		MaxOrderNotional: ins.MaxOrderNotional,
+		MaxOrderQuantity: ins.MaxOrderQuantity,
+		PriceCollarBps:   ins.PriceCollarBps,
+		MaxOpenOrders:    ins.MaxOpenOrders,
+		FatFingerBps:     ins.FatFingerBps,
+	}, nil
+}
+
+// GetTradingPhase returns continuous trading for symbols without configuration
+func (iqh *InstrumentQueryHandler) GetTradingPhase(ctx context.Context, symbol string) (order.TradingPhase, error) {
+	ins, err := iqh.instrumentRepository.Get(ctx, symbol)
+	if err != nil {
+		if errors.Is(err, instrument.InstrumentNotFound) {
+			return order.ContinuousTradingPhase, nil
+		}
+		return "", err
+	}
+	return ins.Phase, nil
+}
+
+// GetTradingRules returns the default increments for symbols without configuration
+func (iqh *InstrumentQueryHandler) GetTradingRules(ctx context.Context, symbol string) (order.TradingRules, error) {
+	ins, err := iqh.instrumentRepository.Get(ctx, symbol)
+	if err != nil {
+		if errors.Is(err, instrument.InstrumentNotFound) {
+			return order.DefaultTradingRules(), nil
+		}
+		return order.TradingRules{}, err
+	}
+	return ins.TradingRules(), nil
+}
+
+// GetVolatilityBand returns a disabled band for symbols without configuration
+func (iqh *InstrumentQueryHandler) GetVolatilityBand(ctx context.Context, symbol string) (order.VolatilityBand, error) {
+	ins, err := iqh.instrumentRepository.Get(ctx, symbol)
+	if err != nil {
+		if errors.Is(err, instrument.InstrumentNotFound) {
+			return order.VolatilityBand{}, nil
+		}
+		return order.VolatilityBand{}, err
+	}
+	return ins.VolatilityBand(), nil
+}
+
+// GetAllocationRules returns FIFO for symbols without configuration
+func (iqh *InstrumentQueryHandler) GetAllocationRules(ctx context.Context, symbol string) (order.AllocationRules, error) {
+	ins, err := iqh.instrumentRepository.Get(ctx, symbol)
+	if err != nil {
+		if errors.Is(err, instrument.InstrumentNotFound) {
+			return order.DefaultAllocationRules(), nil
+		}
+		return order.AllocationRules{}, err
+	}
+	return ins.AllocationRules(), nil
+}
+
+func toInstrumentDto(ins *instrument.Instrument) *InstrumentDto {
+	return &InstrumentDto{
+		Symbol:                  ins.Symbol,
+		TickSize:                ins.TickSize,
+		LotSize:                 ins.LotSize,
+		MinOrderQuantity:        ins.MinOrderQuantity,
+		MaxOrderNotional:        ins.MaxOrderNotional,
+		MaxOrderQuantity:        ins.MaxOrderQuantity,
+		PriceCollarBps:          ins.PriceCollarBps,
+		MaxOpenOrders:           ins.MaxOpenOrders,
+		FatFingerBps:            ins.FatFingerBps,
+		VolatilityBandBps:       ins.VolatilityBandBps,
+		VolatilityWindowSeconds: ins.VolatilityWindowSeconds,
+		VolatilityPauseSeconds:  ins.VolatilityPauseSeconds,
+		VolatilityPhase:         ins.VolatilityPhase,
+		AllocationAlgorithm:     ins.AllocationAlgorithm,
+		MinAllocation:           ins.MinAllocation,
+		Phase:                   ins.Phase,
+		PhaseUntil:              ins.PhaseUntil,
+	}
+}
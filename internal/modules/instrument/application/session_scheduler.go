@@ -0,0 +1,66 @@
+package application
+
+import (
+	"context"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/instrument"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TradingSessionScheduler resumes continuous trading once an automatic halt or volatility auction has run its course.
+// Every matcher instance runs one, each resumes only the symbols it owns so an expired phase is resumed once
+type TradingSessionScheduler struct {
+	instrumentRepository instrument.IInstrumentReadRepository
+	ownership            instrument.ISymbolOwnership
+	commandHandler       *InstrumentCommandHandler
+	interval             time.Duration
+	clock                lib.IClock
+}
+
+func NewTradingSessionScheduler(instrumentRepository instrument.IInstrumentReadRepository, ownership instrument.ISymbolOwnership, commandHandler *InstrumentCommandHandler, interval time.Duration, clock lib.IClock) *TradingSessionScheduler {
+	return &TradingSessionScheduler{
+		instrumentRepository: instrumentRepository,
+		ownership:            ownership,
+		commandHandler:       commandHandler,
+		interval:             interval,
+		clock:                clock,
+	}
+}
+
+func (tss *TradingSessionScheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(tss.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := tss.resumeExpired(ctx, tss.clock.Now()); err != nil {
+				logrus.WithField("Executor", tss.GetRepresentation()).Errorln(err)
+			}
+		}
+	}
+}
+
+func (tss *TradingSessionScheduler) resumeExpired(ctx context.Context, now time.Time) error {
+	instruments, err := tss.instrumentRepository.ListPhaseExpired(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, ins := range instruments {
+		if !tss.ownership.Owns(ins.Symbol) {
+			continue
+		}
+		if _, err := tss.commandHandler.Resume(ctx, ins.Symbol); err != nil {
+			return err
+		}
+		logrus.WithField("symbol", ins.Symbol).Infoln("continuous trading resumed")
+	}
+	return nil
+}
+
+func (tss *TradingSessionScheduler) GetRepresentation() string {
+	return "TradingSessionScheduler"
+}
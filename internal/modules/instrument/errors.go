@@ -0,0 +1,9 @@
+package instrument
+
+import (
+	"tradeTornado/internal/lib"
+)
+
+var (
+	InstrumentNotFound = lib.NewNotFoundError("instrument")
+)
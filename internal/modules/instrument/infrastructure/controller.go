@@ -0,0 +1,116 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/instrument/application"
+)
+
+type InstrumentController struct {
+	commandHandler *application.InstrumentCommandHandler
+	queryHandler   *application.InstrumentQueryHandler
+}
+
+func NewInstrumentController(ch *application.InstrumentCommandHandler, qh *application.InstrumentQueryHandler) *InstrumentController {
+	return &InstrumentController{
+		commandHandler: ch,
+		queryHandler:   qh,
+	}
+}
+
+func (ic *InstrumentController) GetRoutes() []lib.Route {
+	return []lib.Route{
+		ic.listInstruments(),
+		ic.getInstrument(),
+		ic.setInstrument(),
+		ic.setTradingPhase(),
+		ic.halt(),
+		ic.resume(),
+		ic.cancelAll(),
+	}
+}
+
+func (ic *InstrumentController) GetRoot() string {
+	return "admin/instruments"
+}
+
+func (ic *InstrumentController) GetMiddlewares() []lib.Middleware {
+	return nil
+}
+
+type listInstrumentsResponse struct {
+	Instruments []*application.InstrumentDto `json:"instruments"`
+}
+
+func (ic *InstrumentController) listInstruments() lib.Route {
+	return lib.Handle(http.MethodGet, "", func(ctx context.Context, _ struct{}) (*listInstrumentsResponse, error) {
+		instruments, err := ic.queryHandler.ListInstruments(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &listInstrumentsResponse{Instruments: instruments}, nil
+	})
+}
+
+type symbolRequest struct {
+	Symbol string `path:"symbol"`
+}
+
+func (ic *InstrumentController) getInstrument() lib.Route {
+	return lib.Handle(http.MethodGet, ":symbol", func(ctx context.Context, req symbolRequest) (*application.InstrumentDto, error) {
+		return ic.queryHandler.GetInstrument(ctx, req.Symbol)
+	})
+}
+
+type setInstrumentRequest struct {
+	Symbol string `path:"symbol"`
+	application.SetInstrumentCommand
+}
+
+func (ic *InstrumentController) setInstrument() lib.Route {
+	return lib.Handle(http.MethodPut, ":symbol", func(ctx context.Context, req setInstrumentRequest) (*application.InstrumentDto, error) {
+		cmd := req.SetInstrumentCommand
+		cmd.Symbol = req.Symbol
+		return ic.commandHandler.SetInstrument(ctx, cmd)
+	})
+}
+
+type setTradingPhaseRequest struct {
+	Symbol string `path:"symbol"`
+	application.SetTradingPhaseCommand
+}
+
+func (ic *InstrumentController) setTradingPhase() lib.Route {
+	return lib.Handle(http.MethodPut, ":symbol/phase", func(ctx context.Context, req setTradingPhaseRequest) (*application.InstrumentDto, error) {
+		cmd := req.SetTradingPhaseCommand
+		cmd.Symbol = req.Symbol
+		return ic.commandHandler.SetTradingPhase(ctx, cmd)
+	})
+}
+
+func (ic *InstrumentController) halt() lib.Route {
+	return lib.Handle(http.MethodPost, ":symbol/halt", func(ctx context.Context, req symbolRequest) (*application.InstrumentDto, error) {
+		return ic.commandHandler.Halt(ctx, req.Symbol)
+	})
+}
+
+func (ic *InstrumentController) resume() lib.Route {
+	return lib.Handle(http.MethodPost, ":symbol/resume", func(ctx context.Context, req symbolRequest) (*application.InstrumentDto, error) {
+		return ic.commandHandler.Resume(ctx, req.Symbol)
+	})
+}
+
+type cancelAllResponse struct {
+	Cancelled int `json:"cancelled"`
+}
+
+func (ic *InstrumentController) cancelAll() lib.Route {
+	return lib.Handle(http.MethodPost, ":symbol/cancel-all", func(ctx context.Context, req symbolRequest) (*cancelAllResponse, error) {
+		cancelled, err := ic.commandHandler.CancelAll(ctx, req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		return &cancelAllResponse{Cancelled: cancelled}, nil
+	})
+}
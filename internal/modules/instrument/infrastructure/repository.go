@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tradeTornado/internal/modules/instrument"
+	"tradeTornado/internal/service/provider"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InstrumentRepository struct {
+	session *provider.GormSession
+}
+
+func NewInstrumentRepository(session *provider.GormSession) *InstrumentRepository {
+	return &InstrumentRepository{
+		session: session,
+	}
+}
+
+func (c *InstrumentRepository) Save(ctx context.Context, ins *instrument.Instrument) error {
+	return c.session.Gorm().WithContext(ctx).Save(ins).Error
+}
+
+func (c *InstrumentRepository) Update(ctx context.Context, symbol string, process func(ctx context.Context, ins *instrument.Instrument) error) error {
+	return c.session.RunTx(ctx, func() error {
+		var ins *instrument.Instrument
+		err := c.session.Gorm().WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("symbol = ?", symbol).First(&ins).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ins, err = instrument.NewInstrument(symbol), nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := process(ctx, ins); err != nil {
+			return err
+		}
+		return c.session.Gorm().WithContext(ctx).Save(ins).Error
+	})
+}
+
+func (c *InstrumentRepository) Get(ctx context.Context, symbol string) (*instrument.Instrument, error) {
+	var ins *instrument.Instrument
+	if err := c.session.Gorm().WithContext(ctx).Where("symbol = ?", symbol).First(&ins).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, instrument.InstrumentNotFound
+		}
+		return nil, err
+	}
+	return ins, nil
+}
+
+func (c *InstrumentRepository) List(ctx context.Context) ([]*instrument.Instrument, error) {
+	var instruments []*instrument.Instrument
+	if err := c.session.Gorm().WithContext(ctx).Order("symbol ASC").Find(&instruments).Error; err != nil {
+		return nil, err
+	}
+	return instruments, nil
+}
+
+func (c *InstrumentRepository) ListPhaseExpired(ctx context.Context, now time.Time) ([]*instrument.Instrument, error) {
+	var instruments []*instrument.Instrument
+	if err := c.session.Gorm().WithContext(ctx).
+		Where("phase_until is not null and phase_until <= ?", now).
+		Order("symbol ASC").
+		Find(&instruments).Error; err != nil {
+		return nil, err
+	}
+	return instruments, nil
+}
+
+func (c *InstrumentRepository) Migrate(ctx context.Context) error {
+	return c.session.Gorm().WithContext(ctx).AutoMigrate(&instrument.Instrument{})
+}
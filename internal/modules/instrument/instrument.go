@@ -0,0 +1,190 @@
+package instrument
+
+import (
+	"fmt"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+)
+
+// phaseTransitions is the trading session state machine, auctions end in continuous trading or in the close
+var phaseTransitions = map[order.TradingPhase][]order.TradingPhase{
+	order.ClosedTradingPhase:     {order.PreOpenTradingPhase},
+	order.PreOpenTradingPhase:    {order.AuctionTradingPhase, order.ClosedTradingPhase},
+	order.AuctionTradingPhase:    {order.ContinuousTradingPhase, order.ClosedTradingPhase, order.HaltedTradingPhase},
+	order.ContinuousTradingPhase: {order.AuctionTradingPhase, order.HaltedTradingPhase, order.ClosedTradingPhase},
+	order.HaltedTradingPhase:     {order.AuctionTradingPhase, order.ContinuousTradingPhase, order.ClosedTradingPhase},
+}
+
+// Instrument holds the trading rules of a symbol, zero limits disable the related pre-trade check
+type Instrument struct {
+	Symbol string `gorm:"primarykey;column:symbol"`
+	// TickSize and LotSize are the price and quantity increments, MinOrderQuantity zero disables the minimum
+	TickSize         lib.Decimal `gorm:"column:tick_size;default:1"`
+	LotSize          lib.Decimal `gorm:"column:lot_size;default:1"`
+	MinOrderQuantity lib.Decimal `gorm:"column:min_order_quantity;default:0"`
+	MaxOrderNotional lib.Decimal `gorm:"column:max_order_notional"`
+	MaxOrderQuantity lib.Decimal `gorm:"column:max_order_quantity"`
+	PriceCollarBps   int         `gorm:"column:price_collar_bps"`
+	MaxOpenOrders    int         `gorm:"column:max_open_orders"`
+	FatFingerBps     int         `gorm:"column:fat_finger_bps"`
+	// VolatilityBandBps is the allowed move from the first trade of the trailing window, zero disables the circuit breaker
+	VolatilityBandBps       int `gorm:"column:volatility_band_bps;default:0"`
+	VolatilityWindowSeconds int `gorm:"column:volatility_window_seconds;default:0"`
+	// VolatilityPauseSeconds is how long a tripped circuit breaker stops continuous trading, zero waits for an operator
+	VolatilityPauseSeconds int                `gorm:"column:volatility_pause_seconds;default:0"`
+	VolatilityPhase        order.TradingPhase `gorm:"column:volatility_phase;default:halted"`
+	// AllocationAlgorithm splits fills over the orders of a price level, MinAllocation only applies to pro-rata shares
+	AllocationAlgorithm order.AllocationAlgorithm `gorm:"column:allocation_algorithm;default:fifo"`
+	MinAllocation       lib.Decimal               `gorm:"column:min_allocation;default:0"`
+	// Phase defaults to continuous so symbols keep matching without a configured session
+	Phase order.TradingPhase `gorm:"column:phase;default:continuous"`
+	// PhaseUntil is when an automatic halt or volatility auction returns to continuous trading
+	PhaseUntil *time.Time `gorm:"column:phase_until;index"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewInstrument(symbol string) *Instrument {
+	rules := order.DefaultTradingRules()
+	return &Instrument{
+		Symbol:              symbol,
+		TickSize:            rules.TickSize,
+		LotSize:             rules.LotSize,
+		Phase:               order.ContinuousTradingPhase,
+		VolatilityPhase:     order.HaltedTradingPhase,
+		AllocationAlgorithm: order.FIFOAllocationAlgorithm,
+	}
+}
+
+// SetTradingRules zero tick and lot sizes fall back to the default increments
+func (ins *Instrument) SetTradingRules(tickSize, lotSize, minOrderQuantity lib.Decimal) error {
+	defaults := order.DefaultTradingRules()
+	if tickSize == 0 {
+		tickSize = defaults.TickSize
+	}
+	if lotSize == 0 {
+		lotSize = defaults.LotSize
+	}
+	ins.TickSize = tickSize
+	ins.LotSize = lotSize
+	ins.MinOrderQuantity = minOrderQuantity
+	return ins.validate()
+}
+
+func (ins *Instrument) TradingRules() order.TradingRules {
+	return order.TradingRules{TickSize: ins.TickSize, LotSize: ins.LotSize, MinOrderQuantity: ins.MinOrderQuantity}
+}
+
+// SetAllocation an empty algorithm keeps the instrument on FIFO
+func (ins *Instrument) SetAllocation(algorithm string, minAllocation lib.Decimal) error {
+	allocationAlgorithm, err := order.ParseAllocationAlgorithm(algorithm)
+	if err != nil {
+		return err
+	}
+	ins.AllocationAlgorithm = allocationAlgorithm
+	ins.MinAllocation = minAllocation
+	return ins.validate()
+}
+
+func (ins *Instrument) AllocationRules() order.AllocationRules {
+	return order.AllocationRules{Algorithm: ins.AllocationAlgorithm, MinAllocation: ins.MinAllocation, LotSize: ins.LotSize}
+}
+
+// SetVolatilityBand configures the circuit breaker, the session moves to phase for pauseSeconds when it trips
+func (ins *Instrument) SetVolatilityBand(bandBps, windowSeconds, pauseSeconds int, phase order.TradingPhase) error {
+	if phase == "" {
+		phase = order.HaltedTradingPhase
+	}
+	ins.VolatilityBandBps = bandBps
+	ins.VolatilityWindowSeconds = windowSeconds
+	ins.VolatilityPauseSeconds = pauseSeconds
+	ins.VolatilityPhase = phase
+	return ins.validate()
+}
+
+// TripCircuitBreaker stops continuous trading after a volatility band breach, sessions in other phases are left as they are
+func (ins *Instrument) TripCircuitBreaker(now time.Time) error {
+	if !ins.Phase.IsContinuous() {
+		return nil
+	}
+	if err := ins.TransitionTo(ins.VolatilityPhase); err != nil {
+		return err
+	}
+	if ins.VolatilityPauseSeconds > 0 {
+		until := now.Add(time.Duration(ins.VolatilityPauseSeconds) * time.Second)
+		ins.PhaseUntil = &until
+	}
+	return nil
+}
+
+// TransitionTo moves the trading session to phase, only transitions of the session state machine are allowed
+func (ins *Instrument) TransitionTo(phase order.TradingPhase) error {
+	for _, allowed := range phaseTransitions[ins.Phase] {
+		if allowed == phase {
+			ins.Phase = phase
+			ins.PhaseUntil = nil
+			return nil
+		}
+	}
+	validation := lib.NewErrorNotification()
+	validation.Add("phase", fmt.Errorf("can not move from %s to %s", ins.Phase, phase))
+	return validation.Err()
+}
+
+// RequiresUncross reports whether moving to phase uncrosses the call book, a halt may leave a crossed book
+// behind so resuming from it reopens through an uncross as well
+func (ins *Instrument) RequiresUncross(phase order.TradingPhase) bool {
+	switch ins.Phase {
+	case order.AuctionTradingPhase:
+		return phase == order.ContinuousTradingPhase || phase == order.ClosedTradingPhase
+	case order.HaltedTradingPhase:
+		return phase == order.ContinuousTradingPhase
+	}
+	return false
+}
+
+func (ins *Instrument) VolatilityBand() order.VolatilityBand {
+	return order.VolatilityBand{
+		BandBps: ins.VolatilityBandBps,
+		Window:  time.Duration(ins.VolatilityWindowSeconds) * time.Second,
+		Phase:   ins.VolatilityPhase,
+	}
+}
+
+func (ins *Instrument) SetRiskLimits(maxOrderNotional, maxOrderQuantity lib.Decimal, priceCollarBps, maxOpenOrders, fatFingerBps int) error {
+	ins.MaxOrderNotional = maxOrderNotional
+	ins.MaxOrderQuantity = maxOrderQuantity
+	ins.PriceCollarBps = priceCollarBps
+	ins.MaxOpenOrders = maxOpenOrders
+	ins.FatFingerBps = fatFingerBps
+	return ins.validate()
+}
+
+func (ins *Instrument) validate() error {
+	validation := lib.NewErrorNotification()
+
+	validation.StringNotEmpty("symbol", ins.Symbol)
+	validation.DecimalShouldBePositive("tick_size", ins.TickSize)
+	validation.DecimalShouldBePositive("lot_size", ins.LotSize)
+	validation.DecimalShouldNotBeNegative("min_order_quantity", ins.MinOrderQuantity)
+	validation.DecimalShouldBeMultipleOf("min_order_quantity", ins.MinOrderQuantity, ins.LotSize)
+	if ins.MaxOrderQuantity > 0 {
+		validation.DecimalShouldBeGTE("max_order_quantity", ins.MaxOrderQuantity, ins.MinOrderQuantity)
+	}
+	validation.DecimalShouldNotBeNegative("max_order_notional", ins.MaxOrderNotional)
+	validation.DecimalShouldNotBeNegative("max_order_quantity", ins.MaxOrderQuantity)
+	validation.DecimalShouldNotBeNegative("min_allocation", ins.MinAllocation)
+	validation.DecimalShouldBeMultipleOf("min_allocation", ins.MinAllocation, ins.LotSize)
+	validation.IntShouldBeGTE("price_collar_bps", ins.PriceCollarBps, 0)
+	validation.IntShouldBeGTE("max_open_orders", ins.MaxOpenOrders, 0)
+	validation.IntShouldBeGTE("fat_finger_bps", ins.FatFingerBps, 0)
+	validation.IntShouldBeGTE("volatility_band_bps", ins.VolatilityBandBps, 0)
+	validation.IntShouldBeGTE("volatility_window_seconds", ins.VolatilityWindowSeconds, 0)
+	validation.IntShouldBeGTE("volatility_pause_seconds", ins.VolatilityPauseSeconds, 0)
+	if ins.VolatilityPhase != order.HaltedTradingPhase && ins.VolatilityPhase != order.AuctionTradingPhase {
+		validation.Add("volatility_phase", fmt.Errorf("circuit breaker can only move to %s or %s", order.HaltedTradingPhase, order.AuctionTradingPhase))
+	}
+
+	return validation.Err()
+}
@@ -0,0 +1,45 @@
+package instrument
+
+import (
+	"context"
+	"time"
+	"tradeTornado/internal/modules/order"
+)
+
+type IInstrumentWriteRepository interface {
+	Save(ctx context.Context, ins *Instrument) error
+	// Update locks the instrument of symbol, a new one when the symbol has none, and saves it once process returns.
+	// The lock is held until the transaction process runs in is committed
+	Update(ctx context.Context, symbol string, process func(ctx context.Context, ins *Instrument) error) error
+	IInstrumentReadRepository
+}
+
+type IInstrumentReadRepository interface {
+	Get(ctx context.Context, symbol string) (*Instrument, error)
+	List(ctx context.Context) ([]*Instrument, error)
+	// ListPhaseExpired returns the instruments whose automatic halt or volatility auction ended before now
+	ListPhaseExpired(ctx context.Context, now time.Time) ([]*Instrument, error)
+}
+
+// IAuctionUncrosser executes the call book of a symbol when its auction ends, commit runs in the transaction of the
+// uncross before the executions are committed
+type IAuctionUncrosser interface {
+	Uncross(ctx context.Context, symbol string, next order.TradingPhase, commit func(ctx context.Context) error) error
+}
+
+// PhaseTransition is an instrument repository and an uncrosser sharing one database session, so a phase leaving an
+// auction is stored in the transaction of the uncross
+type PhaseTransition struct {
+	Instruments      IInstrumentWriteRepository
+	AuctionUncrosser IAuctionUncrosser
+}
+
+// IOrderCanceller cancels every resting and pending order of a symbol
+type IOrderCanceller interface {
+	CancelAll(ctx context.Context, symbol string) (int, error)
+}
+
+// ISymbolOwnership tells whether this instance matches the symbol
+type ISymbolOwnership interface {
+	Owns(symbol string) bool
+}
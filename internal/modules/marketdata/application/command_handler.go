@@ -0,0 +1,139 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/marketdata"
+
+	"github.com/sirupsen/logrus"
+)
+
+const backfillBatchSize = 1000
+
+type RecordExecutionCommand struct {
+	TradeID   uint
+	Symbol    string
+	Price     lib.Decimal
+	Quantity  lib.Decimal
+	CreatedAt time.Time
+}
+
+// BackfillCandlesCommand rebuilds the candles of every whole UTC day overlapping [From, To)
+type BackfillCandlesCommand struct {
+	Symbol string
+	From   time.Time
+	To     time.Time
+}
+
+type CandleBackfillDto struct {
+	Symbol  string
+	From    time.Time
+	To      time.Time
+	Trades  int
+	Candles int
+}
+
+type CandleCommandHandler struct {
+	candleRepository marketdata.ICandleWriteRepository
+	tradeHistory     marketdata.ITradeHistory
+}
+
+func NewCandleCommandHandler(candleRepository marketdata.ICandleWriteRepository, tradeHistory marketdata.ITradeHistory) *CandleCommandHandler {
+	return &CandleCommandHandler{candleRepository: candleRepository, tradeHistory: tradeHistory}
+}
+
+// RecordExecution aggregates the execution into the candles of every interval, a known trade is ignored
+func (cch *CandleCommandHandler) RecordExecution(ctx context.Context, cmd RecordExecutionCommand) error {
+	execution, err := marketdata.NewExecution(cmd.TradeID, cmd.Symbol, cmd.Price, cmd.Quantity, cmd.CreatedAt)
+	if err != nil {
+		return err
+	}
+	merged, err := cch.candleRepository.MergeExecution(ctx, execution, marketdata.NewCandles(execution))
+	if err != nil {
+		return err
+	}
+	if !merged {
+		logrus.WithField("trade", execution.TradeID).Debugln("execution already aggregated")
+	}
+	return nil
+}
+
+// Backfill recomputes the candles from the trades table one day at a time, days still receiving executions should
+// not be backfilled since the candles of a day are replaced once all its trades were read
+func (cch *CandleCommandHandler) Backfill(ctx context.Context, cmd BackfillCandlesCommand) (*CandleBackfillDto, error) {
+	validation := lib.NewErrorNotification()
+	validation.StringNotEmpty("symbol", cmd.Symbol)
+	if !cmd.To.After(cmd.From) {
+		validation.Add("to", errors.New("should be after from"))
+	}
+	if err := validation.Err(); err != nil {
+		return nil, err
+	}
+	day := marketdata.OneDayCandleInterval
+	report := &CandleBackfillDto{
+		Symbol: cmd.Symbol,
+		From:   day.OpenTime(cmd.From),
+		To:     day.OpenTime(cmd.To.Add(-time.Nanosecond)).Add(day.Duration()),
+	}
+	for from := report.From; from.Before(report.To); from = from.Add(day.Duration()) {
+		to := from.Add(day.Duration())
+		trades, candles, err := cch.backfillDay(ctx, cmd.Symbol, from, to)
+		if err != nil {
+			return nil, err
+		}
+		report.Trades += trades
+		report.Candles += candles
+		logrus.WithField("symbol", cmd.Symbol).WithField("day", from.Format(time.DateOnly)).WithField("trades", trades).Infoln("candles backfilled")
+	}
+	return report, nil
+}
+
+func (cch *CandleCommandHandler) backfillDay(ctx context.Context, symbol string, from, to time.Time) (int, int, error) {
+	type candleKey struct {
+		interval marketdata.CandleInterval
+		openTime time.Time
+	}
+	var (
+		candles []*marketdata.Candle
+		byKey   = make(map[candleKey]*marketdata.Candle)
+		afterID uint
+		count   int
+	)
+	for {
+		trades, err := cch.tradeHistory.ListTrades(ctx, symbol, afterID, from, to, backfillBatchSize)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(trades) == 0 {
+			break
+		}
+		executions := make([]*marketdata.Execution, 0, len(trades))
+		for _, trade := range trades {
+			execution, err := marketdata.NewExecution(trade.ID, trade.Symbol, trade.Price, trade.Quantity, trade.CreatedAt)
+			if err != nil {
+				return 0, 0, err
+			}
+			executions = append(executions, execution)
+			for _, candle := range marketdata.NewCandles(execution) {
+				key := candleKey{interval: candle.Interval, openTime: candle.OpenTime}
+				if existing, ok := byKey[key]; ok {
+					existing.Merge(candle)
+					continue
+				}
+				byKey[key] = candle
+				candles = append(candles, candle)
+			}
+			afterID = trade.ID
+		}
+		if err := cch.candleRepository.RecordExecutions(ctx, executions...); err != nil {
+			return 0, 0, err
+		}
+		count += len(trades)
+	}
+	if err := cch.candleRepository.ReplaceCandles(ctx, symbol, from, to, candles); err != nil {
+		return 0, 0, err
+	}
+	return count, len(candles), nil
+}
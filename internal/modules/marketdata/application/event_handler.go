@@ -0,0 +1,63 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/sirupsen/logrus"
+)
+
+// matchEvent is the part of the executions published by the matcher the market data needs
+type matchEvent struct {
+	Symbol    string      `json:"symbol"`
+	TradeID   uint        `json:"tradeID"`
+	Price     lib.Decimal `json:"price"`
+	Quantity  lib.Decimal `json:"quantity"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// MarketDataEventHandler aggregates the executions of the match topic, the consumer group is shared by every instance
+type MarketDataEventHandler struct {
+	matchConsumer  provider.IConsumer
+	commandHandler *CandleCommandHandler
+}
+
+func NewMarketDataEventHandler(matchConsumer provider.IConsumer, commandHandler *CandleCommandHandler) *MarketDataEventHandler {
+	return &MarketDataEventHandler{matchConsumer: matchConsumer, commandHandler: commandHandler}
+}
+
+func (mdh *MarketDataEventHandler) Run(ctx context.Context) error {
+	return mdh.matchConsumer.Consume(ctx, func(message string) error {
+		var me matchEvent
+		if err := json.Unmarshal([]byte(message), &me); err != nil {
+			logrus.WithError(err).Errorln("invalid match event")
+			return nil
+		}
+		err := mdh.commandHandler.RecordExecution(ctx, me.command())
+		var notification *lib.ErrorNotification
+		if errors.As(err, &notification) {
+			// retrying would not make an invalid execution valid
+			logrus.WithError(err).WithField("trade", me.TradeID).Errorln("execution not aggregated")
+			return nil
+		}
+		return err
+	})
+}
+
+func (me matchEvent) command() RecordExecutionCommand {
+	return RecordExecutionCommand{
+		TradeID:   me.TradeID,
+		Symbol:    me.Symbol,
+		Price:     me.Price,
+		Quantity:  me.Quantity,
+		CreatedAt: me.CreatedAt,
+	}
+}
+
+func (mdh *MarketDataEventHandler) GetRepresentation() string {
+	return "MarketDataEventHandler"
+}
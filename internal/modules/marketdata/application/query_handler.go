@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/marketdata"
+)
+
+const (
+	defaultCandleLimit = 500
+	maxCandleLimit     = 1000
+)
+
+// ListCandlesQuery a zero To is now and a zero From is Limit intervals before To
+type ListCandlesQuery struct {
+	Symbol   string
+	Interval string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+type CandleDto struct {
+	OpenTime    time.Time
+	CloseTime   time.Time
+	Open        lib.Decimal
+	High        lib.Decimal
+	Low         lib.Decimal
+	Close       lib.Decimal
+	Volume      lib.Decimal
+	QuoteVolume lib.Decimal
+	Trades      int
+}
+
+type CandleQueryHandler struct {
+	candleRepository marketdata.ICandleReadRepository
+	clock            lib.IClock
+}
+
+func NewCandleQueryHandler(candleRepository marketdata.ICandleReadRepository, clock lib.IClock) *CandleQueryHandler {
+	return &CandleQueryHandler{candleRepository: candleRepository, clock: clock}
+}
+
+func (cqh *CandleQueryHandler) ListCandles(ctx context.Context, query ListCandlesQuery) ([]*CandleDto, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultCandleLimit
+	}
+	validation := lib.NewErrorNotification()
+	validation.StringNotEmpty("symbol", query.Symbol)
+	validation.IntShouldBeBetween("limit", query.Limit, 1, maxCandleLimit)
+	interval, err := marketdata.ParseCandleInterval(query.Interval)
+	if err != nil {
+		validation.Add("interval", err)
+	}
+	if err := validation.Err(); err != nil {
+		return nil, err
+	}
+	if query.To.IsZero() {
+		query.To = cqh.clock.Now()
+	}
+	if query.From.IsZero() {
+		query.From = interval.OpenTime(query.To).Add(-time.Duration(query.Limit-1) * interval.Duration())
+	}
+	if !query.To.After(query.From) {
+		validation.Add("to", errors.New("should be after from"))
+		return nil, validation
+	}
+	candles, err := cqh.candleRepository.ListCandles(ctx, query.Symbol, interval, query.From, query.To, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*CandleDto, 0, len(candles))
+	for _, candle := range candles {
+		dtos = append(dtos, toCandleDto(candle))
+	}
+	return dtos, nil
+}
+
+func toCandleDto(candle *marketdata.Candle) *CandleDto {
+	return &CandleDto{
+		OpenTime:    candle.OpenTime,
+		CloseTime:   candle.OpenTime.Add(candle.Interval.Duration()),
+		Open:        candle.Open,
+		High:        candle.High,
+		Low:         candle.Low,
+		Close:       candle.Close,
+		Volume:      candle.Volume,
+		QuoteVolume: candle.QuoteVolume,
+		Trades:      candle.Trades,
+	}
+}
@@ -0,0 +1,141 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/marketdata"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/sirupsen/logrus"
+)
+
+type tickerEvent struct {
+	Symbol             string      `json:"symbol"`
+	At                 time.Time   `json:"at"`
+	LastPrice          lib.Decimal `json:"lastPrice"`
+	LastTradeAt        time.Time   `json:"lastTradeAt"`
+	Open               lib.Decimal `json:"open"`
+	High               lib.Decimal `json:"high"`
+	Low                lib.Decimal `json:"low"`
+	Volume             lib.Decimal `json:"volume"`
+	QuoteVolume        lib.Decimal `json:"quoteVolume"`
+	VWAP               lib.Decimal `json:"vwap"`
+	PriceChange        lib.Decimal `json:"priceChange"`
+	PriceChangePercent lib.Decimal `json:"priceChangePercent"`
+	Trades             int         `json:"trades"`
+	BestBid            lib.Decimal `json:"bestBid"`
+	BestAsk            lib.Decimal `json:"bestAsk"`
+	BidOrders          int         `json:"bidOrders"`
+	AskOrders          int         `json:"askOrders"`
+}
+
+// TickerEventHandler feeds the tickers of the instance, its consumer should read every partition of the match
+// topic from the latest execution without committing it, and warm up the tickers when its partitions are assigned
+type TickerEventHandler struct {
+	matchConsumer provider.IConsumer
+	tickers       *TickerRegistry
+}
+
+func NewTickerEventHandler(matchConsumer provider.IConsumer, tickers *TickerRegistry) *TickerEventHandler {
+	return &TickerEventHandler{matchConsumer: matchConsumer, tickers: tickers}
+}
+
+func (teh *TickerEventHandler) Run(ctx context.Context) error {
+	return teh.matchConsumer.Consume(ctx, func(message string) error {
+		var me matchEvent
+		if err := json.Unmarshal([]byte(message), &me); err != nil {
+			logrus.WithError(err).Errorln("invalid match event")
+			return nil
+		}
+		if err := teh.tickers.RecordExecution(me.command()); err != nil {
+			logrus.WithError(err).WithField("trade", me.TradeID).Errorln("execution not counted")
+		}
+		return nil
+	})
+}
+
+func (teh *TickerEventHandler) GetRepresentation() string {
+	return "TickerEventHandler"
+}
+
+// TickerPublisher produces the tickers of the symbols the instance matches, keyed by symbol, once per interval
+type TickerPublisher struct {
+	tickers   *TickerRegistry
+	ownership marketdata.ISymbolOwnership
+	producer  provider.IProducer
+	topic     string
+	interval  time.Duration
+}
+
+func NewTickerPublisher(tickers *TickerRegistry, ownership marketdata.ISymbolOwnership, producer provider.IProducer, topic string, interval time.Duration) *TickerPublisher {
+	return &TickerPublisher{
+		tickers:   tickers,
+		ownership: ownership,
+		producer:  producer,
+		topic:     topic,
+		interval:  interval,
+	}
+}
+
+func (tp *TickerPublisher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(tp.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := tp.publish(ctx); err != nil {
+				logrus.WithField("Executor", tp.GetRepresentation()).Errorln(err)
+			}
+		}
+	}
+}
+
+func (tp *TickerPublisher) publish(ctx context.Context) error {
+	tickers, err := tp.tickers.ListTickers(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, dto := range tickers {
+		if !tp.ownership.Owns(dto.Symbol) {
+			continue
+		}
+		bts, err := json.Marshal(toTickerEvent(dto))
+		if err != nil {
+			return err
+		}
+		if err := tp.producer.ProduceWithKey(ctx, tp.topic, dto.Symbol, string(bts)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tp *TickerPublisher) GetRepresentation() string {
+	return "TickerPublisher"
+}
+
+func toTickerEvent(dto *TickerDto) tickerEvent {
+	return tickerEvent{
+		Symbol:             dto.Symbol,
+		At:                 dto.At,
+		LastPrice:          dto.LastPrice,
+		LastTradeAt:        dto.LastTradeAt,
+		Open:               dto.Open,
+		High:               dto.High,
+		Low:                dto.Low,
+		Volume:             dto.Volume,
+		QuoteVolume:        dto.QuoteVolume,
+		VWAP:               dto.VWAP,
+		PriceChange:        dto.PriceChange,
+		PriceChangePercent: dto.PriceChangePercent,
+		Trades:             dto.Trades,
+		BestBid:            dto.BestBid,
+		BestAsk:            dto.BestAsk,
+		BidOrders:          dto.BidOrders,
+		AskOrders:          dto.AskOrders,
+	}
+}
@@ -0,0 +1,181 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/marketdata"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/sirupsen/logrus"
+)
+
+type TickerDto struct {
+	Symbol             string
+	At                 time.Time
+	LastPrice          lib.Decimal
+	LastTradeAt        time.Time
+	Open               lib.Decimal
+	High               lib.Decimal
+	Low                lib.Decimal
+	Volume             lib.Decimal
+	QuoteVolume        lib.Decimal
+	VWAP               lib.Decimal
+	PriceChange        lib.Decimal
+	PriceChangePercent lib.Decimal
+	Trades             int
+	BestBid            lib.Decimal
+	BestAsk            lib.Decimal
+	BidOrders          int
+	AskOrders          int
+}
+
+// TickerRegistry holds the rolling 24h ticker of every symbol traded since the warm up, it is fed with every
+// execution of the match topic. The book side of a ticker is read when the ticker is asked for
+type TickerRegistry struct {
+	lock         sync.Mutex
+	tickers      map[string]*marketdata.RollingTicker
+	tradeHistory marketdata.ITradeHistory
+	books        marketdata.IBookSummaryReader
+	clock        lib.IClock
+}
+
+func NewTickerRegistry(tradeHistory marketdata.ITradeHistory, books marketdata.IBookSummaryReader, clock lib.IClock) *TickerRegistry {
+	return &TickerRegistry{
+		tickers:      make(map[string]*marketdata.RollingTicker),
+		tradeHistory: tradeHistory,
+		books:        books,
+		clock:        clock,
+	}
+}
+
+// WarmUp restarts the tickers from the trades of the window, executions of these trades are ignored afterwards so
+// the match topic may be read from any offset before the warm up
+func (tr *TickerRegistry) WarmUp(ctx context.Context) error {
+	now := tr.clock.Now()
+	from := marketdata.OneMinuteCandleInterval.OpenTime(now.Add(-marketdata.TickerWindow))
+	// trades committed while the warm up pages are counted as well
+	until := now.Add(marketdata.TickerWindow)
+	tickers := make(map[string]*marketdata.RollingTicker)
+	var afterID uint
+	for {
+		trades, err := tr.tradeHistory.ListTrades(ctx, "", afterID, from, until, backfillBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(trades) == 0 {
+			break
+		}
+		for _, trade := range trades {
+			execution, err := marketdata.NewExecution(trade.ID, trade.Symbol, trade.Price, trade.Quantity, trade.CreatedAt)
+			if err != nil {
+				return err
+			}
+			ticker, ok := tickers[execution.Symbol]
+			if !ok {
+				ticker = marketdata.NewRollingTicker(execution.Symbol)
+				tickers[execution.Symbol] = ticker
+			}
+			ticker.WarmUp(execution)
+			afterID = trade.ID
+		}
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.tickers = tickers
+	return nil
+}
+
+// PartitionsAssigned warms up before the consumer resolves the offsets of the assignment, so the executions it
+// reads next overlap the trades of the warm up instead of leaving a gap
+func (tr *TickerRegistry) PartitionsAssigned(ctx context.Context, _ provider.PartitionAssignment) {
+	if err := tr.WarmUp(ctx); err != nil {
+		// the tickers fill up with the executions to come
+		logrus.WithError(err).Errorln("can not warm up the tickers")
+	}
+}
+
+func (tr *TickerRegistry) PartitionsRevoked(context.Context, provider.PartitionAssignment) {}
+
+func (tr *TickerRegistry) RecordExecution(cmd RecordExecutionCommand) error {
+	execution, err := marketdata.NewExecution(cmd.TradeID, cmd.Symbol, cmd.Price, cmd.Quantity, cmd.CreatedAt)
+	if err != nil {
+		return err
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.ticker(execution.Symbol).Apply(execution)
+	return nil
+}
+
+// ListTickers returns the ticker of symbol, every ticker when symbol is empty. Symbols with resting orders and no
+// trade since the warm up have a ticker too
+func (tr *TickerRegistry) ListTickers(ctx context.Context, symbol string) ([]*TickerDto, error) {
+	summaries, err := tr.books.SummarizeBooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := tr.clock.Now()
+	bySymbol := make(map[string]*TickerDto)
+	tr.lock.Lock()
+	for _, ticker := range tr.tickers {
+		if symbol == "" || ticker.Symbol == symbol {
+			bySymbol[ticker.Symbol] = toTickerDto(ticker.Stats(now))
+		}
+	}
+	tr.lock.Unlock()
+	for _, summary := range summaries {
+		if symbol != "" && summary.Symbol != symbol {
+			continue
+		}
+		dto, ok := bySymbol[summary.Symbol]
+		if !ok {
+			dto = &TickerDto{Symbol: summary.Symbol, At: now}
+			bySymbol[summary.Symbol] = dto
+		}
+		if summary.Side == order.BuyOrderSide {
+			dto.BestBid, dto.BidOrders = summary.BestPrice, summary.Orders
+		} else {
+			dto.BestAsk, dto.AskOrders = summary.BestPrice, summary.Orders
+		}
+	}
+	dtos := make([]*TickerDto, 0, len(bySymbol))
+	for _, dto := range bySymbol {
+		dtos = append(dtos, dto)
+	}
+	if symbol != "" && len(dtos) == 0 {
+		return nil, marketdata.TickerNotFound
+	}
+	sort.Slice(dtos, func(i, j int) bool { return dtos[i].Symbol < dtos[j].Symbol })
+	return dtos, nil
+}
+
+func (tr *TickerRegistry) ticker(symbol string) *marketdata.RollingTicker {
+	ticker, ok := tr.tickers[symbol]
+	if !ok {
+		ticker = marketdata.NewRollingTicker(symbol)
+		tr.tickers[symbol] = ticker
+	}
+	return ticker
+}
+
+func toTickerDto(stats marketdata.TickerStats) *TickerDto {
+	return &TickerDto{
+		Symbol:             stats.Symbol,
+		At:                 stats.At,
+		LastPrice:          stats.LastPrice,
+		LastTradeAt:        stats.LastTradeAt,
+		Open:               stats.Open,
+		High:               stats.High,
+		Low:                stats.Low,
+		Volume:             stats.Volume,
+		QuoteVolume:        stats.QuoteVolume,
+		VWAP:               stats.VWAP,
+		PriceChange:        stats.PriceChange,
+		PriceChangePercent: stats.PriceChangePercent,
+		Trades:             stats.Trades,
+	}
+}
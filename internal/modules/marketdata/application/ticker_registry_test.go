@@ -0,0 +1,86 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/marketdata/application"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// tradesTable is the trades table of the master in id order
+type tradesTable []*order.Trade
+
+func (tt *tradesTable) ListTrades(_ context.Context, symbol string, afterID uint, from, to time.Time, limit int) ([]*order.Trade, error) {
+	var trades []*order.Trade
+	for _, trade := range *tt {
+		if (symbol == "" || trade.Symbol == symbol) && trade.ID > afterID && !trade.CreatedAt.Before(from) && trade.CreatedAt.Before(to) && len(trades) < limit {
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+type emptyBooks struct{}
+
+func (emptyBooks) SummarizeBooks(context.Context) ([]*order.BookSideSummary, error) {
+	return nil, nil
+}
+
+type TickerRegistryTestSuite struct {
+	suite.Suite
+	trades  *tradesTable
+	clock   *lib.SimulatedClock
+	tickers *application.TickerRegistry
+}
+
+func TestTickerRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(TickerRegistryTestSuite))
+}
+
+func (suite *TickerRegistryTestSuite) SetupTest() {
+	suite.trades = &tradesTable{}
+	suite.clock = lib.NewSimulatedClock(time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC))
+	suite.tickers = application.NewTickerRegistry(suite.trades, emptyBooks{}, suite.clock)
+}
+
+func (suite *TickerRegistryTestSuite) trade(id uint, symbol string, price int64, ago time.Duration) application.RecordExecutionCommand {
+	trade := &order.Trade{ID: id, Symbol: symbol, Price: lib.NewDecimalFromInt(price), Quantity: lib.NewDecimalFromInt(1), CreatedAt: suite.clock.Now().Add(-ago)}
+	*suite.trades = append(*suite.trades, trade)
+	return application.RecordExecutionCommand{TradeID: trade.ID, Symbol: trade.Symbol, Price: trade.Price, Quantity: trade.Quantity, CreatedAt: trade.CreatedAt}
+}
+
+func (suite *TickerRegistryTestSuite) ticker(symbol string) *application.TickerDto {
+	tickers, err := suite.tickers.ListTickers(context.Background(), symbol)
+	suite.Require().NoError(err)
+	suite.Require().Len(tickers, 1)
+	return tickers[0]
+}
+
+func (suite *TickerRegistryTestSuite) TestTheWarmUpCountsEveryTradeOfTheWindowOnce() {
+	suite.trade(1, "BTC-USD", 90, 25*time.Hour)
+	suite.trade(2, "BTC-USD", 100, time.Hour)
+	suite.trade(3, "ETH-USD", 10, time.Minute)
+	// executed before the warm up, not aggregated into candles yet and read again from the match topic
+	uncounted := suite.trade(4, "BTC-USD", 102, time.Second)
+	suite.tickers.PartitionsAssigned(context.Background(), provider.PartitionAssignment{})
+
+	suite.Require().NoError(suite.tickers.RecordExecution(uncounted))
+	suite.Require().NoError(suite.tickers.RecordExecution(application.RecordExecutionCommand{TradeID: 5, Symbol: "BTC-USD",
+		Price: lib.NewDecimalFromInt(104), Quantity: lib.NewDecimalFromInt(1), CreatedAt: suite.clock.Now()}))
+	btc := suite.ticker("BTC-USD")
+	suite.Equal(3, btc.Trades)
+	suite.Equal(lib.NewDecimalFromInt(100), btc.Open)
+	suite.Equal(lib.NewDecimalFromInt(104), btc.LastPrice)
+	suite.Equal(1, suite.ticker("ETH-USD").Trades)
+
+	// a new assignment starts from the trades again instead of adding them twice
+	suite.trade(5, "BTC-USD", 104, 0)
+	suite.tickers.PartitionsAssigned(context.Background(), provider.PartitionAssignment{})
+	suite.Equal(3, suite.ticker("BTC-USD").Trades)
+}
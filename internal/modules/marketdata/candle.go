@@ -0,0 +1,140 @@
+package marketdata
+
+import (
+	"time"
+	"tradeTornado/internal/lib"
+)
+
+type CandleInterval string
+
+const (
+	OneMinuteCandleInterval   CandleInterval = "1m"
+	FiveMinutesCandleInterval CandleInterval = "5m"
+	OneHourCandleInterval     CandleInterval = "1h"
+	OneDayCandleInterval      CandleInterval = "1d"
+)
+
+// CandleIntervals are the intervals every execution is aggregated into, the longest one comes last
+var CandleIntervals = []CandleInterval{OneMinuteCandleInterval, FiveMinutesCandleInterval, OneHourCandleInterval, OneDayCandleInterval}
+
+func ParseCandleInterval(interval string) (CandleInterval, error) {
+	for _, ci := range CandleIntervals {
+		if string(ci) == interval {
+			return ci, nil
+		}
+	}
+	return "", InvalidCandleInterval
+}
+
+func (ci CandleInterval) Duration() time.Duration {
+	switch ci {
+	case OneMinuteCandleInterval:
+		return time.Minute
+	case FiveMinutesCandleInterval:
+		return 5 * time.Minute
+	case OneHourCandleInterval:
+		return time.Hour
+	case OneDayCandleInterval:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// OpenTime is the start of the candle at belongs to, days start at midnight UTC
+func (ci CandleInterval) OpenTime(at time.Time) time.Time {
+	return at.UTC().Truncate(ci.Duration())
+}
+
+// Execution is a trade seen by the market data, it is kept so a redelivered trade is not aggregated twice
+type Execution struct {
+	TradeID   uint        `gorm:"primarykey;column:trade_id;autoIncrement:false"`
+	Symbol    string      `gorm:"column:symbol"`
+	Price     lib.Decimal `gorm:"column:price"`
+	Quantity  lib.Decimal `gorm:"column:quantity"`
+	CreatedAt time.Time   `gorm:"column:created_at"`
+}
+
+func (Execution) TableName() string {
+	return "marketdata_executions"
+}
+
+func NewExecution(tradeID uint, symbol string, price, quantity lib.Decimal, at time.Time) (*Execution, error) {
+	execution := &Execution{
+		TradeID:   tradeID,
+		Symbol:    symbol,
+		Price:     price,
+		Quantity:  quantity,
+		CreatedAt: at.UTC(),
+	}
+	return execution, execution.validate()
+}
+
+func (e *Execution) validate() error {
+	validation := lib.NewErrorNotification()
+
+	validation.UintShouldBeGT("trade_id", e.TradeID, 0)
+	validation.StringNotEmpty("symbol", e.Symbol)
+	validation.DecimalShouldBePositive("price", e.Price)
+	validation.DecimalShouldBePositive("quantity", e.Quantity)
+
+	return validation.Err()
+}
+
+// Candle is the OHLCV summary of the executions of a symbol in [OpenTime, OpenTime+Interval), the open and close are
+// the prices of the first and last trade ids since trades of a symbol get increasing ids
+type Candle struct {
+	Symbol       string         `gorm:"primarykey;column:symbol"`
+	Interval     CandleInterval `gorm:"primarykey;column:interval"`
+	OpenTime     time.Time      `gorm:"primarykey;column:open_time"`
+	Open         lib.Decimal    `gorm:"column:open"`
+	High         lib.Decimal    `gorm:"column:high"`
+	Low          lib.Decimal    `gorm:"column:low"`
+	Close        lib.Decimal    `gorm:"column:close"`
+	Volume       lib.Decimal    `gorm:"column:volume"`
+	QuoteVolume  lib.Decimal    `gorm:"column:quote_volume"`
+	Trades       int            `gorm:"column:trades"`
+	FirstTradeID uint           `gorm:"column:first_trade_id"`
+	LastTradeID  uint           `gorm:"column:last_trade_id"`
+	UpdatedAt    time.Time
+}
+
+func NewCandle(interval CandleInterval, execution *Execution) *Candle {
+	return &Candle{
+		Symbol:       execution.Symbol,
+		Interval:     interval,
+		OpenTime:     interval.OpenTime(execution.CreatedAt),
+		Open:         execution.Price,
+		High:         execution.Price,
+		Low:          execution.Price,
+		Close:        execution.Price,
+		Volume:       execution.Quantity,
+		QuoteVolume:  execution.Price.Mul(execution.Quantity),
+		Trades:       1,
+		FirstTradeID: execution.TradeID,
+		LastTradeID:  execution.TradeID,
+	}
+}
+
+// NewCandles is the candle of every interval made of the single execution
+func NewCandles(execution *Execution) []*Candle {
+	candles := make([]*Candle, 0, len(CandleIntervals))
+	for _, interval := range CandleIntervals {
+		candles = append(candles, NewCandle(interval, execution))
+	}
+	return candles
+}
+
+// Merge adds the executions summarized by other, both candles should be of the same symbol, interval and open time
+func (c *Candle) Merge(other *Candle) {
+	if other.FirstTradeID < c.FirstTradeID {
+		c.Open, c.FirstTradeID = other.Open, other.FirstTradeID
+	}
+	if other.LastTradeID > c.LastTradeID {
+		c.Close, c.LastTradeID = other.Close, other.LastTradeID
+	}
+	c.High = max(c.High, other.High)
+	c.Low = min(c.Low, other.Low)
+	c.Volume += other.Volume
+	c.QuoteVolume += other.QuoteVolume
+	c.Trades += other.Trades
+}
@@ -0,0 +1,64 @@
+package marketdata
+
+import (
+	"testing"
+	"time"
+	"tradeTornado/internal/lib"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CandleTestSuite struct {
+	suite.Suite
+}
+
+func TestCandleTestSuite(t *testing.T) {
+	suite.Run(t, new(CandleTestSuite))
+}
+
+func (suite *CandleTestSuite) newExecution(tradeID uint, price, quantity int64, at time.Time) *Execution {
+	execution, err := NewExecution(tradeID, "BTC-USD", lib.NewDecimalFromInt(price), lib.NewDecimalFromInt(quantity), at)
+	suite.Require().NoError(err)
+	return execution
+}
+
+func (suite *CandleTestSuite) TestOpenTimeIsAlignedToTheInterval() {
+	at := time.Date(2026, 3, 4, 13, 47, 31, 0, time.FixedZone("CET", 3600))
+	suite.Equal(time.Date(2026, 3, 4, 12, 47, 0, 0, time.UTC), OneMinuteCandleInterval.OpenTime(at))
+	suite.Equal(time.Date(2026, 3, 4, 12, 45, 0, 0, time.UTC), FiveMinutesCandleInterval.OpenTime(at))
+	suite.Equal(time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC), OneHourCandleInterval.OpenTime(at))
+	suite.Equal(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), OneDayCandleInterval.OpenTime(at))
+
+	_, err := ParseCandleInterval("15m")
+	suite.ErrorIs(err, InvalidCandleInterval)
+}
+
+func (suite *CandleTestSuite) TestMergeKeepsOpenAndCloseInTradeOrder() {
+	dec := lib.NewDecimalFromInt
+	at := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	candle := NewCandle(OneHourCandleInterval, suite.newExecution(5, 100, 2, at.Add(time.Minute)))
+	// executions of other partitions arrive out of order
+	candle.Merge(NewCandle(OneHourCandleInterval, suite.newExecution(7, 98, 1, at.Add(3*time.Minute))))
+	candle.Merge(NewCandle(OneHourCandleInterval, suite.newExecution(3, 103, 1, at)))
+	candle.Merge(NewCandle(OneHourCandleInterval, suite.newExecution(6, 101, 4, at.Add(2*time.Minute))))
+
+	suite.Equal(at, candle.OpenTime)
+	suite.Equal(dec(103), candle.Open)
+	suite.Equal(dec(98), candle.Close)
+	suite.Equal(dec(103), candle.High)
+	suite.Equal(dec(98), candle.Low)
+	suite.Equal(dec(8), candle.Volume)
+	suite.Equal(dec(200+98+103+404), candle.QuoteVolume)
+	suite.Equal(4, candle.Trades)
+	suite.Equal(uint(3), candle.FirstTradeID)
+	suite.Equal(uint(7), candle.LastTradeID)
+}
+
+func (suite *CandleTestSuite) TestExecutionIsValidated() {
+	_, err := NewExecution(0, "", 0, lib.NewDecimalFromInt(1), time.Now())
+	var notification *lib.ErrorNotification
+	suite.Require().ErrorAs(err, &notification)
+	suite.Contains(notification.Errs, "trade_id")
+	suite.Contains(notification.Errs, "symbol")
+	suite.Contains(notification.Errs, "price")
+}
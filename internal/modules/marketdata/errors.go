@@ -0,0 +1,16 @@
+package marketdata
+
+import (
+	"errors"
+
+	"tradeTornado/internal/lib"
+)
+
+var (
+	InvalidCandleInterval = lib.NewErrorNotification()
+	TickerNotFound        = lib.NewNotFoundError("ticker")
+)
+
+func init() {
+	InvalidCandleInterval.Add("interval", errors.New("should be one of 1m, 5m, 1h or 1d"))
+}
@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/marketdata/application"
+)
+
+type MarketDataController struct {
+	candleQueryHandler *application.CandleQueryHandler
+	tickers            *application.TickerRegistry
+}
+
+func NewMarketDataController(cqh *application.CandleQueryHandler, tickers *application.TickerRegistry) *MarketDataController {
+	return &MarketDataController{
+		candleQueryHandler: cqh,
+		tickers:            tickers,
+	}
+}
+
+func (mdc *MarketDataController) GetRoutes() []lib.Route {
+	return []lib.Route{
+		mdc.listCandles(),
+		mdc.listTickers(),
+	}
+}
+
+func (mdc *MarketDataController) GetRoot() string {
+	return "marketdata"
+}
+
+func (mdc *MarketDataController) GetMiddlewares() []lib.Middleware {
+	return nil
+}
+
+// listCandlesRequest From and To are RFC 3339 times, zero when missing
+type listCandlesRequest struct {
+	Symbol   string    `query:"symbol"`
+	Interval string    `query:"interval" default:"1m"`
+	From     time.Time `query:"from"`
+	To       time.Time `query:"to"`
+	Limit    int       `query:"limit"`
+}
+
+type listCandlesResponse struct {
+	Symbol   string                   `json:"symbol"`
+	Interval string                   `json:"interval"`
+	Candles  []*application.CandleDto `json:"candles"`
+}
+
+func (mdc *MarketDataController) listCandles() lib.Route {
+	return lib.Handle(http.MethodGet, "candles", func(ctx context.Context, req listCandlesRequest) (*listCandlesResponse, error) {
+		query := application.ListCandlesQuery{
+			Symbol:   req.Symbol,
+			Interval: req.Interval,
+			From:     req.From,
+			To:       req.To,
+			Limit:    req.Limit,
+		}
+		candles, err := mdc.candleQueryHandler.ListCandles(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return &listCandlesResponse{Symbol: query.Symbol, Interval: query.Interval, Candles: candles}, nil
+	})
+}
+
+type listTickersRequest struct {
+	Symbol string `query:"symbol"`
+}
+
+type listTickersResponse struct {
+	Tickers []*application.TickerDto `json:"tickers"`
+}
+
+func (mdc *MarketDataController) listTickers() lib.Route {
+	return lib.Handle(http.MethodGet, "ticker", func(ctx context.Context, req listTickersRequest) (*listTickersResponse, error) {
+		tickers, err := mdc.tickers.ListTickers(ctx, req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		return &listTickersResponse{Tickers: tickers}, nil
+	})
+}
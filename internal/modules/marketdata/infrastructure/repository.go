@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"context"
+	"time"
+
+	"tradeTornado/internal/modules/marketdata"
+	"tradeTornado/internal/service/provider"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const executionBatchSize = 500
+
+// mergeCandle folds the excluded row into the stored candle, the row is locked by the upsert so concurrent
+// consumers of the match topic merge one after the other
+var mergeCandle = clause.OnConflict{
+	Columns: []clause.Column{{Name: "symbol"}, {Name: "interval"}, {Name: "open_time"}},
+	DoUpdates: clause.Assignments(map[string]interface{}{
+		"open":           gorm.Expr("CASE WHEN excluded.first_trade_id < candles.first_trade_id THEN excluded.open ELSE candles.open END"),
+		"close":          gorm.Expr("CASE WHEN excluded.last_trade_id > candles.last_trade_id THEN excluded.close ELSE candles.close END"),
+		"high":           gorm.Expr("GREATEST(candles.high, excluded.high)"),
+		"low":            gorm.Expr("LEAST(candles.low, excluded.low)"),
+		"volume":         gorm.Expr("candles.volume + excluded.volume"),
+		"quote_volume":   gorm.Expr("candles.quote_volume + excluded.quote_volume"),
+		"trades":         gorm.Expr("candles.trades + excluded.trades"),
+		"first_trade_id": gorm.Expr("LEAST(candles.first_trade_id, excluded.first_trade_id)"),
+		"last_trade_id":  gorm.Expr("GREATEST(candles.last_trade_id, excluded.last_trade_id)"),
+		"updated_at":     gorm.Expr("excluded.updated_at"),
+	}),
+}
+
+type CandleRepository struct {
+	session *provider.GormSession
+}
+
+func NewCandleRepository(session *provider.GormSession) *CandleRepository {
+	return &CandleRepository{
+		session: session,
+	}
+}
+
+func (c *CandleRepository) MergeExecution(ctx context.Context, execution *marketdata.Execution, candles []*marketdata.Candle) (bool, error) {
+	merged := false
+	err := c.session.RunTx(ctx, func() error {
+		result := c.session.Gorm().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(execution)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		merged = true
+		return c.session.Gorm().WithContext(ctx).Clauses(mergeCandle).Create(candles).Error
+	})
+	return merged, err
+}
+
+func (c *CandleRepository) RecordExecutions(ctx context.Context, executions ...*marketdata.Execution) error {
+	if len(executions) == 0 {
+		return nil
+	}
+	return c.session.Gorm().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(executions, executionBatchSize).Error
+}
+
+func (c *CandleRepository) ReplaceCandles(ctx context.Context, symbol string, from, to time.Time, candles []*marketdata.Candle) error {
+	return c.session.RunTx(ctx, func() error {
+		if err := c.session.Gorm().WithContext(ctx).
+			Where("symbol = ? and open_time >= ? and open_time < ?", symbol, from, to).
+			Delete(&marketdata.Candle{}).Error; err != nil {
+			return err
+		}
+		if len(candles) == 0 {
+			return nil
+		}
+		return c.session.Gorm().WithContext(ctx).CreateInBatches(candles, executionBatchSize).Error
+	})
+}
+
+func (c *CandleRepository) ListCandles(ctx context.Context, symbol string, interval marketdata.CandleInterval, from, to time.Time, limit int) ([]*marketdata.Candle, error) {
+	var candles []*marketdata.Candle
+	if err := c.session.Gorm().WithContext(ctx).
+		Where(`symbol = ? and "interval" = ? and open_time >= ? and open_time < ?`, symbol, interval, from, to).
+		Order("open_time ASC").
+		Limit(limit).
+		Find(&candles).Error; err != nil {
+		return nil, err
+	}
+	return candles, nil
+}
+
+func (c *CandleRepository) Migrate(ctx context.Context) error {
+	return c.session.Gorm().WithContext(ctx).AutoMigrate(&marketdata.Execution{}, &marketdata.Candle{})
+}
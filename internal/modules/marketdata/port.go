@@ -0,0 +1,40 @@
+package marketdata
+
+import (
+	"context"
+	"time"
+	"tradeTornado/internal/modules/order"
+)
+
+type ICandleWriteRepository interface {
+	// MergeExecution records the execution and merges its candles into the stored ones, false when the execution was
+	// already recorded and nothing changed
+	MergeExecution(ctx context.Context, execution *Execution, candles []*Candle) (bool, error)
+	// RecordExecutions records the executions that are not recorded yet
+	RecordExecutions(ctx context.Context, executions ...*Execution) error
+	// ReplaceCandles replaces the candles of symbol opened in [from, to) with candles
+	ReplaceCandles(ctx context.Context, symbol string, from, to time.Time, candles []*Candle) error
+	ICandleReadRepository
+}
+
+type ICandleReadRepository interface {
+	// ListCandles returns up to limit candles opened in [from, to), oldest first
+	ListCandles(ctx context.Context, symbol string, interval CandleInterval, from, to time.Time, limit int) ([]*Candle, error)
+}
+
+// ITradeHistory reads the trades the matcher persisted
+type ITradeHistory interface {
+	// ListTrades returns up to limit trades of symbol created in [from, to) with an id greater than afterID, in id
+	// order. An empty symbol lists the trades of every symbol
+	ListTrades(ctx context.Context, symbol string, afterID uint, from, to time.Time, limit int) ([]*order.Trade, error)
+}
+
+// IBookSummaryReader summarizes the resting orders of every book
+type IBookSummaryReader interface {
+	SummarizeBooks(ctx context.Context) ([]*order.BookSideSummary, error)
+}
+
+// ISymbolOwnership tells whether this instance matches the symbol
+type ISymbolOwnership interface {
+	Owns(symbol string) bool
+}
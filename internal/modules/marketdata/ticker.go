@@ -0,0 +1,104 @@
+package marketdata
+
+import (
+	"time"
+	"tradeTornado/internal/lib"
+)
+
+const TickerWindow = 24 * time.Hour
+
+// RollingTicker keeps the one minute candles of the last 24 hours of a symbol, the window moves a minute at a time
+type RollingTicker struct {
+	Symbol      string
+	candles     map[time.Time]*Candle
+	lastPrice   lib.Decimal
+	lastTradeID uint
+	lastTradeAt time.Time
+	// warmedUpTo is the last trade id of the trades the ticker started from, older executions are already counted
+	warmedUpTo uint
+}
+
+// TickerStats are the statistics of the window ending at At, the last trade may be older than the window
+type TickerStats struct {
+	Symbol             string
+	At                 time.Time
+	LastPrice          lib.Decimal
+	LastTradeAt        time.Time
+	Open               lib.Decimal
+	High               lib.Decimal
+	Low                lib.Decimal
+	Volume             lib.Decimal
+	QuoteVolume        lib.Decimal
+	VWAP               lib.Decimal
+	PriceChange        lib.Decimal
+	PriceChangePercent lib.Decimal
+	Trades             int
+}
+
+func NewRollingTicker(symbol string) *RollingTicker {
+	return &RollingTicker{Symbol: symbol, candles: make(map[time.Time]*Candle)}
+}
+
+// WarmUp merges a stored trade, executions up to it are ignored afterwards
+func (rt *RollingTicker) WarmUp(execution *Execution) {
+	rt.merge(NewCandle(OneMinuteCandleInterval, execution), execution.CreatedAt)
+	rt.warmedUpTo = max(rt.warmedUpTo, execution.TradeID)
+}
+
+// Apply merges the execution, false when it was already counted by the warm up
+func (rt *RollingTicker) Apply(execution *Execution) bool {
+	if execution.TradeID <= rt.warmedUpTo {
+		return false
+	}
+	rt.merge(NewCandle(OneMinuteCandleInterval, execution), execution.CreatedAt)
+	return true
+}
+
+// Stats evicts the candles that left the window ending at now and summarizes the rest
+func (rt *RollingTicker) Stats(now time.Time) TickerStats {
+	stats := TickerStats{Symbol: rt.Symbol, At: now, LastPrice: rt.lastPrice, LastTradeAt: rt.lastTradeAt}
+	start := OneMinuteCandleInterval.OpenTime(now.Add(-TickerWindow)).Add(time.Minute)
+	var first *Candle
+	for openTime, candle := range rt.candles {
+		if openTime.Before(start) {
+			delete(rt.candles, openTime)
+			continue
+		}
+		if openTime.After(now) {
+			continue
+		}
+		if first == nil || candle.FirstTradeID < first.FirstTradeID {
+			first = candle
+		}
+		if stats.Trades == 0 || candle.High > stats.High {
+			stats.High = candle.High
+		}
+		if stats.Trades == 0 || candle.Low < stats.Low {
+			stats.Low = candle.Low
+		}
+		stats.Volume += candle.Volume
+		stats.QuoteVolume += candle.QuoteVolume
+		stats.Trades += candle.Trades
+	}
+	if first == nil {
+		return stats
+	}
+	stats.Open = first.Open
+	stats.VWAP = stats.QuoteVolume.Div(stats.Volume)
+	stats.PriceChange = stats.LastPrice - stats.Open
+	stats.PriceChangePercent = stats.PriceChange.MulInt(100).Div(stats.Open)
+	return stats
+}
+
+func (rt *RollingTicker) merge(candle *Candle, at time.Time) {
+	if existing, ok := rt.candles[candle.OpenTime]; ok {
+		existing.Merge(candle)
+	} else {
+		copied := *candle
+		rt.candles[candle.OpenTime] = &copied
+	}
+	if candle.LastTradeID > rt.lastTradeID {
+		rt.lastPrice, rt.lastTradeID = candle.Close, candle.LastTradeID
+		rt.lastTradeAt = at
+	}
+}
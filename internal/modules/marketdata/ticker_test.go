@@ -0,0 +1,67 @@
+package marketdata
+
+import (
+	"testing"
+	"time"
+	"tradeTornado/internal/lib"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TickerTestSuite struct {
+	suite.Suite
+	start time.Time
+}
+
+func TestTickerTestSuite(t *testing.T) {
+	suite.Run(t, new(TickerTestSuite))
+}
+
+func (suite *TickerTestSuite) SetupTest() {
+	suite.start = time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *TickerTestSuite) execution(tradeID uint, price, quantity int64, after time.Duration) *Execution {
+	execution, err := NewExecution(tradeID, "BTC-USD", lib.NewDecimalFromInt(price), lib.NewDecimalFromInt(quantity), suite.start.Add(after))
+	suite.Require().NoError(err)
+	return execution
+}
+
+func (suite *TickerTestSuite) TestStatsCoverTheLastDay() {
+	dec := lib.NewDecimalFromInt
+	ticker := NewRollingTicker("BTC-USD")
+	suite.True(ticker.Apply(suite.execution(1, 80, 1, 0)))
+	suite.True(ticker.Apply(suite.execution(2, 100, 2, 2*time.Hour)))
+	suite.True(ticker.Apply(suite.execution(3, 120, 1, 3*time.Hour)))
+	suite.True(ticker.Apply(suite.execution(4, 110, 1, 25*time.Hour+30*time.Second)))
+
+	stats := ticker.Stats(suite.start.Add(25*time.Hour + time.Minute))
+	suite.Equal(dec(110), stats.LastPrice)
+	suite.Equal(suite.start.Add(25*time.Hour+30*time.Second), stats.LastTradeAt)
+	suite.Equal(dec(100), stats.Open)
+	suite.Equal(dec(120), stats.High)
+	suite.Equal(dec(100), stats.Low)
+	suite.Equal(dec(4), stats.Volume)
+	suite.Equal(dec(200+120+110), stats.QuoteVolume)
+	suite.Equal(lib.MustParseDecimal("107.5"), stats.VWAP)
+	suite.Equal(dec(10), stats.PriceChange)
+	suite.Equal(dec(10), stats.PriceChangePercent)
+	suite.Equal(3, stats.Trades)
+
+	stats = ticker.Stats(suite.start.Add(50 * time.Hour))
+	suite.Equal(0, stats.Trades)
+	suite.Equal(dec(110), stats.LastPrice)
+	suite.Equal(lib.Decimal(0), stats.PriceChangePercent)
+}
+
+func (suite *TickerTestSuite) TestWarmUpSkipsCountedExecutions() {
+	ticker := NewRollingTicker("BTC-USD")
+	ticker.WarmUp(suite.execution(5, 100, 1, 0))
+	ticker.WarmUp(suite.execution(6, 101, 1, time.Second))
+
+	suite.False(ticker.Apply(suite.execution(6, 101, 1, time.Second)))
+	suite.True(ticker.Apply(suite.execution(7, 102, 1, 2*time.Second)))
+	stats := ticker.Stats(suite.start.Add(time.Minute))
+	suite.Equal(3, stats.Trades)
+	suite.Equal(lib.NewDecimalFromInt(102), stats.LastPrice)
+}
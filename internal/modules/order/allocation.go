@@ -0,0 +1,151 @@
+package order
+
+import (
+	"errors"
+
+	"tradeTornado/internal/lib"
+)
+
+// AllocationAlgorithm decides how an incoming order is split over the resting orders of one price level
+type AllocationAlgorithm string
+
+const (
+	FIFOAllocationAlgorithm            AllocationAlgorithm = "fifo"
+	ProRataAllocationAlgorithm         AllocationAlgorithm = "pro_rata"
+	TopOrderProRataAllocationAlgorithm AllocationAlgorithm = "top_order_pro_rata"
+)
+
+func (aa AllocationAlgorithm) validate() error {
+	switch aa {
+	case FIFOAllocationAlgorithm, ProRataAllocationAlgorithm, TopOrderProRataAllocationAlgorithm:
+		return nil
+	}
+	validation := lib.NewErrorNotification()
+	validation.Add("allocation_algorithm", errors.New("invalid allocation algorithm"))
+	return validation.Err()
+}
+
+// ParseAllocationAlgorithm an empty algorithm is FIFO
+func ParseAllocationAlgorithm(algorithm string) (AllocationAlgorithm, error) {
+	if algorithm == "" {
+		return FIFOAllocationAlgorithm, nil
+	}
+	aa := AllocationAlgorithm(algorithm)
+	return aa, aa.validate()
+}
+
+// Allocation is the part of the incoming order executed against one maker
+type Allocation struct {
+	Maker    *Order
+	Quantity lib.Decimal
+}
+
+// IAllocationStrategy splits quantity over the makers of one price level, level is in time priority and the
+// allocations never exceed the visible remaining of a maker
+type IAllocationStrategy interface {
+	Allocate(quantity lib.Decimal, level []*Order) []Allocation
+}
+
+// AllocationRules are the allocation settings of an instrument, pro-rata shares are rounded down to LotSize and
+// shares below MinAllocation are dropped before the leftover is allocated in time priority
+type AllocationRules struct {
+	Algorithm     AllocationAlgorithm
+	MinAllocation lib.Decimal
+	LotSize       lib.Decimal
+}
+
+func DefaultAllocationRules() AllocationRules {
+	return AllocationRules{Algorithm: FIFOAllocationAlgorithm, LotSize: DefaultTradingRules().LotSize}
+}
+
+func (ar AllocationRules) Strategy() IAllocationStrategy {
+	proRata := ProRataAllocation{MinAllocation: ar.MinAllocation, RoundingUnit: ar.LotSize}
+	switch ar.Algorithm {
+	case ProRataAllocationAlgorithm:
+		return proRata
+	case TopOrderProRataAllocationAlgorithm:
+		return TopOrderProRataAllocation{ProRataAllocation: proRata}
+	}
+	return FIFOAllocation{}
+}
+
+// FIFOAllocation fills the makers one after the other in time priority
+type FIFOAllocation struct{}
+
+func (FIFOAllocation) Allocate(quantity lib.Decimal, level []*Order) []Allocation {
+	var allocations []Allocation
+	for _, maker := range level {
+		if quantity <= 0 {
+			break
+		}
+		fill := min(quantity, maker.Remaining)
+		if fill <= 0 {
+			continue
+		}
+		allocations = append(allocations, Allocation{Maker: maker, Quantity: fill})
+		quantity -= fill
+	}
+	return allocations
+}
+
+// ProRataAllocation shares quantity in proportion to the remaining of each maker, the quantity lost to rounding
+// and to the minimum allocation is given back in time priority so the level is always fully used
+type ProRataAllocation struct {
+	MinAllocation lib.Decimal
+	RoundingUnit  lib.Decimal
+}
+
+func (pra ProRataAllocation) Allocate(quantity lib.Decimal, level []*Order) []Allocation {
+	var total lib.Decimal
+	for _, maker := range level {
+		total += maker.Remaining
+	}
+	if quantity <= 0 || total <= 0 {
+		return nil
+	}
+	if quantity >= total {
+		return FIFOAllocation{}.Allocate(quantity, level)
+	}
+	fills := make([]lib.Decimal, len(level))
+	leftover := quantity
+	for i, maker := range level {
+		share := quantity.Mul(maker.Remaining).Div(total)
+		if pra.RoundingUnit > 0 {
+			share -= share % pra.RoundingUnit
+		}
+		if share < pra.MinAllocation {
+			share = 0
+		}
+		fills[i] = share
+		leftover -= share
+	}
+	for i, maker := range level {
+		if leftover <= 0 {
+			break
+		}
+		extra := min(leftover, maker.Remaining-fills[i])
+		fills[i] += extra
+		leftover -= extra
+	}
+	var allocations []Allocation
+	for i, maker := range level {
+		if fills[i] > 0 {
+			allocations = append(allocations, Allocation{Maker: maker, Quantity: fills[i]})
+		}
+	}
+	return allocations
+}
+
+// TopOrderProRataAllocation fills the oldest maker of the level first and shares the rest pro-rata
+type TopOrderProRataAllocation struct {
+	ProRataAllocation
+}
+
+func (tpa TopOrderProRataAllocation) Allocate(quantity lib.Decimal, level []*Order) []Allocation {
+	if quantity <= 0 || len(level) == 0 {
+		return nil
+	}
+	top := min(quantity, level[0].Remaining)
+	allocations := []Allocation{{Maker: level[0], Quantity: top}}
+	return append(allocations, tpa.ProRataAllocation.Allocate(quantity-top, level[1:])...)
+}
@@ -0,0 +1,84 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AllocationTestSuite struct {
+	suite.Suite
+	nextID uint
+}
+
+func TestAllocationTestSuite(t *testing.T) {
+	suite.Run(t, new(AllocationTestSuite))
+}
+
+func (suite *AllocationTestSuite) SetupTest() {
+	suite.nextID = 0
+}
+
+func (suite *AllocationTestSuite) level(quantities ...int64) []*Order {
+	level := make([]*Order, 0, len(quantities))
+	for _, quantity := range quantities {
+		suite.nextID++
+		om, err := NewOrder(suite.nextID, "account", "BTC-USD", "sell", "limit", dec(100), 0, dec(quantity), time.Unix(int64(suite.nextID), 0))
+		suite.Require().NoError(err)
+		level = append(level, om)
+	}
+	return level
+}
+
+func (suite *AllocationTestSuite) quantities(allocations []Allocation) map[uint]lib.Decimal {
+	quantities := make(map[uint]lib.Decimal)
+	for _, allocation := range allocations {
+		quantities[allocation.Maker.ID] = allocation.Quantity
+	}
+	return quantities
+}
+
+func (suite *AllocationTestSuite) TestFIFO() {
+	level := suite.level(5, 5, 5)
+	allocations := AllocationRules{Algorithm: FIFOAllocationAlgorithm}.Strategy().Allocate(dec(7), level)
+	suite.Equal(map[uint]lib.Decimal{1: dec(5), 2: dec(2)}, suite.quantities(allocations))
+}
+
+func (suite *AllocationTestSuite) TestProRata() {
+	level := suite.level(10, 30, 60)
+	strategy := AllocationRules{Algorithm: ProRataAllocationAlgorithm, LotSize: dec(1)}.Strategy()
+	suite.Equal(map[uint]lib.Decimal{1: dec(5), 2: dec(15), 3: dec(30)}, suite.quantities(strategy.Allocate(dec(50), level)))
+	suite.Equal(map[uint]lib.Decimal{1: dec(10), 2: dec(30), 3: dec(60)}, suite.quantities(strategy.Allocate(dec(500), level)))
+}
+
+func (suite *AllocationTestSuite) TestProRataLeftoverGoesInTimePriority() {
+	level := suite.level(10, 30, 60)
+	strategy := AllocationRules{Algorithm: ProRataAllocationAlgorithm, MinAllocation: dec(2), LotSize: dec(1)}.Strategy()
+	// shares 0.7, 2.1 and 4.2 round down to 0, 2 and 4, the first order is below the minimum and gets the leftover
+	suite.Equal(map[uint]lib.Decimal{1: dec(1), 2: dec(2), 3: dec(4)}, suite.quantities(strategy.Allocate(dec(7), level)))
+}
+
+func (suite *AllocationTestSuite) TestProRataRoundsToFractionalLots() {
+	level := suite.level(1, 2)
+	strategy := AllocationRules{Algorithm: ProRataAllocationAlgorithm, LotSize: lib.MustParseDecimal("0.1")}.Strategy()
+	allocations := strategy.Allocate(dec(1), level)
+	suite.Equal(map[uint]lib.Decimal{1: lib.MustParseDecimal("0.4"), 2: lib.MustParseDecimal("0.6")}, suite.quantities(allocations))
+}
+
+func (suite *AllocationTestSuite) TestTopOrderThenProRata() {
+	level := suite.level(10, 30, 60)
+	strategy := AllocationRules{Algorithm: TopOrderProRataAllocationAlgorithm, LotSize: dec(1)}.Strategy()
+	suite.Equal(map[uint]lib.Decimal{1: dec(10), 2: dec(10), 3: dec(20)}, suite.quantities(strategy.Allocate(dec(40), level)))
+	suite.Equal(map[uint]lib.Decimal{1: dec(4)}, suite.quantities(strategy.Allocate(dec(4), level)))
+}
+
+func (suite *AllocationTestSuite) TestUnknownAlgorithmIsRejected() {
+	_, err := ParseAllocationAlgorithm("lottery")
+	suite.ErrorIs(err, lib.NewErrorNotification())
+	algorithm, err := ParseAllocationAlgorithm("")
+	suite.NoError(err)
+	suite.Equal(FIFOAllocationAlgorithm, algorithm)
+}
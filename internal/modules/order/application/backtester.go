@@ -0,0 +1,408 @@
+package application
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+)
+
+type BacktestInputFormat string
+
+const (
+	// EventsBacktestInputFormat is one order event of the create topic per line, with an optional createdAt
+	EventsBacktestInputFormat BacktestInputFormat = "events"
+	// JournalBacktestInputFormat is one journal entry per line, only the commands are run
+	JournalBacktestInputFormat BacktestInputFormat = "journal"
+)
+
+func ParseBacktestInputFormat(format string) (BacktestInputFormat, error) {
+	switch BacktestInputFormat(format) {
+	case EventsBacktestInputFormat, JournalBacktestInputFormat:
+		return BacktestInputFormat(format), nil
+	}
+	return "", fmt.Errorf("unknown backtest input format %q", format)
+}
+
+// BacktestSettings is the reference data shared by every symbol of a backtest, Step moves the clock between events
+// without a time. Zero SnapshotInterval only snapshots the books at the end
+type BacktestSettings struct {
+	TradingRules     order.TradingRules
+	Allocation       order.AllocationRules
+	MakerFeeBps      int
+	TakerFeeBps      int
+	STPMode          order.STPMode
+	FeeAccountID     string
+	Start            time.Time
+	Step             time.Duration
+	SnapshotInterval time.Duration
+	DepthLevels      int
+}
+
+// BacktestReferenceData answers the lookups of the matcher from the settings, no limit is enforced and the
+// volatility band is disabled
+type BacktestReferenceData struct {
+	settings BacktestSettings
+}
+
+func NewBacktestReferenceData(settings BacktestSettings) *BacktestReferenceData {
+	return &BacktestReferenceData{settings: settings}
+}
+
+func (brd *BacktestReferenceData) GetFeeRates(context.Context, string) (int, int, error) {
+	return brd.settings.MakerFeeBps, brd.settings.TakerFeeBps, nil
+}
+
+func (brd *BacktestReferenceData) GetTradingRules(context.Context, string) (order.TradingRules, error) {
+	return brd.settings.TradingRules, nil
+}
+
+func (brd *BacktestReferenceData) GetRiskLimits(context.Context, string) (order.RiskLimits, error) {
+	return order.RiskLimits{}, nil
+}
+
+func (brd *BacktestReferenceData) GetSTPMode(context.Context, string) (order.STPMode, error) {
+	return brd.settings.STPMode, nil
+}
+
+func (brd *BacktestReferenceData) GetVolatilityBand(context.Context, string) (order.VolatilityBand, error) {
+	return order.VolatilityBand{}, nil
+}
+
+func (brd *BacktestReferenceData) GetAllocationRules(context.Context, string) (order.AllocationRules, error) {
+	return brd.settings.Allocation, nil
+}
+
+type BacktestTradeDto struct {
+	TradeID        uint
+	Symbol         string
+	TakerOrderID   uint
+	MakerOrderID   uint
+	TakerAccountID string
+	MakerAccountID string
+	TakerSide      order.OrderSide
+	Price          lib.Decimal
+	Quantity       lib.Decimal
+	TakerFee       lib.Decimal
+	MakerFee       lib.Decimal
+	CreatedAt      time.Time
+}
+
+type BacktestSnapshotDto struct {
+	At time.Time
+	DepthDto
+}
+
+type BacktestSymbolStatsDto struct {
+	Orders   int
+	Trades   int
+	Volume   lib.Decimal
+	Notional lib.Decimal
+	Open     lib.Decimal
+	High     lib.Decimal
+	Low      lib.Decimal
+	Last     lib.Decimal
+	VWAP     lib.Decimal
+	Fees     lib.Decimal
+}
+
+// BacktestPositionDto is the position of an account in a symbol, PnL marks the position at the last price net of fees
+type BacktestPositionDto struct {
+	Trades   int
+	Position lib.Decimal
+	Cash     lib.Decimal
+	Fees     lib.Decimal
+	PnL      lib.Decimal
+}
+
+type BacktestSummaryDto struct {
+	From                 time.Time
+	To                   time.Time
+	Commands             int
+	Orders               int
+	Trades               int
+	SelfTradePreventions int
+	Rejected             map[order.RejectReason]int
+	Symbols              map[string]*BacktestSymbolStatsDto
+	// Accounts are keyed by account and then by symbol
+	Accounts map[string]map[string]*BacktestPositionDto
+}
+
+// IBacktestOutput receives the results of a backtest while it runs
+type IBacktestOutput interface {
+	WriteTrade(trade *BacktestTradeDto) error
+	WriteSnapshot(snapshot *BacktestSnapshotDto) error
+}
+
+type backtestOrder struct {
+	AccountID string
+	Side      order.OrderSide
+}
+
+// Backtester runs historical inputs through an offline matcher on a simulated clock, the matcher should use
+// repository, sessions and clock
+type Backtester struct {
+	repository   order.IReplayRepository
+	matcher      *OrderMatcher
+	sessions     *JournaledSessions
+	clock        *lib.SimulatedClock
+	output       IBacktestOutput
+	settings     BacktestSettings
+	orders       map[uint]backtestOrder
+	summary      *BacktestSummaryDto
+	nextSnapshot time.Time
+}
+
+func NewBacktester(repository order.IReplayRepository, matcher *OrderMatcher, sessions *JournaledSessions, clock *lib.SimulatedClock, output IBacktestOutput, settings BacktestSettings) *Backtester {
+	return &Backtester{
+		repository: repository,
+		matcher:    matcher,
+		sessions:   sessions,
+		clock:      clock,
+		output:     output,
+		settings:   settings,
+		orders:     make(map[uint]backtestOrder),
+		summary: &BacktestSummaryDto{
+			Rejected: make(map[order.RejectReason]int),
+			Symbols:  make(map[string]*BacktestSymbolStatsDto),
+			Accounts: make(map[string]map[string]*BacktestPositionDto),
+		},
+	}
+}
+
+// Run reads every line of input, the books are snapshotted once more after the last one
+func (b *Backtester) Run(ctx context.Context, input io.Reader, format BacktestInputFormat) (*BacktestSummaryDto, error) {
+	reader := bufio.NewReader(input)
+	at := b.settings.Start
+	for line := 1; ; line++ {
+		bts, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(bts)) > 0 {
+			command, decodeErr := b.decode(bts, format, at)
+			if decodeErr != nil {
+				return nil, fmt.Errorf("line %d: %w", line, decodeErr)
+			}
+			if command != nil {
+				if err := b.apply(ctx, command); err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				at = command.CreatedAt.Add(b.settings.Step)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	if err := b.snapshot(ctx, b.summary.To); err != nil {
+		return nil, err
+	}
+	b.finish()
+	return b.summary, nil
+}
+
+// decode turns a line into a command, at is the time of a line without one. Journal events are skipped
+func (b *Backtester) decode(line []byte, format BacktestInputFormat, at time.Time) (*order.JournalEntry, error) {
+	if format == JournalBacktestInputFormat {
+		var entry order.JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, err
+		}
+		if entry.Kind != order.CommandJournalEntryKind {
+			return nil, nil
+		}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = at
+		}
+		return &entry, nil
+	}
+	var event struct {
+		orderCreateEvent
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	if err := json.Unmarshal(line, &event); err != nil {
+		return nil, err
+	}
+	if !event.CreatedAt.IsZero() {
+		at = event.CreatedAt
+	}
+	return order.NewJournalCommand(order.CreateOrderJournalEntryType, event.Symbol, createOrderJournal{SubmitOrderCommand: event.command()}, at)
+}
+
+func (b *Backtester) apply(ctx context.Context, command *order.JournalEntry) error {
+	if b.summary.From.IsZero() {
+		b.summary.From = command.CreatedAt
+		if b.settings.SnapshotInterval > 0 {
+			b.nextSnapshot = command.CreatedAt.Truncate(b.settings.SnapshotInterval).Add(b.settings.SnapshotInterval)
+		}
+	}
+	for b.settings.SnapshotInterval > 0 && !command.CreatedAt.Before(b.nextSnapshot) {
+		if err := b.snapshot(ctx, b.nextSnapshot); err != nil {
+			return err
+		}
+		b.nextSnapshot = b.nextSnapshot.Add(b.settings.SnapshotInterval)
+	}
+	b.clock.Set(command.CreatedAt)
+	if err := executeJournalCommand(ctx, b.matcher, b.sessions, command); err != nil {
+		return err
+	}
+	b.summary.To = command.CreatedAt
+	for _, journaled := range b.repository.TakeJournal() {
+		if err := b.record(journaled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Backtester) record(journaled *order.JournaledCommand) error {
+	b.summary.Commands++
+	stats := b.symbolStats(journaled.Command.Symbol)
+	if journaled.Command.Type == order.CreateOrderJournalEntryType {
+		var create createOrderJournal
+		if err := json.Unmarshal([]byte(journaled.Command.Payload), &create); err != nil {
+			return err
+		}
+		b.orders[create.OrderID] = backtestOrder{AccountID: create.AccountID, Side: order.OrderSide(create.Side)}
+		b.summary.Orders++
+		stats.Orders++
+	}
+	for _, event := range journaled.Events {
+		switch event.Type {
+		case order.OrderMatchedJournalEntryType:
+			var matched orderMatchEvent
+			if err := json.Unmarshal([]byte(event.Payload), &matched); err != nil {
+				return err
+			}
+			if err := b.recordTrade(matched); err != nil {
+				return err
+			}
+		case order.OrderRejectedJournalEntryType:
+			var rejected orderRejectEvent
+			if err := json.Unmarshal([]byte(event.Payload), &rejected); err != nil {
+				return err
+			}
+			b.summary.Rejected[rejected.Reason]++
+		case order.SelfTradePreventedJournalEntryType:
+			b.summary.SelfTradePreventions++
+		}
+	}
+	return nil
+}
+
+func (b *Backtester) recordTrade(matched orderMatchEvent) error {
+	taker, maker := b.orders[matched.OrderID], b.orders[matched.MatchedOrderID]
+	trade := &BacktestTradeDto{
+		TradeID:        matched.TradeID,
+		Symbol:         matched.Symbol,
+		TakerOrderID:   matched.OrderID,
+		MakerOrderID:   matched.MatchedOrderID,
+		TakerAccountID: taker.AccountID,
+		MakerAccountID: maker.AccountID,
+		TakerSide:      taker.Side,
+		Price:          matched.Price,
+		Quantity:       matched.Quantity,
+		TakerFee:       matched.TakerFee,
+		MakerFee:       matched.MakerFee,
+		CreatedAt:      matched.CreatedAt,
+	}
+	notional := trade.Price.Mul(trade.Quantity)
+	stats := b.symbolStats(trade.Symbol)
+	if stats.Trades == 0 {
+		stats.Open, stats.High, stats.Low = trade.Price, trade.Price, trade.Price
+	}
+	stats.Trades++
+	stats.Volume += trade.Quantity
+	stats.Notional += notional
+	stats.High = max(stats.High, trade.Price)
+	stats.Low = min(stats.Low, trade.Price)
+	stats.Last = trade.Price
+	stats.Fees += trade.TakerFee + trade.MakerFee
+	b.summary.Trades++
+
+	buyer, seller := b.position(trade.TakerAccountID, trade.Symbol), b.position(trade.MakerAccountID, trade.Symbol)
+	if trade.TakerSide == order.SellOrderSide {
+		buyer, seller = seller, buyer
+	}
+	buyer.Position += trade.Quantity
+	buyer.Cash -= notional
+	seller.Position -= trade.Quantity
+	seller.Cash += notional
+	takerPosition, makerPosition := b.position(trade.TakerAccountID, trade.Symbol), b.position(trade.MakerAccountID, trade.Symbol)
+	takerPosition.Trades++
+	takerPosition.Fees += trade.TakerFee
+	makerPosition.Trades++
+	makerPosition.Fees += trade.MakerFee
+	return b.output.WriteTrade(trade)
+}
+
+// snapshot writes the depth of every symbol seen so far, in symbol order
+func (b *Backtester) snapshot(ctx context.Context, at time.Time) error {
+	symbols := make([]string, 0, len(b.summary.Symbols))
+	for symbol := range b.summary.Symbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		bids, err := b.repository.Depth(ctx, symbol, order.BuyOrderSide, b.settings.DepthLevels)
+		if err != nil {
+			return err
+		}
+		asks, err := b.repository.Depth(ctx, symbol, order.SellOrderSide, b.settings.DepthLevels)
+		if err != nil {
+			return err
+		}
+		snapshot := &BacktestSnapshotDto{At: at, DepthDto: DepthDto{Symbol: symbol, Bids: toPriceLevelDtos(bids...), Asks: toPriceLevelDtos(asks...)}}
+		if err := b.output.WriteSnapshot(snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Backtester) finish() {
+	for _, stats := range b.summary.Symbols {
+		if stats.Volume > 0 {
+			stats.VWAP = stats.Notional.Div(stats.Volume)
+		}
+	}
+	for _, positions := range b.summary.Accounts {
+		for symbol, position := range positions {
+			position.PnL = position.Cash + position.Position.Mul(b.summary.Symbols[symbol].Last) - position.Fees
+		}
+	}
+}
+
+func (b *Backtester) symbolStats(symbol string) *BacktestSymbolStatsDto {
+	stats, ok := b.summary.Symbols[symbol]
+	if !ok {
+		stats = &BacktestSymbolStatsDto{}
+		b.summary.Symbols[symbol] = stats
+	}
+	return stats
+}
+
+func (b *Backtester) position(accountID, symbol string) *BacktestPositionDto {
+	positions, ok := b.summary.Accounts[accountID]
+	if !ok {
+		positions = make(map[string]*BacktestPositionDto)
+		b.summary.Accounts[accountID] = positions
+	}
+	position, ok := positions[symbol]
+	if !ok {
+		position = &BacktestPositionDto{}
+		positions[symbol] = position
+	}
+	return position
+}
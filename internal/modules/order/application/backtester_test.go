@@ -0,0 +1,132 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/modules/order/infrastructure"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// backtestOutput keeps what a backtest writes in memory
+type backtestOutput struct {
+	trades    []*application.BacktestTradeDto
+	snapshots []*application.BacktestSnapshotDto
+}
+
+func (bo *backtestOutput) WriteTrade(trade *application.BacktestTradeDto) error {
+	bo.trades = append(bo.trades, trade)
+	return nil
+}
+
+func (bo *backtestOutput) WriteSnapshot(snapshot *application.BacktestSnapshotDto) error {
+	bo.snapshots = append(bo.snapshots, snapshot)
+	return nil
+}
+
+const backtestEvents = `{"orderID":1,"accountID":"mm","symbol":"BTC-USD","side":"sell","price":101,"quantity":10,"createdAt":"2026-01-02T09:00:00Z"}
+{"orderID":2,"accountID":"mm","symbol":"BTC-USD","side":"buy","price":99,"quantity":10,"createdAt":"2026-01-02T09:00:30Z"}
+
+{"orderID":3,"accountID":"quant","symbol":"BTC-USD","side":"buy","price":101,"quantity":4,"createdAt":"2026-01-02T09:01:10Z"}
+{"orderID":4,"accountID":"quant","symbol":"BTC-USD","side":"sell","type":"market","quantity":6,"createdAt":"2026-01-02T09:03:10Z"}
+`
+
+type BacktesterTestSuite struct {
+	suite.Suite
+	settings application.BacktestSettings
+	output   *backtestOutput
+}
+
+func TestBacktesterTestSuite(t *testing.T) {
+	suite.Run(t, new(BacktesterTestSuite))
+}
+
+func (suite *BacktesterTestSuite) SetupTest() {
+	suite.output = &backtestOutput{}
+	suite.settings = application.BacktestSettings{
+		TradingRules:     order.DefaultTradingRules(),
+		Allocation:       order.DefaultAllocationRules(),
+		TakerFeeBps:      10,
+		FeeAccountID:     "fees",
+		Start:            time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC),
+		Step:             time.Millisecond,
+		SnapshotInterval: time.Minute,
+		DepthLevels:      5,
+	}
+}
+
+func (suite *BacktesterTestSuite) run(input string, format application.BacktestInputFormat) *application.BacktestSummaryDto {
+	repository := infrastructure.NewMemoryOrderRepository()
+	sessions := application.NewJournaledSessions(order.ContinuousTradingPhase)
+	clock := lib.NewSimulatedClock(suite.settings.Start)
+	reference := application.NewBacktestReferenceData(suite.settings)
+	matcher := application.NewOrderMatcher(provider.DiscardProducer{}, application.OrderEventTopics{},
+		func() order.OrderTransaction {
+			return order.OrderTransaction{Orders: repository, CircuitBreaker: sessions}
+		},
+		reference, suite.settings.FeeAccountID, reference, reference, order.DefaultRiskChain(), reference,
+		sessions, reference, reference, application.DetachedBooks{}, clock)
+	summary, err := application.NewBacktester(repository, matcher, sessions, clock, suite.output, suite.settings).
+		Run(context.Background(), strings.NewReader(input), format)
+	suite.Require().NoError(err)
+	return summary
+}
+
+func (suite *BacktesterTestSuite) TestRunWritesTradesSnapshotsAndSummary() {
+	dec := lib.NewDecimalFromInt
+	summary := suite.run(backtestEvents, application.EventsBacktestInputFormat)
+
+	suite.Require().Len(suite.output.trades, 2)
+	suite.Equal(dec(101), suite.output.trades[0].Price)
+	suite.Equal(dec(4), suite.output.trades[0].Quantity)
+	suite.Equal(time.Date(2026, 1, 2, 9, 1, 10, 0, time.UTC), suite.output.trades[0].CreatedAt)
+	suite.Equal(dec(99), suite.output.trades[1].Price)
+
+	// one snapshot per elapsed minute and the final one
+	suite.Require().Len(suite.output.snapshots, 4)
+	suite.Equal(time.Date(2026, 1, 2, 9, 1, 0, 0, time.UTC), suite.output.snapshots[0].At)
+	last := suite.output.snapshots[3]
+	suite.Require().Len(last.Bids, 1)
+	suite.Equal(dec(4), last.Bids[0].Quantity)
+	suite.Require().Len(last.Asks, 1)
+	suite.Equal(dec(6), last.Asks[0].Quantity)
+
+	suite.Equal(4, summary.Orders)
+	suite.Equal(2, summary.Trades)
+	stats := summary.Symbols["BTC-USD"]
+	suite.Equal(dec(10), stats.Volume)
+	suite.Equal(lib.MustParseDecimal("99.8"), stats.VWAP)
+	suite.Equal(dec(2), summary.Accounts["mm"]["BTC-USD"].Position)
+	suite.Equal(dec(-2), summary.Accounts["quant"]["BTC-USD"].Position)
+}
+
+func (suite *BacktesterTestSuite) TestRunReadsAJournal() {
+	dec := lib.NewDecimalFromInt
+	repository, _, clock, matcher := newOfflineMatcher(referenceData{})
+	clock.Set(suite.settings.Start)
+	suite.Require().NoError(matcher.Submit(context.Background(), application.SubmitOrderCommand{OrderID: 1, AccountID: "mm", Symbol: "BTC-USD", Side: "sell", Price: dec(101), Quantity: dec(10)}))
+	clock.Set(suite.settings.Start.Add(time.Second))
+	suite.Require().NoError(matcher.Submit(context.Background(), application.SubmitOrderCommand{OrderID: 2, AccountID: "quant", Symbol: "BTC-USD", Side: "buy", Price: dec(101), Quantity: dec(3)}))
+	var lines []string
+	for _, journaled := range repository.TakeJournal() {
+		for _, entry := range append([]*order.JournalEntry{journaled.Command}, journaled.Events...) {
+			line, err := json.Marshal(entry)
+			suite.Require().NoError(err)
+			lines = append(lines, string(line))
+		}
+	}
+
+	summary := suite.run(strings.Join(lines, "\n"), application.JournalBacktestInputFormat)
+	suite.Equal(2, summary.Commands)
+	suite.Require().Len(suite.output.trades, 1)
+	suite.Equal(dec(3), suite.output.trades[0].Quantity)
+	suite.Equal(suite.settings.Start.Add(time.Second), suite.output.trades[0].CreatedAt)
+}
@@ -0,0 +1,199 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/sirupsen/logrus"
+)
+
+type BookSummaryDto struct {
+	Symbol  string
+	Orders  int
+	BestBid lib.Decimal
+	BestAsk lib.Decimal
+}
+
+// BookRegistry holds the books of the symbols this matcher instance owns, a symbol is owned when its partition of
+// the order topic is assigned to the instance. Books are loaded when partitions are assigned and dropped when they
+// are revoked. A standby follows every symbol instead, it reloads the books the journal of the leader touched. Admin commands are served by
+// whichever instance the request reached, a non owner asks the owner to load the book again through the order topic
+type BookRegistry struct {
+	orderRepository order.IOrderReadRepository
+	producer        provider.IProducer
+	orderTopic      string
+	lock            sync.RWMutex
+	partitions      map[int32]bool
+	partitionCount  int
+	following       bool
+	books           map[string]*order.Book
+}
+
+// NewBookRegistry orderRepository should read from the master so a new owner never starts from a lagging replica,
+// orderTopic is the topic the owners consume their commands from
+func NewBookRegistry(orderRepository order.IOrderReadRepository, producer provider.IProducer, orderTopic string) *BookRegistry {
+	return &BookRegistry{
+		orderRepository: orderRepository,
+		producer:        producer,
+		orderTopic:      orderTopic,
+		partitions:      make(map[int32]bool),
+		books:           make(map[string]*order.Book),
+	}
+}
+
+// PartitionsAssigned reloads the owned books even when they are already loaded, a followed book may miss the last
+// commands of the journal
+func (br *BookRegistry) PartitionsAssigned(ctx context.Context, assignment provider.PartitionAssignment) {
+	br.lock.Lock()
+	defer br.lock.Unlock()
+	br.partitionCount = assignment.PartitionCount
+	for _, partition := range assignment.Partitions {
+		br.partitions[partition] = true
+	}
+	br.loadOwned(ctx)
+}
+
+func (br *BookRegistry) PartitionsRevoked(ctx context.Context, assignment provider.PartitionAssignment) {
+	br.lock.Lock()
+	defer br.lock.Unlock()
+	for _, partition := range assignment.Partitions {
+		delete(br.partitions, partition)
+	}
+	br.unloadForeign()
+}
+
+// Follow loads the book of every symbol, used while the instance is a standby
+func (br *BookRegistry) Follow(ctx context.Context) {
+	br.lock.Lock()
+	defer br.lock.Unlock()
+	br.following = true
+	br.loadOwned(ctx)
+}
+
+// Unfollow keeps only the books of the assigned partitions
+func (br *BookRegistry) Unfollow() {
+	br.lock.Lock()
+	defer br.lock.Unlock()
+	br.following = false
+	br.unloadForeign()
+}
+
+// Apply updates the book of symbol, the book is created on the first order of an owned symbol. Orders committed
+// by an instance that is not assigned the partition of symbol come from an admin command, the owner reloads its book
+func (br *BookRegistry) Apply(ctx context.Context, symbol string, orders ...*order.Order) {
+	br.lock.Lock()
+	defer br.lock.Unlock()
+	br.reloadAssigned(ctx, symbol)
+	book, loaded := br.books[symbol]
+	if !loaded {
+		if !br.owns(symbol) {
+			return
+		}
+		book = order.NewBook(symbol, nil)
+		br.books[symbol] = book
+	}
+	book.Apply(orders...)
+}
+
+func (br *BookRegistry) Reset(ctx context.Context, symbol string) {
+	br.lock.Lock()
+	defer br.lock.Unlock()
+	br.reloadAssigned(ctx, symbol)
+	if _, loaded := br.books[symbol]; loaded {
+		br.books[symbol] = order.NewBook(symbol, nil)
+	}
+}
+
+// Reload loads the book of symbol again from the database, asked by the instance that served an admin command
+func (br *BookRegistry) Reload(ctx context.Context, symbol string) {
+	br.lock.Lock()
+	defer br.lock.Unlock()
+	if br.owns(symbol) {
+		br.load(ctx, symbol)
+	}
+}
+
+// Owns reports whether the instance matches symbol, a standby owns every symbol while it follows
+func (br *BookRegistry) Owns(symbol string) bool {
+	br.lock.RLock()
+	defer br.lock.RUnlock()
+	return br.owns(symbol)
+}
+
+func (br *BookRegistry) Summaries() []*BookSummaryDto {
+	br.lock.RLock()
+	defer br.lock.RUnlock()
+	summaries := make([]*BookSummaryDto, 0, len(br.books))
+	for _, book := range br.books {
+		summaries = append(summaries, &BookSummaryDto{
+			Symbol:  book.Symbol,
+			Orders:  book.Len(),
+			BestBid: book.BestPrice(order.BuyOrderSide),
+			BestAsk: book.BestPrice(order.SellOrderSide),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Symbol < summaries[j].Symbol })
+	return summaries
+}
+
+func (br *BookRegistry) loadOwned(ctx context.Context) {
+	symbols, err := br.orderRepository.RestingSymbols(ctx)
+	if err != nil {
+		// books are created empty by the first committed order instead
+		logrus.WithError(err).Errorln("can not load the owned books")
+		return
+	}
+	for _, symbol := range symbols {
+		if br.owns(symbol) {
+			br.load(ctx, symbol)
+		}
+	}
+}
+
+func (br *BookRegistry) load(ctx context.Context, symbol string) {
+	resting, err := br.orderRepository.Resting(ctx, symbol)
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Errorln("can not load the book")
+		return
+	}
+	br.books[symbol] = order.NewBook(symbol, resting)
+	logrus.WithField("symbol", symbol).WithField("orders", len(resting)).Infoln("book loaded")
+}
+
+// reloadAssigned asks the instance assigned the partition of symbol to reload its book, the message is keyed by
+// symbol so the owner reads it after the commands it was already given
+func (br *BookRegistry) reloadAssigned(ctx context.Context, symbol string) {
+	if br.assigned(symbol) {
+		return
+	}
+	message, err := json.Marshal(bookReloadEvent{Command: reloadBookCommand, Symbol: symbol})
+	if err == nil {
+		err = br.producer.ProduceWithKey(ctx, br.orderTopic, symbol, string(message))
+	}
+	if err != nil {
+		// the owner keeps the book it had until its partitions are assigned again
+		logrus.WithError(err).WithField("symbol", symbol).Errorln("can not ask the owner to reload the book")
+	}
+}
+
+func (br *BookRegistry) unloadForeign() {
+	for symbol := range br.books {
+		if !br.owns(symbol) {
+			delete(br.books, symbol)
+			logrus.WithField("symbol", symbol).Infoln("book unloaded")
+		}
+	}
+}
+
+func (br *BookRegistry) owns(symbol string) bool {
+	return br.following || br.assigned(symbol)
+}
+
+func (br *BookRegistry) assigned(symbol string) bool {
+	return br.partitions[lib.PartitionForKey(symbol, br.partitionCount)]
+}
@@ -0,0 +1,175 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// restingOrders is the database the books are loaded from
+type restingOrders struct {
+	order.IOrderReadRepository
+	lock  sync.Mutex
+	books map[string][]*order.Order
+}
+
+func (ro *restingOrders) Resting(_ context.Context, symbol string) ([]*order.Order, error) {
+	ro.lock.Lock()
+	defer ro.lock.Unlock()
+	return ro.books[symbol], nil
+}
+
+func (ro *restingOrders) RestingSymbols(context.Context) ([]string, error) {
+	ro.lock.Lock()
+	defer ro.lock.Unlock()
+	var symbols []string
+	for symbol := range ro.books {
+		symbols = append(symbols, symbol)
+	}
+	return symbols, nil
+}
+
+type producedMessage struct {
+	topic, key, message string
+}
+
+type recordingProducer struct {
+	produced []producedMessage
+}
+
+func (rp *recordingProducer) Produce(ctx context.Context, topic, message string) error {
+	return rp.ProduceWithKey(ctx, topic, "", message)
+}
+
+func (rp *recordingProducer) ProduceWithKey(_ context.Context, topic, key, message string) error {
+	rp.produced = append(rp.produced, producedMessage{topic: topic, key: key, message: message})
+	return nil
+}
+
+type BookRegistryTestSuite struct {
+	suite.Suite
+	database *restingOrders
+	producer *recordingProducer
+	books    *application.BookRegistry
+}
+
+func TestBookRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(BookRegistryTestSuite))
+}
+
+func (suite *BookRegistryTestSuite) SetupTest() {
+	suite.database = &restingOrders{books: map[string][]*order.Order{}}
+	suite.producer = &recordingProducer{}
+	suite.books = application.NewBookRegistry(suite.database, suite.producer, "orders")
+}
+
+func (suite *BookRegistryTestSuite) rest(id uint, symbol string) *order.Order {
+	om, err := order.NewOrder(id, "account", symbol, "buy", "limit", lib.NewDecimalFromInt(100), lib.NewDecimalFromInt(0), lib.NewDecimalFromInt(1), time.Unix(int64(id), 0))
+	suite.Require().NoError(err)
+	suite.database.lock.Lock()
+	defer suite.database.lock.Unlock()
+	suite.database.books[symbol] = append(suite.database.books[symbol], om)
+	return om
+}
+
+func (suite *BookRegistryTestSuite) orders(symbol string) int {
+	for _, summary := range suite.books.Summaries() {
+		if summary.Symbol == symbol {
+			return summary.Orders
+		}
+	}
+	return 0
+}
+
+func (suite *BookRegistryTestSuite) TestAdminCommandsOfANonOwnerReloadTheOwnerBook() {
+	suite.books.Reset(context.Background(), "BTC-USD")
+	suite.books.Apply(context.Background(), "BTC-USD", suite.rest(1, "BTC-USD"))
+	suite.Empty(suite.books.Summaries())
+	suite.Require().Len(suite.producer.produced, 2)
+	suite.Equal("orders", suite.producer.produced[0].topic)
+	suite.Equal("BTC-USD", suite.producer.produced[0].key)
+	suite.JSONEq(`{"command":"reload_book","symbol":"BTC-USD"}`, suite.producer.produced[0].message)
+
+	owner := application.NewBookRegistry(suite.database, suite.producer, "orders")
+	owner.PartitionsAssigned(context.Background(), provider.PartitionAssignment{Partitions: []int32{0}, PartitionCount: 1})
+	suite.rest(2, "BTC-USD")
+	owner.Reload(context.Background(), "BTC-USD")
+	suite.Require().Len(owner.Summaries(), 1)
+	suite.Equal(2, owner.Summaries()[0].Orders)
+	suite.Len(suite.producer.produced, 2)
+}
+
+func (suite *BookRegistryTestSuite) TestAStandbyStillAsksTheOwnerToReload() {
+	suite.rest(1, "BTC-USD")
+	suite.books.Follow(context.Background())
+	suite.Equal(1, suite.orders("BTC-USD"))
+
+	suite.books.Reset(context.Background(), "BTC-USD")
+	suite.Equal(0, suite.orders("BTC-USD"))
+	suite.Require().Len(suite.producer.produced, 1)
+	var reload map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.producer.produced[0].message), &reload))
+	suite.Equal("BTC-USD", reload["symbol"])
+}
+
+// tailedJournal is a journal the leader keeps appending to
+type tailedJournal struct {
+	lock    sync.Mutex
+	journal journalSlice
+}
+
+func (tj *tailedJournal) append(symbol string) {
+	tj.lock.Lock()
+	defer tj.lock.Unlock()
+	command := &order.JournalEntry{Sequence: uint64(len(tj.journal) + 1), Kind: order.CommandJournalEntryKind, Symbol: symbol}
+	tj.journal = append(tj.journal, &order.JournaledCommand{Command: command})
+}
+
+func (tj *tailedJournal) ReadJournal(ctx context.Context, after, until uint64, limit int) ([]*order.JournaledCommand, error) {
+	tj.lock.Lock()
+	defer tj.lock.Unlock()
+	return tj.journal.ReadJournal(ctx, after, until, limit)
+}
+
+func (tj *tailedJournal) LastJournalSequence(ctx context.Context) (uint64, error) {
+	tj.lock.Lock()
+	defer tj.lock.Unlock()
+	return tj.journal.LastJournalSequence(ctx)
+}
+
+func (suite *BookRegistryTestSuite) TestAStandbyFollowsOrdersThatNeverTraded() {
+	journal := &tailedJournal{}
+	suite.rest(1, "BTC-USD")
+	journal.append("BTC-USD")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- application.NewJournalFollower(journal, suite.books, time.Millisecond).Run(ctx)
+	}()
+	suite.Eventually(func() bool { return suite.orders("BTC-USD") == 1 }, time.Second, time.Millisecond)
+
+	suite.rest(2, "BTC-USD")
+	suite.rest(3, "ETH-USD")
+	journal.append("BTC-USD")
+	journal.append("ETH-USD")
+	suite.Eventually(func() bool { return suite.orders("BTC-USD") == 2 && suite.orders("ETH-USD") == 1 }, time.Second, time.Millisecond)
+
+	suite.database.lock.Lock()
+	suite.database.books["BTC-USD"] = nil
+	suite.database.lock.Unlock()
+	journal.append("BTC-USD")
+	suite.Eventually(func() bool { return suite.orders("BTC-USD") == 0 }, time.Second, time.Millisecond)
+
+	cancel()
+	suite.NoError(<-done)
+	suite.Empty(suite.books.Summaries())
+}
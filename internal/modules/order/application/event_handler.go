@@ -3,103 +3,184 @@ package application
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"sync"
 	"time"
+	"tradeTornado/internal/lib"
 	"tradeTornado/internal/modules/order"
 	"tradeTornado/internal/service/provider"
 
 	"github.com/sirupsen/logrus"
 )
 
+type OrderEventTopics struct {
+	Matched            string
+	Rejected           string
+	SelfTradePrevented string
+}
+
 type OrderEventHandler struct {
 	createOrderConsumer provider.IConsumer
-	matchOrderProducer  provider.IProducer
-	matchOrderTopic     string
-	orderRepositoryGen  func() order.IOrderWriteRepository
+	matcher             *OrderMatcher
+	books               *BookRegistry
 	processingOrders    sync.Map
 }
 
+const (
+	createOrderCommand = "create"
+	cancelOrderCommand = "cancel"
+	amendOrderCommand  = "amend"
+	reloadBookCommand  = "reload_book"
+)
+
+// orderCommandHeader is read first from every message of the order topic, an empty Command creates an order
+type orderCommandHeader struct {
+	Command string `json:"command"`
+	OrderID uint   `json:"orderID"`
+	Symbol  string `json:"symbol"`
+}
+
 type orderCreateEvent struct {
-	OrderID  uint   `json:"orderID"`
-	Price    int    `json:"price"`
-	Quantity int    `json:"quantity"`
-	Side     string `json:"side"`
+	OrderID         uint        `json:"orderID"`
+	AccountID       string      `json:"accountID"`
+	Symbol          string      `json:"symbol"`
+	Type            string      `json:"type"`
+	Price           lib.Decimal `json:"price"`
+	TriggerPrice    lib.Decimal `json:"triggerPrice"`
+	Quantity        lib.Decimal `json:"quantity"`
+	DisplayQuantity lib.Decimal `json:"displayQuantity"`
+	// ExecInstructions is the bitset of order.ExecInstruction flags
+	ExecInstructions uint32 `json:"execInstructions"`
+	Side             string `json:"side"`
+	STPMode          string `json:"stpMode"`
+}
+
+type orderCancelEvent struct {
+	OrderID uint   `json:"orderID"`
+	Symbol  string `json:"symbol"`
+}
+
+// orderAmendEvent Quantity is what the order can still trade once amended
+type orderAmendEvent struct {
+	OrderID  uint        `json:"orderID"`
+	Symbol   string      `json:"symbol"`
+	Price    lib.Decimal `json:"price"`
+	Quantity lib.Decimal `json:"quantity"`
+}
+
+// bookReloadEvent is produced by an instance that served an admin command of a symbol it does not own
+type bookReloadEvent struct {
+	Command string `json:"command"`
+	Symbol  string `json:"symbol"`
+}
+
+type orderRejectEvent struct {
+	OrderID   uint               `json:"orderID"`
+	AccountID string             `json:"accountID"`
+	Symbol    string             `json:"symbol"`
+	Reason    order.RejectReason `json:"reason"`
+	Message   string             `json:"message"`
+	CreatedAt time.Time          `json:"createdAt"`
 }
 
 type orderMatchEvent struct {
-	OrderID        uint      `json:"orderID"`
-	MatchedOrderID uint      `json:"matchedOrderID"`
-	CreatedAt      time.Time `json:"createdAt"`
+	Symbol         string      `json:"symbol"`
+	OrderID        uint        `json:"orderID"`
+	MatchedOrderID uint        `json:"matchedOrderID"`
+	TradeID        uint        `json:"tradeID"`
+	Price          lib.Decimal `json:"price"`
+	Quantity       lib.Decimal `json:"quantity"`
+	TakerFee       lib.Decimal `json:"takerFee"`
+	MakerFee       lib.Decimal `json:"makerFee"`
+	FeeAccountID   string      `json:"feeAccountID"`
+	CreatedAt      time.Time   `json:"createdAt"`
+}
+
+type selfTradePreventedEvent struct {
+	Symbol              string        `json:"symbol"`
+	AccountID           string        `json:"accountID"`
+	Mode                order.STPMode `json:"mode"`
+	OrderID             uint          `json:"orderID"`
+	MatchedOrderID      uint          `json:"matchedOrderID"`
+	CancelledOrderIDs   []uint        `json:"cancelledOrderIDs"`
+	DecrementedQuantity lib.Decimal   `json:"decrementedQuantity"`
+	CreatedAt           time.Time     `json:"createdAt"`
 }
 
-func NewOrderEventHandler(createOrderConsumer provider.IConsumer, matchOrderProducer provider.IProducer, mot string, orderRepositoryGet func() order.IOrderWriteRepository) *OrderEventHandler {
-	return &OrderEventHandler{createOrderConsumer: createOrderConsumer, matchOrderProducer: matchOrderProducer, matchOrderTopic: mot, orderRepositoryGen: orderRepositoryGet}
+func NewOrderEventHandler(createOrderConsumer provider.IConsumer, matcher *OrderMatcher, books *BookRegistry) *OrderEventHandler {
+	return &OrderEventHandler{
+		createOrderConsumer: createOrderConsumer,
+		matcher:             matcher,
+		books:               books,
+	}
 }
 
 func (o *OrderEventHandler) Run(ctx context.Context) error {
 	fmt.Println("### --> running")
 	return o.createOrderConsumer.Consume(ctx, func(message string) error {
-		var oe orderCreateEvent
-		err := json.Unmarshal([]byte(message), &oe)
-		if err != nil {
+		var header orderCommandHeader
+		if err := json.Unmarshal([]byte(message), &header); err != nil {
 			return err
 		}
-		_, loaded := o.processingOrders.LoadOrStore(oe.OrderID, oe.OrderID)
+		if header.Command == "" {
+			header.Command = createOrderCommand
+		}
+		_, loaded := o.processingOrders.LoadOrStore(header, header.OrderID)
 		if loaded {
 			logrus.Warningln(order.OrderAlreadyProcessingFound)
 			return nil
 		}
-		defer o.processingOrders.Delete(oe.OrderID)
-		om, err := order.NewOrder(oe.OrderID, oe.Side, oe.Price, oe.Quantity)
-		if err != nil {
-			logrus.Errorln(err)
-			// Invalid orders are erased from queue
-			return nil
-		}
-		orderRepo := o.orderRepositoryGen()
-		err = orderRepo.CreateWithHook(ctx, om, func(ctx context.Context, createdOrder *order.Order) error {
-			if err := o.matchOrder(ctx, orderRepo, createdOrder); err != nil {
-				if !errors.Is(err, order.NoOrderMatched) {
-					return err
-				}
-			}
-			return nil
-		})
-		if err != nil {
-			if errors.Is(err, order.OrderAlreadyCreated) {
-				logrus.Warningln(order.OrderAlreadyCreated)
-				return nil
-			} else {
-				return err
-			}
-		}
-		return nil
+		defer o.processingOrders.Delete(header)
+		return o.handle(ctx, header.Command, []byte(message))
 	})
 }
 
-func (o *OrderEventHandler) matchOrder(ctx context.Context, orderRepo order.IOrderWriteRepository, createdOrder *order.Order) error {
-	// TODO: database may become bottleneck, use cache or eventual solutions (Inbox pattern forexample) based on load
-	return orderRepo.SelectForUpdate(ctx, createdOrder.Side.GetMatchSide(), int(createdOrder.Price), int(createdOrder.Quantity), func(ctx context.Context, matchedOrder *order.Order) error {
-		createdOrder.Match()
-		err := orderRepo.Save(ctx, createdOrder)
-		if err != nil {
+func (o *OrderEventHandler) handle(ctx context.Context, command string, message []byte) error {
+	switch command {
+	case createOrderCommand:
+		var oe orderCreateEvent
+		if err := json.Unmarshal(message, &oe); err != nil {
 			return err
 		}
-		matchedOrder.Match()
-		err = orderRepo.Save(ctx, matchedOrder)
-		if err != nil {
+		return o.matcher.Submit(ctx, oe.command())
+	case cancelOrderCommand:
+		var ce orderCancelEvent
+		if err := json.Unmarshal(message, &ce); err != nil {
 			return err
 		}
-		matchEvent := orderMatchEvent{OrderID: createdOrder.ID, MatchedOrderID: matchedOrder.ID, CreatedAt: time.Now()}
-		bts, err := json.Marshal(matchEvent)
-		if err != nil {
+		return o.matcher.Cancel(ctx, CancelOrderCommand{OrderID: ce.OrderID, Symbol: ce.Symbol})
+	case amendOrderCommand:
+		var ae orderAmendEvent
+		if err := json.Unmarshal(message, &ae); err != nil {
 			return err
 		}
-		fmt.Println("--------------- MATCH EVENT:", string(bts))
-		return o.matchOrderProducer.Produce(ctx, o.matchOrderTopic, string(bts))
-	})
+		return o.matcher.Amend(ctx, AmendOrderCommand{OrderID: ae.OrderID, Symbol: ae.Symbol, Price: ae.Price, Quantity: ae.Quantity})
+	case reloadBookCommand:
+		var re bookReloadEvent
+		if err := json.Unmarshal(message, &re); err != nil {
+			return err
+		}
+		o.books.Reload(ctx, re.Symbol)
+		return nil
+	}
+	logrus.WithField("command", command).Errorln("unknown order command")
+	return nil
+}
+
+func (oe orderCreateEvent) command() SubmitOrderCommand {
+	return SubmitOrderCommand{
+		OrderID:          oe.OrderID,
+		AccountID:        oe.AccountID,
+		Symbol:           oe.Symbol,
+		Type:             oe.Type,
+		Side:             oe.Side,
+		Price:            oe.Price,
+		TriggerPrice:     oe.TriggerPrice,
+		Quantity:         oe.Quantity,
+		DisplayQuantity:  oe.DisplayQuantity,
+		ExecInstructions: order.ExecInstruction(oe.ExecInstructions),
+		STPMode:          order.STPMode(oe.STPMode),
+	}
 }
 
 func (o *OrderEventHandler) GetRepresentation() string {
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"time"
+	"tradeTornado/internal/modules/order"
+
+	"github.com/sirupsen/logrus"
+)
+
+const journalFollowBatchSize = 500
+
+// JournalFollower keeps the books of a standby warm by tailing the journal of the leader. A command is journaled in
+// the transaction of the orders it wrote, the book of every symbol a new command touched is loaded again from the
+// master so resting orders, cancels and amendments are followed as well as executions. A command committed after a
+// later sequence was read is only seen with the next command of its symbol, a standby taking over loads its books
+// again anyway
+type JournalFollower struct {
+	journal  order.IJournalReader
+	books    *BookRegistry
+	interval time.Duration
+}
+
+func NewJournalFollower(journal order.IJournalReader, books *BookRegistry, interval time.Duration) *JournalFollower {
+	return &JournalFollower{journal: journal, books: books, interval: interval}
+}
+
+func (jf *JournalFollower) Run(ctx context.Context) error {
+	// the books are loaded after the tail is read so no command in between is missed
+	after, err := jf.journal.LastJournalSequence(ctx)
+	if err != nil {
+		return err
+	}
+	jf.books.Follow(ctx)
+	defer jf.books.Unfollow()
+	ticker := time.NewTicker(jf.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if after, err = jf.follow(ctx, after); err != nil {
+				logrus.WithField("Executor", jf.GetRepresentation()).Errorln(err)
+			}
+		}
+	}
+}
+
+// follow reloads the books the commands journaled after the given sequence touched and returns the last sequence read
+func (jf *JournalFollower) follow(ctx context.Context, after uint64) (uint64, error) {
+	touched := make(map[string]bool)
+	defer func() {
+		for symbol := range touched {
+			jf.books.Reload(ctx, symbol)
+		}
+	}()
+	for {
+		commands, err := jf.journal.ReadJournal(ctx, after, 0, journalFollowBatchSize)
+		if err != nil {
+			return after, err
+		}
+		for _, journaled := range commands {
+			touched[journaled.Command.Symbol] = true
+			after = journaled.Command.Sequence
+		}
+		if len(commands) < journalFollowBatchSize {
+			return after, nil
+		}
+	}
+}
+
+func (jf *JournalFollower) GetRepresentation() string {
+	return "JournalFollower"
+}
@@ -0,0 +1,736 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/sirupsen/logrus"
+)
+
+type SubmitOrderCommand struct {
+	OrderID          uint
+	AccountID        string
+	Symbol           string
+	Type             string
+	Side             string
+	Price            lib.Decimal
+	TriggerPrice     lib.Decimal
+	Quantity         lib.Decimal
+	DisplayQuantity  lib.Decimal
+	ExecInstructions order.ExecInstruction
+	STPMode          order.STPMode
+}
+
+type CancelOrderCommand struct {
+	OrderID uint
+	Symbol  string
+}
+
+// AmendOrderCommand changes the price and the open quantity of a live order, Quantity is what the order can still trade
+type AmendOrderCommand struct {
+	OrderID  uint
+	Symbol   string
+	Price    lib.Decimal
+	Quantity lib.Decimal
+}
+
+// OrderMatcher runs incoming orders through pre-trade checks and the book, every execution is published as an event
+type OrderMatcher struct {
+	orderEventProducer provider.IProducer
+	topics             OrderEventTopics
+	transactionGen     func() order.OrderTransaction
+	feeSchedule        order.IFeeSchedule
+	feeAccountID       string
+	tradingRules       order.ITradingRulesProvider
+	riskLimits         order.IRiskLimitsProvider
+	riskChain          order.RiskChain
+	selfTradePolicy    order.ISelfTradePolicy
+	tradingSessions    order.ITradingSessionProvider
+	volatilityBands    order.IVolatilityBandProvider
+	allocationRules    order.IAllocationRulesProvider
+	books              order.IBookReplica
+	clock              lib.IClock
+}
+
+// createOrderJournal is the journaled create command, the phase the order arrived in is kept so a replay does not
+// depend on the session schedule
+type createOrderJournal struct {
+	SubmitOrderCommand
+	Phase order.TradingPhase `json:"phase"`
+}
+
+type amendOrderJournal struct {
+	AmendOrderCommand
+	Phase order.TradingPhase `json:"phase"`
+}
+
+type uncrossJournal struct {
+	Symbol string             `json:"symbol"`
+	Next   order.TradingPhase `json:"next"`
+}
+
+type cancelAllJournal struct {
+	Symbol string `json:"symbol"`
+}
+
+func NewOrderMatcher(orderEventProducer provider.IProducer, topics OrderEventTopics, transactionGen func() order.OrderTransaction, feeSchedule order.IFeeSchedule, feeAccountID string, tradingRules order.ITradingRulesProvider, riskLimits order.IRiskLimitsProvider, riskChain order.RiskChain, selfTradePolicy order.ISelfTradePolicy, tradingSessions order.ITradingSessionProvider, volatilityBands order.IVolatilityBandProvider, allocationRules order.IAllocationRulesProvider, books order.IBookReplica, clock lib.IClock) *OrderMatcher {
+	return &OrderMatcher{
+		orderEventProducer: orderEventProducer,
+		topics:             topics,
+		transactionGen:     transactionGen,
+		feeSchedule:        feeSchedule,
+		feeAccountID:       feeAccountID,
+		tradingRules:       tradingRules,
+		riskLimits:         riskLimits,
+		riskChain:          riskChain,
+		selfTradePolicy:    selfTradePolicy,
+		tradingSessions:    tradingSessions,
+		volatilityBands:    volatilityBands,
+		allocationRules:    allocationRules,
+		books:              books,
+		clock:              clock,
+	}
+}
+
+// Submit places a new order, orders arriving outside the continuous phase rest in the book until the auction uncrosses.
+// The command is journaled with its events unless it fails and will be submitted again
+func (m *OrderMatcher) Submit(ctx context.Context, cmd SubmitOrderCommand) error {
+	orderRepo := m.newRecordingRepository()
+	om, err := order.NewOrder(cmd.OrderID, cmd.AccountID, cmd.Symbol, cmd.Side, cmd.Type, cmd.Price, cmd.TriggerPrice, cmd.Quantity, orderRepo.now)
+	if err == nil {
+		err = om.SetSelfTradePrevention(cmd.STPMode)
+	}
+	if err == nil {
+		err = om.SetDisplayQuantity(cmd.DisplayQuantity)
+	}
+	if err == nil {
+		err = om.SetExecInstructions(cmd.ExecInstructions)
+	}
+	if err == nil {
+		var rules order.TradingRules
+		if rules, err = orderRepo.reference.GetTradingRules(ctx, om.Symbol); err != nil {
+			return err
+		}
+		err = om.ValidateTradingRules(rules)
+	}
+	if err != nil {
+		logrus.Errorln(err)
+		// Invalid orders are erased from queue
+		return m.rejectSubmit(ctx, orderRepo, cmd, "", om, order.NewOrderRejected(order.InvalidOrderRejectReason, err.Error()))
+	}
+	phase, err := m.tradingSessions.GetTradingPhase(ctx, om.Symbol)
+	if err != nil {
+		return err
+	}
+	if !phase.AcceptsOrders() {
+		return m.rejectSubmit(ctx, orderRepo, cmd, phase, om, order.NewOrderRejected(phase.RejectReason(), "%s is %s", om.Symbol, phase))
+	}
+	if err := m.checkRisk(ctx, orderRepo, om, false); err != nil {
+		var rejected *order.OrderRejected
+		if errors.As(err, &rejected) {
+			logrus.WithField("orderID", om.ID).Warningln(rejected)
+			return m.rejectSubmit(ctx, orderRepo, cmd, phase, om, rejected)
+		}
+		return err
+	}
+	var tripped *order.CircuitBreakerTripped
+	err = orderRepo.CreateWithHook(ctx, om, func(ctx context.Context, createdOrder *order.Order) error {
+		orderRepo.record(order.NewOrderEvent(order.AcceptedOrderEventType, createdOrder, "", orderRepo.now))
+		// call phases only collect orders, the book is uncrossed when the auction ends
+		if phase.IsContinuous() {
+			err := m.matchOrder(ctx, orderRepo, createdOrder)
+			if err == nil {
+				err = m.triggerStops(ctx, orderRepo, createdOrder.Symbol)
+			}
+			// trades before the breach are kept and committed with the session leaving continuous trading
+			if err != nil && !errors.As(err, &tripped) {
+				return err
+			}
+			if err := m.trip(ctx, orderRepo, tripped); err != nil {
+				return err
+			}
+		}
+		return orderRepo.journal(ctx, order.CreateOrderJournalEntryType, cmd.Symbol, createOrderJournal{SubmitOrderCommand: cmd, Phase: phase})
+	})
+	if err != nil {
+		var rejected *order.OrderRejected
+		if errors.As(err, &rejected) {
+			logrus.WithField("orderID", om.ID).Warningln(rejected)
+			return m.rejectSubmit(ctx, orderRepo, cmd, phase, om, rejected)
+		}
+		if errors.Is(err, order.OrderAlreadyCreated) {
+			logrus.Warningln(order.OrderAlreadyCreated)
+			return nil
+		} else {
+			return err
+		}
+	}
+	m.books.Apply(ctx, om.Symbol, orderRepo.written...)
+	return nil
+}
+
+// Cancel removes a live order from the book or the trigger book, an order that is not live any more is left as it is.
+// The command is journaled either way
+func (m *OrderMatcher) Cancel(ctx context.Context, cmd CancelOrderCommand) error {
+	orderRepo := m.newRecordingRepository()
+	err := orderRepo.SelectLiveForUpdate(ctx, cmd.Symbol, cmd.OrderID, func(ctx context.Context, om *order.Order) error {
+		om.Cancel()
+		if err := orderRepo.Save(ctx, om); err != nil {
+			return err
+		}
+		orderRepo.record(order.NewOrderEvent(order.CancelledOrderEventType, om, "", orderRepo.now))
+		return orderRepo.journal(ctx, order.CancelOrderJournalEntryType, cmd.Symbol, cmd)
+	})
+	if errors.Is(err, order.OrderNotFound) {
+		logrus.WithField("orderID", cmd.OrderID).Warningln(err)
+		err = orderRepo.journal(ctx, order.CancelOrderJournalEntryType, cmd.Symbol, cmd)
+	}
+	if err != nil {
+		return err
+	}
+	m.books.Apply(ctx, cmd.Symbol, orderRepo.written...)
+	return nil
+}
+
+// Amend changes a live order after the trading rules and the pre-trade checks accepted it, an order losing its time
+// priority in continuous trading is matched again like an incoming one. A rejected amendment leaves the order as it
+// was and an order that is not live any more is left as it is, the command is journaled either way
+func (m *OrderMatcher) Amend(ctx context.Context, cmd AmendOrderCommand) error {
+	orderRepo := m.newRecordingRepository()
+	phase, err := m.tradingSessions.GetTradingPhase(ctx, cmd.Symbol)
+	if err != nil {
+		return err
+	}
+	journaled := amendOrderJournal{AmendOrderCommand: cmd, Phase: phase}
+	var unamended order.Order
+	var tripped *order.CircuitBreakerTripped
+	err = orderRepo.SelectLiveForUpdate(ctx, cmd.Symbol, cmd.OrderID, func(ctx context.Context, om *order.Order) error {
+		unamended = *om
+		if !phase.AcceptsOrders() {
+			return order.NewOrderRejected(phase.RejectReason(), "%s is %s", om.Symbol, phase)
+		}
+		err := om.Amend(cmd.Price, cmd.Quantity, orderRepo.now)
+		if err == nil {
+			var rules order.TradingRules
+			if rules, err = orderRepo.reference.GetTradingRules(ctx, om.Symbol); err != nil {
+				return err
+			}
+			err = om.ValidateTradingRules(rules)
+		}
+		if err != nil {
+			return order.NewOrderRejected(order.InvalidOrderRejectReason, err.Error())
+		}
+		if err := m.checkRisk(ctx, orderRepo, om, true); err != nil {
+			return err
+		}
+		orderRepo.record(order.NewOrderEvent(order.AmendedOrderEventType, om, "", orderRepo.now))
+		if phase.IsContinuous() && om.IsResting() && !om.PriorityAt.Equal(unamended.PriorityAt) {
+			err := m.matchOrder(ctx, orderRepo, om)
+			if err == nil {
+				err = m.triggerStops(ctx, orderRepo, om.Symbol)
+			}
+			if err != nil && !errors.As(err, &tripped) {
+				return err
+			}
+			if err := m.trip(ctx, orderRepo, tripped); err != nil {
+				return err
+			}
+		} else if err := orderRepo.Save(ctx, om); err != nil {
+			return err
+		}
+		return orderRepo.journal(ctx, order.AmendOrderJournalEntryType, cmd.Symbol, journaled)
+	})
+	var rejected *order.OrderRejected
+	if errors.As(err, &rejected) {
+		logrus.WithField("orderID", cmd.OrderID).Warningln(rejected)
+		if err = m.reject(ctx, orderRepo, &unamended, rejected); err == nil {
+			err = orderRepo.journal(ctx, order.AmendOrderJournalEntryType, cmd.Symbol, journaled)
+		}
+	} else if errors.Is(err, order.OrderNotFound) {
+		logrus.WithField("orderID", cmd.OrderID).Warningln(err)
+		err = orderRepo.journal(ctx, order.AmendOrderJournalEntryType, cmd.Symbol, journaled)
+	}
+	if err != nil {
+		return err
+	}
+	m.books.Apply(ctx, cmd.Symbol, orderRepo.written...)
+	return nil
+}
+
+// trip moves the session out of continuous trading in the transaction of the command that breached the band, a
+// failed trip rolls the command back so it is matched again once redelivered
+func (m *OrderMatcher) trip(ctx context.Context, orderRepo *recordingOrderRepository, tripped *order.CircuitBreakerTripped) error {
+	if tripped == nil {
+		return nil
+	}
+	logrus.WithField("symbol", tripped.Symbol).Warningln(tripped)
+	return orderRepo.circuitBreaker.Trip(ctx, tripped.Symbol)
+}
+
+// Uncross executes the call book of symbol at its equilibrium price, the remainder of market orders is cancelled.
+// next is the phase the session enters afterwards, stops are only triggered when it is continuous and a
+// *order.CircuitBreakerTripped is returned after commit when they breach the volatility band. A non nil commit runs
+// in the transaction of the uncross while the book is locked, the session stores next with it
+func (m *OrderMatcher) Uncross(ctx context.Context, symbol string, next order.TradingPhase, commit func(ctx context.Context) error) error {
+	orderRepo := m.newRecordingRepository()
+	var tripped *order.CircuitBreakerTripped
+	err := orderRepo.SelectBookForUpdate(ctx, symbol, func(ctx context.Context, book []*order.Order) error {
+		referencePrice, err := orderRepo.LastTradePrice(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		result := order.ComputeEquilibrium(book, referencePrice)
+		logrus.WithField("symbol", symbol).WithField("price", result.Price).WithField("volume", result.Volume).Infoln("auction uncrossed")
+		executed := make(map[uint]bool)
+		for _, fill := range order.AllocateAuction(book, result) {
+			taker, maker := fill.TakerMaker()
+			if err := m.execute(ctx, orderRepo, taker, maker, result.Price, fill.Quantity); err != nil {
+				return err
+			}
+			executed[taker.ID] = true
+		}
+		for _, om := range book {
+			if om.IsResting() && !om.Type.HasLimitPrice() {
+				om.Cancel()
+				orderRepo.record(order.NewOrderEvent(order.ExpiredOrderEventType, om, order.UnfilledMarketOrderEventReason, orderRepo.now))
+			} else if !executed[om.ID] {
+				continue
+			}
+			if err := orderRepo.Save(ctx, om); err != nil {
+				return err
+			}
+		}
+		if next.IsContinuous() {
+			if err := m.triggerStops(ctx, orderRepo, symbol); err != nil && !errors.As(err, &tripped) {
+				return err
+			}
+		}
+		if commit != nil {
+			if err := commit(ctx); err != nil {
+				return err
+			}
+		}
+		return orderRepo.journal(ctx, order.UncrossJournalEntryType, symbol, uncrossJournal{Symbol: symbol, Next: next})
+	})
+	if err != nil {
+		return err
+	}
+	m.books.Apply(ctx, symbol, orderRepo.written...)
+	if tripped != nil {
+		return tripped
+	}
+	return nil
+}
+
+// CancelAll empties the book and the trigger book of symbol, the book is locked so no order is matched meanwhile
+func (m *OrderMatcher) CancelAll(ctx context.Context, symbol string) (int, error) {
+	orderRepo := m.newRecordingRepository()
+	var cancelled []*order.Order
+	err := orderRepo.SelectBookForUpdate(ctx, symbol, func(ctx context.Context, _ []*order.Order) error {
+		var err error
+		if cancelled, err = orderRepo.CancelAll(ctx, symbol); err != nil {
+			return err
+		}
+		for _, om := range cancelled {
+			orderRepo.record(order.NewOrderEvent(order.CancelledOrderEventType, om, order.CancelAllOrderEventReason, orderRepo.now))
+		}
+		return orderRepo.journal(ctx, order.CancelAllJournalEntryType, symbol, cancelAllJournal{Symbol: symbol})
+	})
+	if err != nil {
+		return 0, err
+	}
+	logrus.WithField("symbol", symbol).WithField("cancelled", len(cancelled)).Warningln("all orders cancelled")
+	m.books.Reset(ctx, symbol)
+	return len(cancelled), nil
+}
+
+// matchOrder walks the opposite side level by level in price-time priority until the taker is filled or stops crossing
+func (m *OrderMatcher) matchOrder(ctx context.Context, orderRepo *recordingOrderRepository, taker *order.Order) error {
+	// TODO: database may become bottleneck, use cache or eventual solutions (Inbox pattern forexample) based on load
+	if !taker.IsResting() {
+		// conditional orders wait in the trigger book
+		return orderRepo.Save(ctx, taker)
+	}
+	if err := m.applyExecInstructions(ctx, orderRepo, taker); err != nil {
+		return err
+	}
+	if taker.ExecInstructions.Has(order.PostOnlyExecInstruction) {
+		// post only orders never take liquidity, they go straight to the book
+		return orderRepo.Save(ctx, taker)
+	}
+	stpMode, err := m.resolveSTPMode(ctx, orderRepo, taker)
+	if err != nil {
+		return err
+	}
+	band, referencePrice, err := m.volatilityReference(ctx, orderRepo, taker.Symbol, orderRepo.now)
+	if err != nil {
+		return err
+	}
+	allocation, err := orderRepo.reference.GetAllocationRules(ctx, taker.Symbol)
+	if err != nil {
+		return err
+	}
+	strategy := allocation.Strategy()
+	var limitPrice lib.Decimal
+	if taker.Type.HasLimitPrice() {
+		limitPrice = taker.Price
+	}
+	var tripped *order.CircuitBreakerTripped
+	for taker.IsResting() && tripped == nil {
+		level, err := orderRepo.SelectBestLevelForUpdate(ctx, taker.Symbol, taker.Side.GetMatchSide(), limitPrice)
+		if err != nil {
+			return err
+		}
+		if len(level) == 0 {
+			break
+		}
+		// self trades are resolved first so the allocation only shares the taker among the other accounts
+		makers := make([]*order.Order, 0, len(level))
+		for _, maker := range level {
+			if !taker.IsResting() {
+				break
+			}
+			if prevented := order.PreventSelfTrade(stpMode, taker, maker, orderRepo.now); prevented != nil {
+				if err := m.preventSelfTrade(ctx, orderRepo, prevented); err != nil {
+					return err
+				}
+				continue
+			}
+			makers = append(makers, maker)
+		}
+		if !taker.IsResting() || len(makers) == 0 {
+			continue
+		}
+		if band.Breached(makers[0].Price, referencePrice) {
+			tripped = &order.CircuitBreakerTripped{Symbol: taker.Symbol, Price: makers[0].Price, ReferencePrice: referencePrice}
+			break
+		}
+		for _, fill := range strategy.Allocate(taker.Open(), makers) {
+			if err := m.execute(ctx, orderRepo, taker, fill.Maker, fill.Maker.Price, fill.Quantity); err != nil {
+				return err
+			}
+		}
+	}
+	if taker.IsResting() && !taker.Type.HasLimitPrice() && (tripped == nil || band.Phase != order.AuctionTradingPhase) {
+		// market orders never rest in the continuous book, a volatility auction keeps them for the uncross
+		taker.Cancel()
+		orderRepo.record(order.NewOrderEvent(order.ExpiredOrderEventType, taker, order.UnfilledMarketOrderEventReason, orderRepo.now))
+	}
+	if err := orderRepo.Save(ctx, taker); err != nil {
+		return err
+	}
+	if tripped != nil {
+		return tripped
+	}
+	return nil
+}
+
+// volatilityReference loads the band of the symbol and its reference price at now, nothing is loaded when the band is disabled
+func (m *OrderMatcher) volatilityReference(ctx context.Context, orderRepo *recordingOrderRepository, symbol string, now time.Time) (order.VolatilityBand, lib.Decimal, error) {
+	band, err := orderRepo.reference.GetVolatilityBand(ctx, symbol)
+	if err != nil || !band.Enabled() {
+		return band, 0, err
+	}
+	referencePrice, err := orderRepo.ReferencePrice(ctx, symbol, now.Add(-band.Window))
+	return band, referencePrice, err
+}
+
+// triggerStops activates conditional orders one at a time against the latest trade price,
+// trades of an activated order move the price again so the cascade continues until nothing is triggered
+func (m *OrderMatcher) triggerStops(ctx context.Context, orderRepo *recordingOrderRepository, symbol string) error {
+	for {
+		lastTradePrice, err := orderRepo.LastTradePrice(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		if lastTradePrice <= 0 {
+			return nil
+		}
+		stop, err := orderRepo.SelectNextTriggeredForUpdate(ctx, symbol, lastTradePrice)
+		if err != nil {
+			return err
+		}
+		if stop == nil || !stop.IsTriggeredBy(lastTradePrice) {
+			return nil
+		}
+		stop.Trigger(orderRepo.now)
+		orderRepo.record(order.NewOrderEvent(order.TriggeredOrderEventType, stop, "", orderRepo.now))
+		logrus.WithField("orderID", stop.ID).WithField("lastTradePrice", lastTradePrice).Debugln("stop order triggered")
+		if err := m.matchOrder(ctx, orderRepo, stop); err != nil {
+			var rejected *order.OrderRejected
+			if !errors.As(err, &rejected) {
+				return err
+			}
+			stop.Cancel()
+			if err := orderRepo.Save(ctx, stop); err != nil {
+				return err
+			}
+			if err := m.reject(ctx, orderRepo, stop, rejected); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// applyExecInstructions evaluates the execution flags of the order before it crosses the book
+func (m *OrderMatcher) applyExecInstructions(ctx context.Context, orderRepo *recordingOrderRepository, taker *order.Order) error {
+	if taker.ExecInstructions.Has(order.ReduceOnlyExecInstruction) {
+		position, err := orderRepo.NetPosition(ctx, taker.Symbol, taker.AccountID)
+		if err != nil {
+			return err
+		}
+		open := taker.Open()
+		if err := taker.ApplyReduceOnly(position, orderRepo.now); err != nil {
+			return err
+		}
+		if taker.Open() < open {
+			amended := order.NewOrderEvent(order.AmendedOrderEventType, taker, order.ReduceOnlyOrderEventReason, orderRepo.now)
+			amended.Quantity = open - taker.Open()
+			orderRepo.record(amended)
+		}
+	}
+	if taker.ExecInstructions.Has(order.PostOnlyExecInstruction) {
+		bestOppositePrice, err := orderRepo.BestPrice(ctx, taker.Symbol, taker.Side.GetMatchSide())
+		if err != nil {
+			return err
+		}
+		rules, err := orderRepo.reference.GetTradingRules(ctx, taker.Symbol)
+		if err != nil {
+			return err
+		}
+		price := taker.Price
+		if err := taker.ApplyPostOnly(bestOppositePrice, rules.TickSize); err != nil {
+			return err
+		}
+		if taker.Price != price {
+			orderRepo.record(order.NewOrderEvent(order.AmendedOrderEventType, taker, order.RepricedOrderEventReason, orderRepo.now))
+		}
+	}
+	return nil
+}
+
+// execute trades quantity between both orders at price and saves the maker, continuous matching trades at the resting
+// order price and only the visible slice of a resting iceberg is tradable
+func (m *OrderMatcher) execute(ctx context.Context, orderRepo *recordingOrderRepository, taker, maker *order.Order, price, quantity lib.Decimal) error {
+	trade := order.NewTrade(taker, maker, price, quantity, orderRepo.now)
+	taker.Fill(trade.Quantity, orderRepo.now)
+	maker.Fill(trade.Quantity, orderRepo.now)
+	if err := orderRepo.Save(ctx, maker); err != nil {
+		return err
+	}
+	if err := m.chargeFees(ctx, orderRepo, trade); err != nil {
+		return err
+	}
+	if err := orderRepo.CreateTrade(ctx, trade); err != nil {
+		return err
+	}
+	orderRepo.record(order.NewFillEvent(taker, trade), order.NewFillEvent(maker, trade))
+	matchEvent := orderMatchEvent{
+		Symbol:         trade.Symbol,
+		OrderID:        taker.ID,
+		MatchedOrderID: maker.ID,
+		TradeID:        trade.ID,
+		Price:          trade.Price,
+		Quantity:       trade.Quantity,
+		TakerFee:       trade.TakerFee,
+		MakerFee:       trade.MakerFee,
+		FeeAccountID:   trade.FeeAccountID,
+		CreatedAt:      trade.CreatedAt,
+	}
+	return m.publish(ctx, orderRepo, m.topics.Matched, order.OrderMatchedJournalEntryType, matchEvent)
+}
+
+// resolveSTPMode prefers the mode of the order over the default mode of its account
+func (m *OrderMatcher) resolveSTPMode(ctx context.Context, orderRepo *recordingOrderRepository, taker *order.Order) (order.STPMode, error) {
+	if taker.SelfTradePrevention != order.NoneSTPMode {
+		return taker.SelfTradePrevention, nil
+	}
+	return orderRepo.reference.GetSTPMode(ctx, taker.AccountID)
+}
+
+func (m *OrderMatcher) preventSelfTrade(ctx context.Context, orderRepo *recordingOrderRepository, prevented *order.SelfTradePrevented) error {
+	if err := orderRepo.Save(ctx, prevented.Maker); err != nil {
+		return err
+	}
+	orderRepo.record(order.NewSelfTradeEvents(prevented, orderRepo.now)...)
+	return m.publish(ctx, orderRepo, m.topics.SelfTradePrevented, order.SelfTradePreventedJournalEntryType, selfTradePreventedEvent{
+		Symbol:              prevented.Taker.Symbol,
+		AccountID:           prevented.Taker.AccountID,
+		Mode:                prevented.Mode,
+		OrderID:             prevented.Taker.ID,
+		MatchedOrderID:      prevented.Maker.ID,
+		CancelledOrderIDs:   prevented.CancelledOrderIDs,
+		DecrementedQuantity: prevented.DecrementedQuantity,
+		CreatedAt:           orderRepo.now,
+	})
+}
+
+// checkRisk runs the pre-trade chain against the instrument limits and the current market snapshot, an amended order
+// is already counted among the open orders of its account
+func (m *OrderMatcher) checkRisk(ctx context.Context, orderRepo *recordingOrderRepository, om *order.Order, amended bool) error {
+	limits, err := orderRepo.reference.GetRiskLimits(ctx, om.Symbol)
+	if err != nil {
+		return err
+	}
+	rc := order.RiskContext{Limits: limits}
+	// orders without a limit price are valued at the market
+	valued := limits.MaxOrderNotional > 0 && !om.Type.HasLimitPrice()
+	if limits.PriceCollarBps > 0 || valued {
+		if rc.LastTradePrice, err = orderRepo.LastTradePrice(ctx, om.Symbol); err != nil {
+			return err
+		}
+	}
+	if limits.FatFingerBps > 0 || (valued && rc.LastTradePrice <= 0) {
+		if rc.BestOppositePrice, err = orderRepo.BestPrice(ctx, om.Symbol, om.Side.GetMatchSide()); err != nil {
+			return err
+		}
+	}
+	if limits.MaxOpenOrders > 0 {
+		if rc.OpenOrders, err = orderRepo.CountOpenOrders(ctx, om.Symbol, om.AccountID); err != nil {
+			return err
+		}
+		if amended && om.IsResting() {
+			rc.OpenOrders--
+		}
+	}
+	return m.riskChain.Check(om, rc)
+}
+
+func (m *OrderMatcher) reject(ctx context.Context, orderRepo *recordingOrderRepository, om *order.Order, rejected *order.OrderRejected) error {
+	orderRepo.record(order.NewRejectEvent(om, rejected, orderRepo.now))
+	return m.publish(ctx, orderRepo, m.topics.Rejected, order.OrderRejectedJournalEntryType, orderRejectEvent{
+		OrderID:   om.ID,
+		AccountID: om.AccountID,
+		Symbol:    om.Symbol,
+		Reason:    rejected.Reason,
+		Message:   rejected.Message,
+		CreatedAt: orderRepo.now,
+	})
+}
+
+// rejectSubmit journals a submitted order that never reached the book, phase is empty when it was not read yet
+func (m *OrderMatcher) rejectSubmit(ctx context.Context, orderRepo *recordingOrderRepository, cmd SubmitOrderCommand, phase order.TradingPhase, om *order.Order, rejected *order.OrderRejected) error {
+	if err := m.reject(ctx, orderRepo, om, rejected); err != nil {
+		return err
+	}
+	return orderRepo.journal(ctx, order.CreateOrderJournalEntryType, cmd.Symbol, createOrderJournal{SubmitOrderCommand: cmd, Phase: phase})
+}
+
+// publish produces the event and keeps it for the journal of the command
+func (m *OrderMatcher) publish(ctx context.Context, orderRepo *recordingOrderRepository, topic string, eventType order.JournalEntryType, event any) error {
+	bts, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	logrus.WithField("topic", topic).Debugln(string(bts))
+	orderRepo.events = append(orderRepo.events, order.NewJournalEvent(eventType, "", string(bts), orderRepo.now))
+	return m.orderEventProducer.Produce(ctx, topic, string(bts))
+}
+
+// chargeFees uses the maker rate of the resting order account and the taker rate of the incoming order account
+func (m *OrderMatcher) chargeFees(ctx context.Context, orderRepo *recordingOrderRepository, trade *order.Trade) error {
+	makerRate, _, err := orderRepo.reference.GetFeeRates(ctx, trade.MakerAccountID)
+	if err != nil {
+		return err
+	}
+	_, takerRate, err := orderRepo.reference.GetFeeRates(ctx, trade.TakerAccountID)
+	if err != nil {
+		return err
+	}
+	trade.ApplyFees(makerRate, takerRate, m.feeAccountID)
+	return nil
+}
+
+// newRecordingRepository starts a command, its time is kept to the microsecond so it survives the database unchanged
+func (m *OrderMatcher) newRecordingRepository() *recordingOrderRepository {
+	transaction := m.transactionGen()
+	return &recordingOrderRepository{
+		IOrderWriteRepository: transaction.Orders,
+		circuitBreaker:        transaction.CircuitBreaker,
+		now:                   m.clock.Now().UTC().Truncate(time.Microsecond),
+		reference:             &referenceRecorder{matcher: m},
+	}
+}
+
+// recordingOrderRepository belongs to one command, it remembers the orders written in a transaction so the books are
+// updated once it commits, the events published so they are journaled with the command, the history of the orders
+// and the reference data the command read
+type recordingOrderRepository struct {
+	order.IOrderWriteRepository
+	circuitBreaker order.ICircuitBreaker
+	now            time.Time
+	written        []*order.Order
+	events         []*order.JournalEntry
+	history        []*order.OrderEvent
+	reference      *referenceRecorder
+}
+
+func (r *recordingOrderRepository) record(events ...*order.OrderEvent) {
+	r.history = append(r.history, events...)
+}
+
+// journal appends the command with the events published so far, the history of its orders and the reference data
+// it read
+func (r *recordingOrderRepository) journal(ctx context.Context, commandType order.JournalEntryType, symbol string, command any) error {
+	entry, err := order.NewJournalCommand(commandType, symbol, command, r.now)
+	if err != nil {
+		return err
+	}
+	if !r.reference.snapshot.isEmpty() {
+		reference, err := json.Marshal(r.reference.snapshot)
+		if err != nil {
+			return err
+		}
+		entry.Reference = string(reference)
+	}
+	if err := r.AppendOrderEvents(ctx, r.history...); err != nil {
+		return err
+	}
+	for _, event := range r.events {
+		event.Symbol = symbol
+	}
+	return r.AppendJournal(ctx, entry, r.events...)
+}
+
+// CreateWithHook forgets the events and the history of a rolled back transaction, they are not part of the journal
+func (r *recordingOrderRepository) CreateWithHook(ctx context.Context, om *order.Order, process func(ctx context.Context, om *order.Order) error) error {
+	published, recorded := len(r.events), len(r.history)
+	if err := r.IOrderWriteRepository.CreateWithHook(ctx, om, process); err != nil {
+		r.events, r.history = r.events[:published], r.history[:recorded]
+		return err
+	}
+	r.written = append(r.written, om)
+	return nil
+}
+
+func (r *recordingOrderRepository) SelectBookForUpdate(ctx context.Context, symbol string, process func(ctx context.Context, book []*order.Order) error) error {
+	published, recorded := len(r.events), len(r.history)
+	if err := r.IOrderWriteRepository.SelectBookForUpdate(ctx, symbol, process); err != nil {
+		r.events, r.history = r.events[:published], r.history[:recorded]
+		return err
+	}
+	return nil
+}
+
+// SelectLiveForUpdate forgets what a rolled back transaction wrote, published and recorded
+func (r *recordingOrderRepository) SelectLiveForUpdate(ctx context.Context, symbol string, id uint, process func(ctx context.Context, om *order.Order) error) error {
+	written, published, recorded := len(r.written), len(r.events), len(r.history)
+	if err := r.IOrderWriteRepository.SelectLiveForUpdate(ctx, symbol, id, process); err != nil {
+		r.written, r.events, r.history = r.written[:written], r.events[:published], r.history[:recorded]
+		return err
+	}
+	return nil
+}
+
+func (r *recordingOrderRepository) Save(ctx context.Context, om *order.Order) error {
+	if err := r.IOrderWriteRepository.Save(ctx, om); err != nil {
+		return err
+	}
+	r.written = append(r.written, om)
+	return nil
+}
@@ -0,0 +1,318 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/modules/order/infrastructure"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	matchedTopic            = "matches"
+	rejectedTopic           = "rejections"
+	selfTradePreventedTopic = "self-trade-preventions"
+)
+
+// configuredReference is referenceData with the risk limits and the account modes of a test
+type configuredReference struct {
+	referenceData
+	limits   order.RiskLimits
+	stpModes map[string]order.STPMode
+	band     order.VolatilityBand
+}
+
+func (cr *configuredReference) GetVolatilityBand(context.Context, string) (order.VolatilityBand, error) {
+	return cr.band, nil
+}
+
+func (cr *configuredReference) GetRiskLimits(context.Context, string) (order.RiskLimits, error) {
+	return cr.limits, nil
+}
+
+func (cr *configuredReference) GetSTPMode(_ context.Context, accountID string) (order.STPMode, error) {
+	if mode, ok := cr.stpModes[accountID]; ok {
+		return mode, nil
+	}
+	return order.NoneSTPMode, nil
+}
+
+// circuitBreaker moves the sessions to the phase of the band unless it fails with err
+type circuitBreaker struct {
+	sessions  *application.JournaledSessions
+	reference *configuredReference
+	err       error
+}
+
+func (cb *circuitBreaker) Trip(_ context.Context, symbol string) error {
+	if cb.err != nil {
+		return cb.err
+	}
+	cb.sessions.SetTradingPhase(symbol, cb.reference.band.Phase)
+	return nil
+}
+
+// rejection is the payload of the rejected topic
+type rejection struct {
+	OrderID   uint               `json:"orderID"`
+	AccountID string             `json:"accountID"`
+	Symbol    string             `json:"symbol"`
+	Reason    order.RejectReason `json:"reason"`
+}
+
+// execution is the payload of the matched topic
+type execution struct {
+	OrderID        uint        `json:"orderID"`
+	MatchedOrderID uint        `json:"matchedOrderID"`
+	Price          lib.Decimal `json:"price"`
+	Quantity       lib.Decimal `json:"quantity"`
+}
+
+// selfTradePrevention is the payload of the self trade prevented topic
+type selfTradePrevention struct {
+	AccountID           string        `json:"accountID"`
+	Mode                order.STPMode `json:"mode"`
+	OrderID             uint          `json:"orderID"`
+	MatchedOrderID      uint          `json:"matchedOrderID"`
+	CancelledOrderIDs   []uint        `json:"cancelledOrderIDs"`
+	DecrementedQuantity lib.Decimal   `json:"decrementedQuantity"`
+}
+
+// MatcherTestSuite runs the matcher of the order topic on a memory repository and reads what it published
+type MatcherTestSuite struct {
+	suite.Suite
+	repository *infrastructure.MemoryOrderRepository
+	producer   *recordingProducer
+	reference  *configuredReference
+	clock      *lib.SimulatedClock
+	sessions   *application.JournaledSessions
+	breaker    *circuitBreaker
+	matcher    *application.OrderMatcher
+}
+
+func TestMatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(MatcherTestSuite))
+}
+
+func (suite *MatcherTestSuite) SetupTest() {
+	suite.repository = infrastructure.NewMemoryOrderRepository()
+	suite.producer = &recordingProducer{}
+	suite.reference = &configuredReference{stpModes: make(map[string]order.STPMode)}
+	suite.clock = lib.NewSimulatedClock(time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC))
+	suite.sessions = application.NewJournaledSessions(order.ContinuousTradingPhase)
+	suite.breaker = &circuitBreaker{sessions: suite.sessions, reference: suite.reference}
+	suite.matcher = application.NewOrderMatcher(suite.producer,
+		application.OrderEventTopics{Matched: matchedTopic, Rejected: rejectedTopic, SelfTradePrevented: selfTradePreventedTopic},
+		func() order.OrderTransaction {
+			return order.OrderTransaction{Orders: suite.repository, CircuitBreaker: suite.breaker}
+		},
+		suite.reference, "fees", suite.reference, suite.reference, order.DefaultRiskChain(), suite.reference,
+		suite.sessions, suite.reference, suite.reference, application.DetachedBooks{}, suite.clock)
+}
+
+func (suite *MatcherTestSuite) submit(cmd application.SubmitOrderCommand) {
+	suite.clock.Set(suite.clock.Now().Add(time.Second))
+	cmd.Symbol = "BTC-USD"
+	if cmd.Type == "" {
+		cmd.Type = "limit"
+	}
+	suite.Require().NoError(suite.matcher.Submit(context.Background(), cmd))
+}
+
+// published decodes every message of topic into a new V, oldest first
+func published[V any](suite *MatcherTestSuite, topic string) []V {
+	var messages []V
+	for _, produced := range suite.producer.produced {
+		if produced.topic != topic {
+			continue
+		}
+		var message V
+		suite.Require().NoError(json.Unmarshal([]byte(produced.message), &message))
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// resting is the open quantity of every order in the book by id
+func (suite *MatcherTestSuite) resting() map[uint]lib.Decimal {
+	orders, err := suite.repository.Resting(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	open := make(map[uint]lib.Decimal)
+	for _, om := range orders {
+		open[om.ID] = om.Open()
+	}
+	return open
+}
+
+func (suite *MatcherTestSuite) TestRiskRejectionsArePublishedWithTheirReason() {
+	dec := lib.NewDecimalFromInt
+	suite.reference.limits = order.RiskLimits{MaxOrderQuantity: dec(10), PriceCollarBps: 1000, FatFingerBps: 500, MaxOpenOrders: 2}
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "maker", Side: "sell", Price: dec(100), Quantity: dec(2)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "taker", Side: "buy", Price: dec(100), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "taker", Side: "buy", Price: dec(100), Quantity: dec(11)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 4, AccountID: "taker", Side: "buy", Price: dec(111), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 5, AccountID: "taker", Side: "buy", Price: dec(106), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 6, AccountID: "maker", Side: "sell", Price: dec(101), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 7, AccountID: "maker", Side: "sell", Price: dec(102), Quantity: dec(1)})
+
+	suite.Equal([]rejection{
+		{OrderID: 3, AccountID: "taker", Symbol: "BTC-USD", Reason: order.MaxQuantityRejectReason},
+		{OrderID: 4, AccountID: "taker", Symbol: "BTC-USD", Reason: order.PriceCollarRejectReason},
+		{OrderID: 5, AccountID: "taker", Symbol: "BTC-USD", Reason: order.FatFingerRejectReason},
+		{OrderID: 7, AccountID: "maker", Symbol: "BTC-USD", Reason: order.MaxOpenOrdersRejectReason},
+	}, published[rejection](suite, rejectedTopic))
+	suite.Equal(map[uint]lib.Decimal{1: dec(1), 6: dec(1)}, suite.resting())
+	suite.Len(published[execution](suite, matchedTopic), 1)
+
+	journal := suite.repository.TakeJournal()
+	suite.Require().Len(journal, 7)
+	suite.Require().Len(journal[2].Events, 1)
+	suite.Equal(order.OrderRejectedJournalEntryType, journal[2].Events[0].Type)
+}
+
+func (suite *MatcherTestSuite) TestTheModeOfTheOrderOverridesTheModeOfTheAccount() {
+	dec := lib.NewDecimalFromInt
+	suite.reference.stpModes["desk"] = order.CancelOldestSTPMode
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "desk", Side: "sell", Price: dec(100), Quantity: dec(3)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "maker", Side: "sell", Price: dec(100), Quantity: dec(2)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "desk", Side: "buy", Price: dec(100), Quantity: dec(4)})
+	suite.Equal(map[uint]lib.Decimal{3: dec(2)}, suite.resting())
+	suite.Equal([]execution{{OrderID: 3, MatchedOrderID: 2, Price: dec(100), Quantity: dec(2)}}, published[execution](suite, matchedTopic))
+
+	suite.submit(application.SubmitOrderCommand{OrderID: 4, AccountID: "desk", Side: "sell", Price: dec(100), Quantity: dec(5), STPMode: order.CancelNewestSTPMode})
+	suite.Equal(map[uint]lib.Decimal{3: dec(2)}, suite.resting())
+	suite.submit(application.SubmitOrderCommand{OrderID: 5, AccountID: "desk", Side: "sell", Price: dec(100), Quantity: dec(1), STPMode: order.DecrementAndCancelSTPMode})
+	suite.Equal(map[uint]lib.Decimal{3: dec(1)}, suite.resting())
+	suite.submit(application.SubmitOrderCommand{OrderID: 6, AccountID: "desk", Side: "sell", Price: dec(100), Quantity: dec(3), STPMode: order.CancelBothSTPMode})
+	suite.Empty(suite.resting())
+
+	suite.Equal([]selfTradePrevention{
+		{AccountID: "desk", Mode: order.CancelOldestSTPMode, OrderID: 3, MatchedOrderID: 1, CancelledOrderIDs: []uint{1}},
+		{AccountID: "desk", Mode: order.CancelNewestSTPMode, OrderID: 4, MatchedOrderID: 3, CancelledOrderIDs: []uint{4}},
+		{AccountID: "desk", Mode: order.DecrementAndCancelSTPMode, OrderID: 5, MatchedOrderID: 3, CancelledOrderIDs: []uint{5}, DecrementedQuantity: dec(1)},
+		{AccountID: "desk", Mode: order.CancelBothSTPMode, OrderID: 6, MatchedOrderID: 3, CancelledOrderIDs: []uint{6, 3}},
+	}, published[selfTradePrevention](suite, selfTradePreventedTopic))
+	suite.Len(published[execution](suite, matchedTopic), 1)
+}
+
+func (suite *MatcherTestSuite) TestTriggeredSellStopsCascadeInTriggerPriority() {
+	dec := lib.NewDecimalFromInt
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "bids", Side: "buy", Price: dec(99), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "bids", Side: "buy", Price: dec(98), Quantity: dec(2)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "bids", Side: "buy", Price: dec(97), Quantity: dec(5)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 4, AccountID: "stops", Side: "sell", Type: "stop", TriggerPrice: dec(98), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 5, AccountID: "stops", Side: "sell", Type: "stop", TriggerPrice: dec(99), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 6, AccountID: "stops", Side: "sell", Type: "stop", TriggerPrice: dec(99), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 7, AccountID: "stops", Side: "sell", Type: "stop_limit", TriggerPrice: dec(97), Price: dec(97), Quantity: dec(6)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 8, AccountID: "stops", Side: "sell", Type: "stop", TriggerPrice: dec(90), Quantity: dec(1)})
+	suite.Empty(published[execution](suite, matchedTopic))
+
+	suite.submit(application.SubmitOrderCommand{OrderID: 9, AccountID: "seller", Side: "sell", Price: dec(99), Quantity: dec(1)})
+	suite.Equal([]execution{
+		{OrderID: 9, MatchedOrderID: 1, Price: dec(99), Quantity: dec(1)},
+		{OrderID: 5, MatchedOrderID: 2, Price: dec(98), Quantity: dec(1)},
+		{OrderID: 6, MatchedOrderID: 2, Price: dec(98), Quantity: dec(1)},
+		{OrderID: 4, MatchedOrderID: 3, Price: dec(97), Quantity: dec(1)},
+		{OrderID: 7, MatchedOrderID: 3, Price: dec(97), Quantity: dec(4)},
+	}, published[execution](suite, matchedTopic))
+	suite.Equal(map[uint]lib.Decimal{7: dec(2)}, suite.resting())
+
+	pending, err := suite.repository.SelectNextTriggeredForUpdate(context.Background(), "BTC-USD", dec(90))
+	suite.Require().NoError(err)
+	suite.Equal(uint(8), pending.ID)
+}
+
+func (suite *MatcherTestSuite) TestTriggeredBuyStopLimitsRestAtTheirLimit() {
+	dec := lib.NewDecimalFromInt
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "asks", Side: "sell", Price: dec(101), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "asks", Side: "sell", Price: dec(102), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "asks", Side: "sell", Price: dec(104), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 4, AccountID: "stops", Side: "buy", Type: "stop_limit", TriggerPrice: dec(102), Price: dec(103), Quantity: dec(2)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 5, AccountID: "stops", Side: "buy", Type: "stop", TriggerPrice: dec(101), Quantity: dec(1)})
+
+	suite.submit(application.SubmitOrderCommand{OrderID: 6, AccountID: "buyer", Side: "buy", Price: dec(101), Quantity: dec(1)})
+	suite.Equal([]execution{
+		{OrderID: 6, MatchedOrderID: 1, Price: dec(101), Quantity: dec(1)},
+		{OrderID: 5, MatchedOrderID: 2, Price: dec(102), Quantity: dec(1)},
+	}, published[execution](suite, matchedTopic))
+	suite.Equal(map[uint]lib.Decimal{3: dec(1), 4: dec(2)}, suite.resting())
+
+	suite.submit(application.SubmitOrderCommand{OrderID: 7, AccountID: "seller", Side: "sell", Price: dec(102), Quantity: dec(2)})
+	suite.Equal(execution{OrderID: 7, MatchedOrderID: 4, Price: dec(103), Quantity: dec(2)}, published[execution](suite, matchedTopic)[2])
+	suite.Equal(map[uint]lib.Decimal{3: dec(1)}, suite.resting())
+}
+
+func (suite *MatcherTestSuite) TestPostOnlyAndReduceOnlyAreAppliedBeforeCrossing() {
+	dec := lib.NewDecimalFromInt
+	postOnly, reduceOnly := order.PostOnlyExecInstruction, order.ReduceOnlyExecInstruction
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "asks", Side: "sell", Price: dec(100), Quantity: dec(5)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "desk", Side: "buy", Price: dec(100), Quantity: dec(3)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "desk", Side: "buy", Price: dec(101), Quantity: dec(1), ExecInstructions: postOnly})
+	suite.submit(application.SubmitOrderCommand{OrderID: 4, AccountID: "desk", Side: "buy", Price: dec(101), Quantity: dec(3), ExecInstructions: postOnly | order.RepriceExecInstruction})
+	suite.submit(application.SubmitOrderCommand{OrderID: 5, AccountID: "desk", Side: "sell", Price: dec(100), Quantity: dec(5), ExecInstructions: reduceOnly})
+	suite.submit(application.SubmitOrderCommand{OrderID: 6, AccountID: "desk", Side: "buy", Price: dec(98), Quantity: dec(1), ExecInstructions: reduceOnly})
+
+	suite.Equal([]rejection{
+		{OrderID: 3, AccountID: "desk", Symbol: "BTC-USD", Reason: order.PostOnlyRejectReason},
+		{OrderID: 6, AccountID: "desk", Symbol: "BTC-USD", Reason: order.ReduceOnlyRejectReason},
+	}, published[rejection](suite, rejectedTopic))
+	suite.Len(published[execution](suite, matchedTopic), 1)
+	suite.Equal(map[uint]lib.Decimal{1: dec(2), 4: dec(3), 5: dec(3)}, suite.resting())
+
+	orders, err := suite.repository.Resting(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	for _, om := range orders {
+		if om.ID == 4 {
+			suite.Equal(dec(99), om.Price)
+		}
+	}
+}
+
+func (suite *MatcherTestSuite) TestMarketOrdersAreValuedAtTheMarketAgainstTheNotionalLimit() {
+	dec := lib.NewDecimalFromInt
+	suite.reference.limits = order.RiskLimits{MaxOrderNotional: dec(1000)}
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "taker", Side: "buy", Type: "market", Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "maker", Side: "sell", Price: dec(100), Quantity: dec(10)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "taker", Side: "buy", Type: "market", Quantity: dec(11)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 4, AccountID: "taker", Side: "buy", Type: "market", Quantity: dec(5)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 5, AccountID: "taker", Side: "sell", Type: "stop", TriggerPrice: dec(90), Quantity: dec(11)})
+
+	suite.Equal([]rejection{
+		{OrderID: 1, AccountID: "taker", Symbol: "BTC-USD", Reason: order.MaxNotionalRejectReason},
+		{OrderID: 3, AccountID: "taker", Symbol: "BTC-USD", Reason: order.MaxNotionalRejectReason},
+		{OrderID: 5, AccountID: "taker", Symbol: "BTC-USD", Reason: order.MaxNotionalRejectReason},
+	}, published[rejection](suite, rejectedTopic))
+	suite.Equal([]execution{{OrderID: 4, MatchedOrderID: 2, Price: dec(100), Quantity: dec(5)}}, published[execution](suite, matchedTopic))
+}
+
+func (suite *MatcherTestSuite) TestAFailedTripRollsTheBreachingOrderBack() {
+	dec := lib.NewDecimalFromInt
+	suite.reference.band = order.VolatilityBand{BandBps: 1000, Window: time.Hour, Phase: order.AuctionTradingPhase}
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "asks", Side: "sell", Price: dec(100), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "bids", Side: "buy", Price: dec(100), Quantity: dec(1)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "asks", Side: "sell", Price: dec(120), Quantity: dec(1)})
+
+	suite.breaker.err = errors.New("instrument locked")
+	breaching := application.SubmitOrderCommand{OrderID: 4, AccountID: "bids", Symbol: "BTC-USD", Side: "buy", Type: "market", Quantity: dec(1)}
+	suite.ErrorIs(suite.matcher.Submit(context.Background(), breaching), suite.breaker.err)
+	suite.Equal(map[uint]lib.Decimal{3: dec(1)}, suite.resting())
+	phase, err := suite.sessions.GetTradingPhase(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	suite.Equal(order.ContinuousTradingPhase, phase)
+
+	// the redelivered command breaches the band again and moves the session with the order kept for the auction
+	suite.breaker.err = nil
+	suite.Require().NoError(suite.matcher.Submit(context.Background(), breaching))
+	suite.Equal(map[uint]lib.Decimal{3: dec(1), 4: dec(1)}, suite.resting())
+	phase, err = suite.sessions.GetTradingPhase(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	suite.Equal(order.AuctionTradingPhase, phase)
+	suite.Len(published[execution](suite, matchedTopic), 1)
+}
@@ -0,0 +1,153 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/modules/order/infrastructure"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// historyRepository keeps the order events the memory repository forgets
+type historyRepository struct {
+	*infrastructure.MemoryOrderRepository
+	events map[uint][]*order.OrderEvent
+}
+
+func (hr *historyRepository) AppendOrderEvents(_ context.Context, events ...*order.OrderEvent) error {
+	for _, event := range events {
+		hr.events[event.OrderID] = append(hr.events[event.OrderID], event)
+	}
+	return nil
+}
+
+// historyReads queries the resting orders and the events of a historyRepository
+type historyReads struct {
+	order.IOrderReadRepository
+	history *historyRepository
+}
+
+func (hr historyReads) Get(ctx context.Context, id uint) (*order.Order, error) {
+	resting, err := hr.history.Resting(ctx, "BTC-USD")
+	if err != nil {
+		return nil, err
+	}
+	for _, om := range resting {
+		if om.ID == id {
+			return om, nil
+		}
+	}
+	return nil, lib.NewNotFoundError("order")
+}
+
+func (hr historyReads) ListOrderEvents(_ context.Context, orderID uint) ([]*order.OrderEvent, error) {
+	return hr.history.events[orderID], nil
+}
+
+type OrderHistoryTestSuite struct {
+	suite.Suite
+	repository *historyRepository
+	clock      *lib.SimulatedClock
+	matcher    *application.OrderMatcher
+	queries    *application.OrderQueryHandler
+}
+
+func TestOrderHistoryTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderHistoryTestSuite))
+}
+
+func (suite *OrderHistoryTestSuite) SetupTest() {
+	suite.repository = &historyRepository{MemoryOrderRepository: infrastructure.NewMemoryOrderRepository(), events: make(map[uint][]*order.OrderEvent)}
+	suite.clock = lib.NewSimulatedClock(time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC))
+	sessions := application.NewJournaledSessions(order.ContinuousTradingPhase)
+	suite.matcher = application.NewOrderMatcher(provider.DiscardProducer{}, application.OrderEventTopics{},
+		func() order.OrderTransaction {
+			return order.OrderTransaction{Orders: suite.repository, CircuitBreaker: sessions}
+		},
+		referenceData{}, "fees", referenceData{}, referenceData{}, order.DefaultRiskChain(), referenceData{},
+		sessions, referenceData{}, referenceData{}, application.DetachedBooks{}, suite.clock)
+	suite.queries = application.NewOrderQueryHandler(historyReads{history: suite.repository}, sessions)
+}
+
+func (suite *OrderHistoryTestSuite) submit(cmd application.SubmitOrderCommand) {
+	suite.clock.Set(suite.clock.Now().Add(time.Second))
+	cmd.Symbol = "BTC-USD"
+	suite.Require().NoError(suite.matcher.Submit(context.Background(), cmd))
+}
+
+func (suite *OrderHistoryTestSuite) types(orderID uint) []order.OrderEventType {
+	var types []order.OrderEventType
+	for _, event := range suite.repository.events[orderID] {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func (suite *OrderHistoryTestSuite) TestFillsKeepTheirTradeAndTheMarketRemainderExpires() {
+	dec := lib.NewDecimalFromInt
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "maker", Side: "sell", Price: dec(101), Quantity: dec(10)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "taker", Side: "buy", Price: dec(101), Quantity: dec(4)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "taker", Side: "buy", Type: "market", Quantity: dec(10)})
+
+	suite.Equal([]order.OrderEventType{order.AcceptedOrderEventType, order.FilledOrderEventType, order.FilledOrderEventType}, suite.types(1))
+	partial, filled := suite.repository.events[1][1], suite.repository.events[1][2]
+	suite.Equal(order.PartiallyFilledOrderStatus, partial.Status)
+	suite.Equal(dec(4), partial.Quantity)
+	suite.Equal(dec(6), partial.Open)
+	suite.Equal(order.FilledOrderStatus, filled.Status)
+	suite.NotEqual(partial.TradeID, filled.TradeID)
+	suite.Equal(filled.TradeID, suite.repository.events[3][1].TradeID)
+
+	suite.Equal([]order.OrderEventType{order.AcceptedOrderEventType, order.FilledOrderEventType, order.ExpiredOrderEventType}, suite.types(3))
+	expired := suite.repository.events[3][2]
+	suite.Equal(order.CancelledOrderStatus, expired.Status)
+	suite.Equal(dec(4), expired.Open)
+	suite.Equal(order.UnfilledMarketOrderEventReason, expired.Reason)
+}
+
+func (suite *OrderHistoryTestSuite) TestRejectionsAmendmentsAndCancellations() {
+	dec := lib.NewDecimalFromInt
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "maker", Side: "sell", Price: dec(101), Quantity: dec(10)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "maker", Side: "buy", Price: dec(101), Quantity: dec(4), STPMode: order.DecrementAndCancelSTPMode})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "taker", Side: "buy", Price: dec(101), Quantity: dec(1), ExecInstructions: order.PostOnlyExecInstruction})
+	_, err := suite.matcher.CancelAll(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+
+	suite.Equal([]order.OrderEventType{order.AcceptedOrderEventType, order.AmendedOrderEventType, order.CancelledOrderEventType}, suite.types(1))
+	amended := suite.repository.events[1][1]
+	suite.Equal(order.SelfTradePreventedOrderEventReason, amended.Reason)
+	suite.Equal(dec(4), amended.Quantity)
+	suite.Equal(dec(6), amended.Open)
+	suite.Equal(order.CancelAllOrderEventReason, suite.repository.events[1][2].Reason)
+
+	suite.Equal([]order.OrderEventType{order.AcceptedOrderEventType, order.CancelledOrderEventType}, suite.types(2))
+
+	// the rejected post only order never reached the book, its acceptance was rolled back
+	suite.Equal([]order.OrderEventType{order.RejectedOrderEventType}, suite.types(3))
+	suite.Equal(order.OrderEventReason(order.PostOnlyRejectReason), suite.repository.events[3][0].Reason)
+}
+
+func (suite *OrderHistoryTestSuite) TestTheIcebergReserveIsNeverQueried() {
+	dec := lib.NewDecimalFromInt
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "maker", Side: "sell", Price: dec(101), Quantity: dec(30), DisplayQuantity: dec(10)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "taker", Side: "buy", Price: dec(101), Quantity: dec(4)})
+
+	detail, err := suite.queries.GetOrder(context.Background(), 1)
+	suite.Require().NoError(err)
+	suite.Equal(dec(10), detail.Quantity)
+	suite.Equal(dec(6), detail.Remaining)
+
+	events, err := suite.queries.ListOrderEvents(context.Background(), 1)
+	suite.Require().NoError(err)
+	suite.Require().Len(events, 2)
+	suite.Equal(dec(10), events[0].Remaining)
+	suite.Equal(dec(4), events[1].Quantity)
+	suite.Equal(dec(6), events[1].Remaining)
+	suite.Equal(dec(26), suite.repository.events[1][1].Open)
+}
@@ -2,43 +2,237 @@ package application
 
 import (
 	"context"
+	"io"
+	"time"
 	"tradeTornado/internal/lib"
 	"tradeTornado/internal/modules/order"
 )
 
 type OrderDto struct {
-	Matched   bool
-	Side      string
-	Price     int
-	Quantity  int
-	CreatedAt int64
+	ID           uint
+	AccountID    string
+	Symbol       string
+	Matched      bool
+	Side         string
+	Type         string
+	Price        lib.Decimal
+	TriggerPrice lib.Decimal
+	Quantity     lib.Decimal
+	Remaining    lib.Decimal
+	Status       string
+	CreatedAt    int64
+}
+
+// OrderDetailDto is the public state of an order, the hidden reserve of an iceberg is never exposed
+type OrderDetailDto struct {
+	OrderDto
+	SelfTradePrevention string
+	ExecInstructions    uint32
+	PriorityAt          time.Time
+}
+
+type OrderEventDto struct {
+	ID        uint
+	OrderID   uint
+	Type      string
+	Status    string
+	Price     lib.Decimal
+	Quantity  lib.Decimal
+	Remaining lib.Decimal
+	TradeID   uint
+	Reason    string
+	Message   string
+	CreatedAt time.Time
+}
+
+type PriceLevelDto struct {
+	Price    lib.Decimal
+	Quantity lib.Decimal
+	Orders   int
+}
+
+type DepthDto struct {
+	Symbol string
+	Bids   []*PriceLevelDto
+	Asks   []*PriceLevelDto
+}
+
+type IndicativeAuctionDto struct {
+	Symbol     string
+	Phase      string
+	Price      lib.Decimal
+	Volume     lib.Decimal
+	BuyVolume  lib.Decimal
+	SellVolume lib.Decimal
+	Imbalance  lib.Decimal
 }
 
 type OrderQueryHandler struct {
 	orderRepository order.IOrderReadRepository
+	tradingSessions order.ITradingSessionProvider
 }
 
-func NewOrderQueryHandler(orderRepository order.IOrderReadRepository) *OrderQueryHandler {
-	return &OrderQueryHandler{orderRepository: orderRepository}
+func NewOrderQueryHandler(orderRepository order.IOrderReadRepository, tradingSessions order.ITradingSessionProvider) *OrderQueryHandler {
+	return &OrderQueryHandler{orderRepository: orderRepository, tradingSessions: tradingSessions}
 }
 
-func (cqh *OrderQueryHandler) ListOrders(ctx context.Context, criteria lib.Criteria) ([]*OrderDto, int, error) {
-	orders, total, err := cqh.orderRepository.List(ctx, criteria)
+func (cqh *OrderQueryHandler) ListOrders(ctx context.Context, criteria lib.Criteria) ([]*OrderDto, lib.Page, error) {
+	orders, page, err := cqh.orderRepository.List(ctx, criteria)
 	if err != nil {
-		return nil, 0, err
+		return nil, lib.Page{}, err
 	}
-	return cqh.toDtos(orders...), total, nil
+	return toOrderDtos(orders...), page, nil
+}
+
+// ProjectOrders lists the projected fields or the aggregates of the orders, rows are keyed by field and aggregate names
+func (cqh *OrderQueryHandler) ProjectOrders(ctx context.Context, criteria lib.Criteria) ([]lib.Row, lib.Page, error) {
+	return cqh.orderRepository.Project(ctx, criteria)
+}
+
+// ExportOrders writes the orders of the criteria, or their projection, to w one row at a time. The orders are only
+// paged when the criteria asks for it
+func (cqh *OrderQueryHandler) ExportOrders(ctx context.Context, criteria lib.Criteria, format lib.ExportFormat, w io.Writer) error {
+	columns := lib.ExportColumns(OrderDto{})
+	if criteria.IsProjection() {
+		var err error
+		if columns, err = lib.ProjectedColumns(order.Order{}, &criteria); err != nil {
+			return err
+		}
+	}
+	writer, err := lib.NewRowWriter(format, w, columns)
+	if err != nil {
+		return err
+	}
+	if criteria.IsProjection() {
+		err = cqh.orderRepository.StreamProjection(ctx, criteria, func(row lib.Row) error {
+			return writer.Write(row)
+		})
+	} else {
+		err = cqh.orderRepository.Stream(ctx, criteria, func(om *order.Order) error {
+			return writer.Write(toOrderDtos(om)[0])
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func (cqh *OrderQueryHandler) GetOrder(ctx context.Context, id uint) (*OrderDetailDto, error) {
+	om, err := cqh.orderRepository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderDetailDto{
+		OrderDto:            *toOrderDtos(om)[0],
+		SelfTradePrevention: string(om.SelfTradePrevention),
+		ExecInstructions:    uint32(om.ExecInstructions),
+		PriorityAt:          om.PriorityAt,
+	}, nil
+}
+
+// ListOrderEvents returns the lifecycle of the order oldest first, a rejected order never reached the book and
+// only has its rejection
+func (cqh *OrderQueryHandler) ListOrderEvents(ctx context.Context, id uint) ([]*OrderEventDto, error) {
+	events, err := cqh.orderRepository.ListOrderEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		// orders created before the history was kept have no event
+		if _, err := cqh.orderRepository.Get(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	dtos := make([]*OrderEventDto, 0, len(events))
+	for _, event := range events {
+		dtos = append(dtos, &OrderEventDto{
+			ID:        event.ID,
+			OrderID:   event.OrderID,
+			Type:      string(event.Type),
+			Status:    string(event.Status),
+			Price:     event.Price,
+			Quantity:  event.Quantity,
+			Remaining: event.Remaining,
+			TradeID:   event.TradeID,
+			Reason:    string(event.Reason),
+			Message:   event.Message,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return dtos, nil
+}
+
+// GetDepth aggregates visible quantities of the book, the hidden reserve of iceberg orders is never exposed
+func (cqh *OrderQueryHandler) GetDepth(ctx context.Context, symbol string, levels int) (*DepthDto, error) {
+	bids, err := cqh.orderRepository.Depth(ctx, symbol, order.BuyOrderSide, levels)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := cqh.orderRepository.Depth(ctx, symbol, order.SellOrderSide, levels)
+	if err != nil {
+		return nil, err
+	}
+	return &DepthDto{Symbol: symbol, Bids: toPriceLevelDtos(bids...), Asks: toPriceLevelDtos(asks...)}, nil
+}
+
+// GetIndicativeAuction is the price and volume the book would uncross at if the running auction ended now
+func (cqh *OrderQueryHandler) GetIndicativeAuction(ctx context.Context, symbol string) (*IndicativeAuctionDto, error) {
+	phase, err := cqh.tradingSessions.GetTradingPhase(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if phase != order.AuctionTradingPhase && phase != order.PreOpenTradingPhase {
+		return nil, order.AuctionNotRunning
+	}
+	book, err := cqh.orderRepository.Resting(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	referencePrice, err := cqh.orderRepository.LastTradePrice(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	result := order.ComputeIndicativeEquilibrium(book, referencePrice)
+	return &IndicativeAuctionDto{
+		Symbol:     symbol,
+		Phase:      string(phase),
+		Price:      result.Price,
+		Volume:     result.Volume,
+		BuyVolume:  result.BuyVolume,
+		SellVolume: result.SellVolume,
+		Imbalance:  result.Imbalance,
+	}, nil
+}
+
+func toPriceLevelDtos(levels ...*order.PriceLevel) []*PriceLevelDto {
+	dtos := make([]*PriceLevelDto, 0)
+	for _, level := range levels {
+		dtos = append(dtos, &PriceLevelDto{
+			Price:    level.Price,
+			Quantity: level.Quantity,
+			Orders:   level.Orders,
+		})
+	}
+	return dtos
 }
 
-func (cqh *OrderQueryHandler) toDtos(orders ...*order.Order) []*OrderDto {
+func toOrderDtos(orders ...*order.Order) []*OrderDto {
 	dtos := make([]*OrderDto, 0)
 	for _, ord := range orders {
 		dtos = append(dtos, &OrderDto{
-			Price:     ord.Price,
-			Quantity:  ord.Quantity,
-			Matched:   ord.Matched,
-			Side:      string(ord.Side),
-			CreatedAt: ord.CreatedAt.Unix(),
+			ID:           ord.ID,
+			AccountID:    ord.AccountID,
+			Symbol:       ord.Symbol,
+			Price:        ord.Price,
+			Quantity:     ord.Quantity,
+			Remaining:    ord.Remaining,
+			Status:       string(ord.Status),
+			Matched:      ord.Matched,
+			Side:         string(ord.Side),
+			Type:         string(ord.Type),
+			TriggerPrice: ord.TriggerPrice,
+			CreatedAt:    ord.CreatedAt.Unix(),
 		})
 	}
 	return dtos
@@ -0,0 +1,190 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"tradeTornado/internal/modules/order"
+)
+
+// FeeRates are the maker and taker rates of an account in basis points
+type FeeRates struct {
+	MakerBps int `json:"makerBps"`
+	TakerBps int `json:"takerBps"`
+}
+
+// ReferenceSnapshot is the reference data one command read keyed by symbol or by account, it is journaled with the
+// command so a replay reads the instruments, fees and accounts of the time the command was applied
+type ReferenceSnapshot struct {
+	TradingRules    map[string]order.TradingRules    `json:"tradingRules,omitempty"`
+	RiskLimits      map[string]order.RiskLimits      `json:"riskLimits,omitempty"`
+	VolatilityBands map[string]order.VolatilityBand  `json:"volatilityBands,omitempty"`
+	AllocationRules map[string]order.AllocationRules `json:"allocationRules,omitempty"`
+	STPModes        map[string]order.STPMode         `json:"stpModes,omitempty"`
+	FeeRates        map[string]FeeRates              `json:"feeRates,omitempty"`
+}
+
+func (rs *ReferenceSnapshot) isEmpty() bool {
+	return len(rs.TradingRules) == 0 && len(rs.RiskLimits) == 0 && len(rs.VolatilityBands) == 0 &&
+		len(rs.AllocationRules) == 0 && len(rs.STPModes) == 0 && len(rs.FeeRates) == 0
+}
+
+// referenceRecorder belongs to one command, it reads the reference data of the matcher and keeps what was read
+type referenceRecorder struct {
+	matcher  *OrderMatcher
+	snapshot ReferenceSnapshot
+}
+
+func (rr *referenceRecorder) GetTradingRules(ctx context.Context, symbol string) (order.TradingRules, error) {
+	rules, err := rr.matcher.tradingRules.GetTradingRules(ctx, symbol)
+	if err == nil {
+		rr.snapshot.TradingRules = record(rr.snapshot.TradingRules, symbol, rules)
+	}
+	return rules, err
+}
+
+func (rr *referenceRecorder) GetRiskLimits(ctx context.Context, symbol string) (order.RiskLimits, error) {
+	limits, err := rr.matcher.riskLimits.GetRiskLimits(ctx, symbol)
+	if err == nil {
+		rr.snapshot.RiskLimits = record(rr.snapshot.RiskLimits, symbol, limits)
+	}
+	return limits, err
+}
+
+func (rr *referenceRecorder) GetVolatilityBand(ctx context.Context, symbol string) (order.VolatilityBand, error) {
+	band, err := rr.matcher.volatilityBands.GetVolatilityBand(ctx, symbol)
+	if err == nil {
+		rr.snapshot.VolatilityBands = record(rr.snapshot.VolatilityBands, symbol, band)
+	}
+	return band, err
+}
+
+func (rr *referenceRecorder) GetAllocationRules(ctx context.Context, symbol string) (order.AllocationRules, error) {
+	rules, err := rr.matcher.allocationRules.GetAllocationRules(ctx, symbol)
+	if err == nil {
+		rr.snapshot.AllocationRules = record(rr.snapshot.AllocationRules, symbol, rules)
+	}
+	return rules, err
+}
+
+func (rr *referenceRecorder) GetSTPMode(ctx context.Context, accountID string) (order.STPMode, error) {
+	mode, err := rr.matcher.selfTradePolicy.GetSTPMode(ctx, accountID)
+	if err == nil {
+		rr.snapshot.STPModes = record(rr.snapshot.STPModes, accountID, mode)
+	}
+	return mode, err
+}
+
+func (rr *referenceRecorder) GetFeeRates(ctx context.Context, accountID string) (int, int, error) {
+	makerRateBps, takerRateBps, err := rr.matcher.feeSchedule.GetFeeRates(ctx, accountID)
+	if err == nil {
+		rr.snapshot.FeeRates = record(rr.snapshot.FeeRates, accountID, FeeRates{MakerBps: makerRateBps, TakerBps: takerRateBps})
+	}
+	return makerRateBps, takerRateBps, err
+}
+
+func record[V any](values map[string]V, key string, value V) map[string]V {
+	if values == nil {
+		values = make(map[string]V)
+	}
+	values[key] = value
+	return values
+}
+
+// JournaledReference is the reference data of a replay, every command reads the snapshot it was journaled with.
+// Commands journaled without one, or lookups missing from it, read the current reference data
+type JournaledReference struct {
+	lock            sync.RWMutex
+	snapshot        ReferenceSnapshot
+	feeSchedule     order.IFeeSchedule
+	tradingRules    order.ITradingRulesProvider
+	riskLimits      order.IRiskLimitsProvider
+	selfTradePolicy order.ISelfTradePolicy
+	volatilityBands order.IVolatilityBandProvider
+	allocationRules order.IAllocationRulesProvider
+}
+
+func NewJournaledReference(feeSchedule order.IFeeSchedule, tradingRules order.ITradingRulesProvider, riskLimits order.IRiskLimitsProvider, selfTradePolicy order.ISelfTradePolicy, volatilityBands order.IVolatilityBandProvider, allocationRules order.IAllocationRulesProvider) *JournaledReference {
+	return &JournaledReference{
+		feeSchedule:     feeSchedule,
+		tradingRules:    tradingRules,
+		riskLimits:      riskLimits,
+		selfTradePolicy: selfTradePolicy,
+		volatilityBands: volatilityBands,
+		allocationRules: allocationRules,
+	}
+}
+
+// Load replaces the snapshot with the one journaled with command, an empty reference clears it
+func (jr *JournaledReference) Load(command *order.JournalEntry) error {
+	var snapshot ReferenceSnapshot
+	if command.Reference != "" {
+		if err := json.Unmarshal([]byte(command.Reference), &snapshot); err != nil {
+			return err
+		}
+	}
+	jr.lock.Lock()
+	defer jr.lock.Unlock()
+	jr.snapshot = snapshot
+	return nil
+}
+
+func (jr *JournaledReference) GetTradingRules(ctx context.Context, symbol string) (order.TradingRules, error) {
+	jr.lock.RLock()
+	rules, ok := jr.snapshot.TradingRules[symbol]
+	jr.lock.RUnlock()
+	if ok {
+		return rules, nil
+	}
+	return jr.tradingRules.GetTradingRules(ctx, symbol)
+}
+
+func (jr *JournaledReference) GetRiskLimits(ctx context.Context, symbol string) (order.RiskLimits, error) {
+	jr.lock.RLock()
+	limits, ok := jr.snapshot.RiskLimits[symbol]
+	jr.lock.RUnlock()
+	if ok {
+		return limits, nil
+	}
+	return jr.riskLimits.GetRiskLimits(ctx, symbol)
+}
+
+func (jr *JournaledReference) GetVolatilityBand(ctx context.Context, symbol string) (order.VolatilityBand, error) {
+	jr.lock.RLock()
+	band, ok := jr.snapshot.VolatilityBands[symbol]
+	jr.lock.RUnlock()
+	if ok {
+		return band, nil
+	}
+	return jr.volatilityBands.GetVolatilityBand(ctx, symbol)
+}
+
+func (jr *JournaledReference) GetAllocationRules(ctx context.Context, symbol string) (order.AllocationRules, error) {
+	jr.lock.RLock()
+	rules, ok := jr.snapshot.AllocationRules[symbol]
+	jr.lock.RUnlock()
+	if ok {
+		return rules, nil
+	}
+	return jr.allocationRules.GetAllocationRules(ctx, symbol)
+}
+
+func (jr *JournaledReference) GetSTPMode(ctx context.Context, accountID string) (order.STPMode, error) {
+	jr.lock.RLock()
+	mode, ok := jr.snapshot.STPModes[accountID]
+	jr.lock.RUnlock()
+	if ok {
+		return mode, nil
+	}
+	return jr.selfTradePolicy.GetSTPMode(ctx, accountID)
+}
+
+func (jr *JournaledReference) GetFeeRates(ctx context.Context, accountID string) (int, int, error) {
+	jr.lock.RLock()
+	rates, ok := jr.snapshot.FeeRates[accountID]
+	jr.lock.RUnlock()
+	if ok {
+		return rates.MakerBps, rates.TakerBps, nil
+	}
+	return jr.feeSchedule.GetFeeRates(ctx, accountID)
+}
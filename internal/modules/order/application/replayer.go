@@ -0,0 +1,212 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+
+	"github.com/sirupsen/logrus"
+)
+
+const replayBatchSize = 1000
+
+// JournaledSessions is the trading session of offline matchers, the phase of a symbol is set from the input and a
+// circuit breaker trip is only seen through the phases given afterwards
+type JournaledSessions struct {
+	lock         sync.RWMutex
+	phases       map[string]order.TradingPhase
+	defaultPhase order.TradingPhase
+}
+
+func NewJournaledSessions(defaultPhase order.TradingPhase) *JournaledSessions {
+	return &JournaledSessions{phases: make(map[string]order.TradingPhase), defaultPhase: defaultPhase}
+}
+
+func (js *JournaledSessions) SetTradingPhase(symbol string, phase order.TradingPhase) {
+	js.lock.Lock()
+	defer js.lock.Unlock()
+	js.phases[symbol] = phase
+}
+
+func (js *JournaledSessions) GetTradingPhase(ctx context.Context, symbol string) (order.TradingPhase, error) {
+	js.lock.RLock()
+	defer js.lock.RUnlock()
+	if phase, ok := js.phases[symbol]; ok {
+		return phase, nil
+	}
+	return js.defaultPhase, nil
+}
+
+func (js *JournaledSessions) Trip(ctx context.Context, symbol string) error {
+	return nil
+}
+
+// DetachedBooks is the book replica of offline matchers, their repository already is the book
+type DetachedBooks struct{}
+
+func (DetachedBooks) Apply(context.Context, string, ...*order.Order) {}
+
+func (DetachedBooks) Reset(context.Context, string) {}
+
+// ReplayDivergenceDto is a journaled entry the replay did not produce byte for byte, an empty side was not produced
+type ReplayDivergenceDto struct {
+	Sequence  uint64
+	Type      order.JournalEntryType
+	Journaled string
+	Replayed  string
+}
+
+type ReplayReportDto struct {
+	Commands     int
+	Events       int
+	LastSequence uint64
+	Divergences  []*ReplayDivergenceDto
+	Books        map[string][]*OrderDto
+}
+
+// JournalReplayer runs the journaled commands through a matcher backed by memory and compares what it journals with
+// the journal, every command reads the instruments, fees and accounts it was journaled with
+type JournalReplayer struct {
+	journal    order.IJournalReader
+	repository order.IReplayRepository
+	matcher    *OrderMatcher
+	sessions   *JournaledSessions
+	reference  *JournaledReference
+	clock      *lib.SimulatedClock
+}
+
+// NewJournalReplayer matcher should use repository, sessions, reference and clock
+func NewJournalReplayer(journal order.IJournalReader, repository order.IReplayRepository, matcher *OrderMatcher, sessions *JournaledSessions, reference *JournaledReference, clock *lib.SimulatedClock) *JournalReplayer {
+	return &JournalReplayer{journal: journal, repository: repository, matcher: matcher, sessions: sessions, reference: reference, clock: clock}
+}
+
+// Replay applies the commands up to the until sequence, zero replays the whole journal. The books are the resting
+// orders of every symbol once the last command was applied
+func (jr *JournalReplayer) Replay(ctx context.Context, until uint64) (*ReplayReportDto, error) {
+	report := &ReplayReportDto{Books: make(map[string][]*OrderDto)}
+	for {
+		commands, err := jr.journal.ReadJournal(ctx, report.LastSequence, until, replayBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(commands) == 0 {
+			break
+		}
+		for _, journaled := range commands {
+			if err := jr.apply(ctx, journaled); err != nil {
+				return nil, fmt.Errorf("command %d: %w", journaled.Command.Sequence, err)
+			}
+			report.compare(journaled, jr.repository.TakeJournal())
+			report.Commands++
+			report.Events += len(journaled.Events)
+			report.LastSequence = journaled.Command.Sequence
+		}
+		logrus.WithField("sequence", report.LastSequence).WithField("divergences", len(report.Divergences)).Infoln("journal replayed")
+	}
+	symbols, err := jr.repository.RestingSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range symbols {
+		resting, err := jr.repository.Resting(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		report.Books[symbol] = toOrderDtos(resting...)
+	}
+	return report, nil
+}
+
+func (jr *JournalReplayer) apply(ctx context.Context, journaled *order.JournaledCommand) error {
+	command := journaled.Command
+	jr.clock.Set(command.CreatedAt)
+	if err := jr.reference.Load(command); err != nil {
+		return err
+	}
+	for _, event := range journaled.Events {
+		if event.Type != order.OrderMatchedJournalEntryType {
+			continue
+		}
+		var matched orderMatchEvent
+		if err := json.Unmarshal([]byte(event.Payload), &matched); err != nil {
+			return err
+		}
+		jr.repository.ReserveTradeIDs(matched.TradeID)
+	}
+	return executeJournalCommand(ctx, jr.matcher, jr.sessions, command)
+}
+
+// executeJournalCommand runs a journaled command on an offline matcher, an empty phase keeps the current one
+func executeJournalCommand(ctx context.Context, matcher *OrderMatcher, sessions *JournaledSessions, command *order.JournalEntry) error {
+	switch command.Type {
+	case order.CreateOrderJournalEntryType:
+		var create createOrderJournal
+		if err := json.Unmarshal([]byte(command.Payload), &create); err != nil {
+			return err
+		}
+		if create.Phase != "" {
+			sessions.SetTradingPhase(command.Symbol, create.Phase)
+		}
+		return matcher.Submit(ctx, create.SubmitOrderCommand)
+	case order.CancelOrderJournalEntryType:
+		var cancel CancelOrderCommand
+		if err := json.Unmarshal([]byte(command.Payload), &cancel); err != nil {
+			return err
+		}
+		return matcher.Cancel(ctx, cancel)
+	case order.AmendOrderJournalEntryType:
+		var amend amendOrderJournal
+		if err := json.Unmarshal([]byte(command.Payload), &amend); err != nil {
+			return err
+		}
+		if amend.Phase != "" {
+			sessions.SetTradingPhase(command.Symbol, amend.Phase)
+		}
+		return matcher.Amend(ctx, amend.AmendOrderCommand)
+	case order.UncrossJournalEntryType:
+		var uncross uncrossJournal
+		if err := json.Unmarshal([]byte(command.Payload), &uncross); err != nil {
+			return err
+		}
+		var tripped *order.CircuitBreakerTripped
+		if err := matcher.Uncross(ctx, uncross.Symbol, uncross.Next, nil); err != nil && !errors.As(err, &tripped) {
+			return err
+		}
+		return nil
+	case order.CancelAllJournalEntryType:
+		var cancelAll cancelAllJournal
+		if err := json.Unmarshal([]byte(command.Payload), &cancelAll); err != nil {
+			return err
+		}
+		_, err := matcher.CancelAll(ctx, cancelAll.Symbol)
+		return err
+	}
+	return fmt.Errorf("unknown journal command %q", command.Type)
+}
+
+// compare expects the replay of one command to journal the same command followed by the same events
+func (rr *ReplayReportDto) compare(journaled *order.JournaledCommand, replayed []*order.JournaledCommand) {
+	expected := append([]*order.JournalEntry{journaled.Command}, journaled.Events...)
+	var actual []*order.JournalEntry
+	for _, command := range replayed {
+		actual = append(actual, command.Command)
+		actual = append(actual, command.Events...)
+	}
+	for i := 0; i < max(len(expected), len(actual)); i++ {
+		divergence := &ReplayDivergenceDto{Sequence: journaled.Command.Sequence}
+		if i < len(expected) {
+			divergence.Sequence, divergence.Type, divergence.Journaled = expected[i].Sequence, expected[i].Type, expected[i].Payload
+		}
+		if i < len(actual) {
+			divergence.Type, divergence.Replayed = actual[i].Type, actual[i].Payload
+		}
+		if i < len(expected) && i < len(actual) && expected[i].Type == actual[i].Type && expected[i].Payload == actual[i].Payload {
+			continue
+		}
+		rr.Divergences = append(rr.Divergences, divergence)
+	}
+}
@@ -0,0 +1,226 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/modules/order/infrastructure"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// referenceData answers every lookup of the matcher with the defaults, every account pays 10 bps as taker
+type referenceData struct{}
+
+func (referenceData) GetFeeRates(context.Context, string) (int, int, error) {
+	return 0, 10, nil
+}
+
+func (referenceData) GetTradingRules(context.Context, string) (order.TradingRules, error) {
+	return order.DefaultTradingRules(), nil
+}
+
+func (referenceData) GetRiskLimits(context.Context, string) (order.RiskLimits, error) {
+	return order.RiskLimits{}, nil
+}
+
+func (referenceData) GetSTPMode(context.Context, string) (order.STPMode, error) {
+	return order.NoneSTPMode, nil
+}
+
+func (referenceData) GetVolatilityBand(context.Context, string) (order.VolatilityBand, error) {
+	return order.VolatilityBand{}, nil
+}
+
+func (referenceData) GetAllocationRules(context.Context, string) (order.AllocationRules, error) {
+	return order.DefaultAllocationRules(), nil
+}
+
+// raisedFees is referenceData once every taker pays 20 bps
+type raisedFees struct {
+	referenceData
+}
+
+func (raisedFees) GetFeeRates(context.Context, string) (int, int, error) {
+	return 0, 20, nil
+}
+
+// matcherReference answers every lookup of the matcher
+type matcherReference interface {
+	order.IFeeSchedule
+	order.ITradingRulesProvider
+	order.IRiskLimitsProvider
+	order.ISelfTradePolicy
+	order.IVolatilityBandProvider
+	order.IAllocationRulesProvider
+}
+
+// journalSlice reads a journal taken from a memory repository
+type journalSlice []*order.JournaledCommand
+
+func (js journalSlice) ReadJournal(_ context.Context, after, until uint64, limit int) ([]*order.JournaledCommand, error) {
+	var commands []*order.JournaledCommand
+	for _, command := range js {
+		if command.Command.Sequence > after && (until == 0 || command.Command.Sequence <= until) && len(commands) < limit {
+			commands = append(commands, command)
+		}
+	}
+	return commands, nil
+}
+
+func (js journalSlice) LastJournalSequence(context.Context) (uint64, error) {
+	if len(js) == 0 {
+		return 0, nil
+	}
+	return js[len(js)-1].Command.Sequence, nil
+}
+
+type ReplayerTestSuite struct {
+	suite.Suite
+	repository *infrastructure.MemoryOrderRepository
+	sessions   *application.JournaledSessions
+	clock      *lib.SimulatedClock
+	matcher    *application.OrderMatcher
+}
+
+func TestReplayerTestSuite(t *testing.T) {
+	suite.Run(t, new(ReplayerTestSuite))
+}
+
+func (suite *ReplayerTestSuite) SetupTest() {
+	suite.repository, suite.sessions, suite.clock, suite.matcher = newOfflineMatcher(referenceData{})
+}
+
+func newOfflineMatcher(reference matcherReference) (*infrastructure.MemoryOrderRepository, *application.JournaledSessions, *lib.SimulatedClock, *application.OrderMatcher) {
+	repository := infrastructure.NewMemoryOrderRepository()
+	sessions := application.NewJournaledSessions(order.ContinuousTradingPhase)
+	clock := lib.NewSimulatedClock(time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC))
+	matcher := application.NewOrderMatcher(provider.DiscardProducer{}, application.OrderEventTopics{},
+		func() order.OrderTransaction {
+			return order.OrderTransaction{Orders: repository, CircuitBreaker: sessions}
+		},
+		reference, "fees", reference, reference, order.DefaultRiskChain(), reference,
+		sessions, reference, reference, application.DetachedBooks{}, clock)
+	return repository, sessions, clock, matcher
+}
+
+func (suite *ReplayerTestSuite) submit(cmd application.SubmitOrderCommand) {
+	suite.clock.Set(suite.clock.Now().Add(time.Second))
+	cmd.Symbol = "BTC-USD"
+	suite.Require().NoError(suite.matcher.Submit(context.Background(), cmd))
+}
+
+func (suite *ReplayerTestSuite) record() journalSlice {
+	dec := lib.NewDecimalFromInt
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "maker", Side: "sell", Price: dec(101), Quantity: dec(10)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "maker", Side: "sell", Price: dec(102), Quantity: dec(30), DisplayQuantity: dec(10)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "stop", Side: "buy", Type: "stop", TriggerPrice: dec(102), Quantity: dec(5)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 4, AccountID: "taker", Side: "buy", Price: dec(102), Quantity: dec(15)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 5, AccountID: "maker", Side: "buy", Price: dec(102), Quantity: dec(1), STPMode: order.CancelNewestSTPMode})
+	suite.submit(application.SubmitOrderCommand{OrderID: 6, AccountID: "taker", Side: "buy", Price: dec(103), Quantity: dec(1), ExecInstructions: order.PostOnlyExecInstruction})
+	suite.submit(application.SubmitOrderCommand{OrderID: 7, AccountID: "taker", Side: "buy", Price: dec(0), Quantity: dec(1)})
+	suite.sessions.SetTradingPhase("BTC-USD", order.HaltedTradingPhase)
+	suite.submit(application.SubmitOrderCommand{OrderID: 8, AccountID: "taker", Side: "buy", Price: dec(99), Quantity: dec(1)})
+	_, err := suite.matcher.CancelAll(context.Background(), "BTC-USD")
+	suite.Require().NoError(err)
+	return suite.repository.TakeJournal()
+}
+
+func (suite *ReplayerTestSuite) replay(journal journalSlice, until uint64) *application.ReplayReportDto {
+	return suite.replayWith(referenceData{}, journal, until)
+}
+
+// replayWith replays the journal with current as the current reference data
+func (suite *ReplayerTestSuite) replayWith(current matcherReference, journal journalSlice, until uint64) *application.ReplayReportDto {
+	reference := application.NewJournaledReference(current, current, current, current, current, current)
+	repository, sessions, clock, matcher := newOfflineMatcher(reference)
+	report, err := application.NewJournalReplayer(journal, repository, matcher, sessions, reference, clock).Replay(context.Background(), until)
+	suite.Require().NoError(err)
+	return report
+}
+
+func (suite *ReplayerTestSuite) TestReplayProducesTheJournaledEvents() {
+	journal := suite.record()
+	suite.Len(journal, 9)
+	events := 0
+	for _, command := range journal {
+		events += len(command.Events)
+	}
+	suite.Greater(events, 4)
+
+	report := suite.replay(journal, 0)
+	suite.Empty(report.Divergences)
+	suite.Equal(9, report.Commands)
+	suite.Equal(events, report.Events)
+	suite.Empty(report.Books)
+}
+
+func (suite *ReplayerTestSuite) TestReplayReportsDivergedEvents() {
+	journal := suite.record()
+	matched := journal[3].Events[0]
+	suite.Equal(order.OrderMatchedJournalEntryType, matched.Type)
+	payload := matched.Payload
+	matched.Payload = payload[:len(payload)-1] + " }"
+
+	report := suite.replay(journal, 0)
+	suite.Require().Len(report.Divergences, 1)
+	suite.Equal(matched.Sequence, report.Divergences[0].Sequence)
+	suite.Equal(payload, report.Divergences[0].Replayed)
+}
+
+func (suite *ReplayerTestSuite) TestReplayReadsTheJournaledReferenceData() {
+	journal := suite.record()
+	suite.Contains(journal[3].Command.Reference, `"taker":{"makerBps":0,"takerBps":10}`)
+	suite.Empty(suite.replayWith(raisedFees{}, journal, 0).Divergences)
+
+	for _, command := range journal {
+		command.Command.Reference = ""
+	}
+	suite.NotEmpty(suite.replayWith(raisedFees{}, journal, 0).Divergences)
+}
+
+func (suite *ReplayerTestSuite) TestReplayUntilRebuildsTheHistoricalBook() {
+	journal := suite.record()
+
+	report := suite.replay(journal, journal[2].Command.Sequence)
+	suite.Empty(report.Divergences)
+	book := report.Books["BTC-USD"]
+	suite.Require().Len(book, 2)
+	suite.Equal(uint(1), book[0].ID)
+	suite.Equal(uint(2), book[1].ID)
+}
+
+func (suite *ReplayerTestSuite) TestCancelsAndAmendmentsAreReplayed() {
+	dec := lib.NewDecimalFromInt
+	suite.submit(application.SubmitOrderCommand{OrderID: 1, AccountID: "maker", Side: "sell", Price: dec(101), Quantity: dec(10)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 2, AccountID: "maker", Side: "sell", Price: dec(102), Quantity: dec(10)})
+	suite.submit(application.SubmitOrderCommand{OrderID: 3, AccountID: "taker", Side: "buy", Price: dec(100), Quantity: dec(4)})
+	suite.clock.Set(suite.clock.Now().Add(time.Second))
+	suite.Require().NoError(suite.matcher.Amend(context.Background(), application.AmendOrderCommand{OrderID: 3, Symbol: "BTC-USD", Price: dec(101), Quantity: dec(6)}))
+	suite.Require().NoError(suite.matcher.Amend(context.Background(), application.AmendOrderCommand{OrderID: 2, Symbol: "BTC-USD", Price: dec(102), Quantity: dec(0)}))
+	suite.Require().NoError(suite.matcher.Cancel(context.Background(), application.CancelOrderCommand{OrderID: 1, Symbol: "BTC-USD"}))
+	suite.Require().NoError(suite.matcher.Cancel(context.Background(), application.CancelOrderCommand{OrderID: 3, Symbol: "BTC-USD"}))
+	journal := suite.repository.TakeJournal()
+
+	suite.Require().Len(journal, 7)
+	suite.Equal(order.AmendOrderJournalEntryType, journal[3].Command.Type)
+	suite.Require().Len(journal[3].Events, 1)
+	suite.Equal(order.OrderMatchedJournalEntryType, journal[3].Events[0].Type)
+	suite.Require().Len(journal[4].Events, 1)
+	suite.Equal(order.OrderRejectedJournalEntryType, journal[4].Events[0].Type)
+	suite.Equal(order.CancelOrderJournalEntryType, journal[5].Command.Type)
+	suite.Equal(order.CancelOrderJournalEntryType, journal[6].Command.Type)
+	suite.Empty(journal[6].Events)
+
+	report := suite.replay(journal, 0)
+	suite.Empty(report.Divergences)
+	book := report.Books["BTC-USD"]
+	suite.Require().Len(book, 1)
+	suite.Equal(uint(2), book[0].ID)
+	suite.Equal(dec(10), book[0].Remaining)
+}
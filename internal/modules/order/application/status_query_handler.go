@@ -0,0 +1,30 @@
+package application
+
+import (
+	"time"
+	"tradeTornado/internal/service"
+)
+
+type ILeadership interface {
+	Status() service.LeadershipStatus
+}
+
+type MatcherStatusDto struct {
+	Role  service.LeadershipRole
+	Since time.Time
+	Books []*BookSummaryDto
+}
+
+type MatcherStatusQueryHandler struct {
+	leadership ILeadership
+	books      *BookRegistry
+}
+
+func NewMatcherStatusQueryHandler(leadership ILeadership, books *BookRegistry) *MatcherStatusQueryHandler {
+	return &MatcherStatusQueryHandler{leadership: leadership, books: books}
+}
+
+func (msq *MatcherStatusQueryHandler) GetStatus() *MatcherStatusDto {
+	status := msq.leadership.Status()
+	return &MatcherStatusDto{Role: status.Role, Since: status.Since, Books: msq.books.Summaries()}
+}
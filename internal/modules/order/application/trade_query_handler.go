@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+)
+
+const (
+	defaultTradePageSize = 100
+	maxTradePageSize     = 1000
+)
+
+// ListTradesQuery OrderID and AccountID match trades where the order or account was either the taker or the maker
+type ListTradesQuery struct {
+	Criteria  lib.Criteria
+	OrderID   uint
+	AccountID string
+}
+
+type TradeDto struct {
+	ID             uint
+	Symbol         string
+	TakerOrderID   uint
+	MakerOrderID   uint
+	TakerAccountID string
+	MakerAccountID string
+	TakerSide      string
+	Price          lib.Decimal
+	Quantity       lib.Decimal
+	TakerFee       lib.Decimal
+	MakerFee       lib.Decimal
+	CreatedAt      time.Time
+}
+
+type TradeQueryHandler struct {
+	tradeRepository order.ITradeReadRepository
+}
+
+func NewTradeQueryHandler(tradeRepository order.ITradeReadRepository) *TradeQueryHandler {
+	return &TradeQueryHandler{tradeRepository: tradeRepository}
+}
+
+// ListTrades pages the newest trades first unless sorts are given, a page has 100 trades by default
+func (tqh *TradeQueryHandler) ListTrades(ctx context.Context, query ListTradesQuery) ([]*TradeDto, lib.Page, error) {
+	criteria := query.Criteria
+	if criteria.Pagination == nil {
+		criteria.SetPagination(lib.NewPagination(0, defaultTradePageSize))
+	}
+	if criteria.Pagination.Limit > maxTradePageSize {
+		validation := lib.NewErrorNotification()
+		validation.Add("limit", fmt.Errorf("should be less than or equal to %d", maxTradePageSize))
+		return nil, lib.Page{}, validation
+	}
+	sortNewestFirst(&criteria)
+	trades, page, err := tqh.tradeRepository.List(ctx, criteria, query.OrderID, query.AccountID)
+	if err != nil {
+		return nil, lib.Page{}, err
+	}
+	return toTradeDtos(trades...), page, nil
+}
+
+// ExportTrades writes every trade of the query to w one at a time, newest first unless sorts are given. The trades are
+// only paged when the criteria asks for it
+func (tqh *TradeQueryHandler) ExportTrades(ctx context.Context, query ListTradesQuery, format lib.ExportFormat, w io.Writer) error {
+	criteria := query.Criteria
+	sortNewestFirst(&criteria)
+	writer, err := lib.NewRowWriter(format, w, lib.ExportColumns(TradeDto{}))
+	if err != nil {
+		return err
+	}
+	err = tqh.tradeRepository.Stream(ctx, criteria, query.OrderID, query.AccountID, func(trade *order.Trade) error {
+		return writer.Write(toTradeDtos(trade)[0])
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func sortNewestFirst(criteria *lib.Criteria) {
+	if len(criteria.Sorts) == 0 {
+		criteria.AddSort(lib.NewSort("created_at", lib.DESC))
+		criteria.AddSort(lib.NewSort("id", lib.DESC))
+	}
+}
+
+func toTradeDtos(trades ...*order.Trade) []*TradeDto {
+	dtos := make([]*TradeDto, 0)
+	for _, trade := range trades {
+		dtos = append(dtos, &TradeDto{
+			ID:             trade.ID,
+			Symbol:         trade.Symbol,
+			TakerOrderID:   trade.TakerOrderID,
+			MakerOrderID:   trade.MakerOrderID,
+			TakerAccountID: trade.TakerAccountID,
+			MakerAccountID: trade.MakerAccountID,
+			TakerSide:      string(trade.TakerSide),
+			Price:          trade.Price,
+			Quantity:       trade.Quantity,
+			TakerFee:       trade.TakerFee,
+			MakerFee:       trade.MakerFee,
+			CreatedAt:      trade.CreatedAt,
+		})
+	}
+	return dtos
+}
@@ -0,0 +1,111 @@
+package application_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// tradeLister keeps the last listing it was asked for
+type tradeLister struct {
+	criteria  lib.Criteria
+	orderID   uint
+	accountID string
+	trades    []*order.Trade
+}
+
+func (tl *tradeLister) List(_ context.Context, cr lib.Criteria, orderID uint, accountID string) ([]*order.Trade, lib.Page, error) {
+	tl.criteria, tl.orderID, tl.accountID = cr, orderID, accountID
+	return tl.trades, lib.Page{Total: len(tl.trades)}, nil
+}
+
+func (tl *tradeLister) Stream(_ context.Context, cr lib.Criteria, orderID uint, accountID string, each func(trade *order.Trade) error) error {
+	tl.criteria, tl.orderID, tl.accountID = cr, orderID, accountID
+	for _, trade := range tl.trades {
+		if err := each(trade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type TradeQueryHandlerTestSuite struct {
+	suite.Suite
+	repository *tradeLister
+	handler    *application.TradeQueryHandler
+}
+
+func TestTradeQueryHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TradeQueryHandlerTestSuite))
+}
+
+func (suite *TradeQueryHandlerTestSuite) SetupTest() {
+	suite.repository = &tradeLister{}
+	suite.handler = application.NewTradeQueryHandler(suite.repository)
+}
+
+func (suite *TradeQueryHandlerTestSuite) TestListTradesPagesNewestFirstByDefault() {
+	at := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	suite.repository.trades = []*order.Trade{{ID: 7, Symbol: "BTC-USD", TakerSide: order.BuyOrderSide, Price: lib.NewDecimalFromInt(101), CreatedAt: at}}
+
+	trades, page, err := suite.handler.ListTrades(context.Background(), application.ListTradesQuery{Criteria: *lib.NewCriteria(), OrderID: 3, AccountID: "maker"})
+	suite.Require().NoError(err)
+	suite.Equal(1, page.Total)
+	suite.Require().Len(trades, 1)
+	suite.Equal(uint(7), trades[0].ID)
+	suite.Equal("buy", trades[0].TakerSide)
+	suite.Equal(at, trades[0].CreatedAt)
+
+	suite.Equal(uint(3), suite.repository.orderID)
+	suite.Equal("maker", suite.repository.accountID)
+	suite.Equal(lib.NewPagination(0, 100), suite.repository.criteria.Pagination)
+	suite.Equal([]lib.Sort{lib.NewSort("created_at", lib.DESC), lib.NewSort("id", lib.DESC)}, suite.repository.criteria.Sorts)
+}
+
+func (suite *TradeQueryHandlerTestSuite) TestListTradesKeepsTheRequestedPageAndSorts() {
+	criteria := lib.NewCriteria()
+	criteria.SetPagination(lib.NewPagination(20, 10))
+	criteria.AddSort(lib.NewSort("price", lib.ASC))
+
+	_, _, err := suite.handler.ListTrades(context.Background(), application.ListTradesQuery{Criteria: *criteria})
+	suite.Require().NoError(err)
+	suite.Equal(lib.NewPagination(20, 10), suite.repository.criteria.Pagination)
+	suite.Equal([]lib.Sort{lib.NewSort("price", lib.ASC)}, suite.repository.criteria.Sorts)
+
+	criteria.SetPagination(lib.NewPagination(0, 5000))
+	_, _, err = suite.handler.ListTrades(context.Background(), application.ListTradesQuery{Criteria: *criteria})
+	var notification *lib.ErrorNotification
+	suite.ErrorAs(err, &notification)
+}
+
+func (suite *TradeQueryHandlerTestSuite) TestExportTradesWritesEveryTradeAsARow() {
+	at := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	suite.repository.trades = []*order.Trade{
+		{ID: 8, Symbol: "BTC-USD", TakerSide: order.SellOrderSide, Price: lib.NewDecimalFromInt(100), Quantity: lib.NewDecimalFromInt(2), CreatedAt: at.Add(time.Second)},
+		{ID: 7, Symbol: "BTC-USD", TakerSide: order.BuyOrderSide, Price: lib.NewDecimalFromInt(101), Quantity: lib.NewDecimalFromInt(1), CreatedAt: at},
+	}
+	query := application.ListTradesQuery{Criteria: *lib.NewCriteria(), AccountID: "maker"}
+
+	var csv bytes.Buffer
+	suite.Require().NoError(suite.handler.ExportTrades(context.Background(), query, lib.CSVExportFormat, &csv))
+	suite.Equal("ID,Symbol,TakerOrderID,MakerOrderID,TakerAccountID,MakerAccountID,TakerSide,Price,Quantity,TakerFee,MakerFee,CreatedAt\n"+
+		"8,BTC-USD,0,0,,,sell,100,2,0,0,2026-01-02T09:00:01Z\n"+
+		"7,BTC-USD,0,0,,,buy,101,1,0,0,2026-01-02T09:00:00Z\n", csv.String())
+	suite.Nil(suite.repository.criteria.Pagination)
+	suite.Equal([]lib.Sort{lib.NewSort("created_at", lib.DESC), lib.NewSort("id", lib.DESC)}, suite.repository.criteria.Sorts)
+	suite.Equal("maker", suite.repository.accountID)
+
+	var ndjson bytes.Buffer
+	suite.Require().NoError(suite.handler.ExportTrades(context.Background(), query, lib.NDJSONExportFormat, &ndjson))
+	lines := bytes.Split(bytes.TrimSpace(ndjson.Bytes()), []byte("\n"))
+	suite.Require().Len(lines, 2)
+	suite.Contains(string(lines[1]), `"ID":7,`)
+	suite.Contains(string(lines[1]), `"Price":101,`)
+}
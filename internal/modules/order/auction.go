@@ -0,0 +1,159 @@
+package order
+
+import (
+	"slices"
+	"sort"
+	"tradeTornado/internal/lib"
+)
+
+// AuctionResult is the outcome of uncrossing a call book, Volume is zero when nothing crosses
+type AuctionResult struct {
+	Price      lib.Decimal
+	Volume     lib.Decimal
+	BuyVolume  lib.Decimal
+	SellVolume lib.Decimal
+	// Imbalance is the surplus at Price, positive on the buy side and negative on the sell side
+	Imbalance lib.Decimal
+}
+
+// AuctionFill is the quantity executed between a buy and a sell order at the auction price
+type AuctionFill struct {
+	Buy      *Order
+	Sell     *Order
+	Quantity lib.Decimal
+}
+
+// TakerMaker names the order that arrived last the taker, auctions have no aggressor but fees need one
+func (af AuctionFill) TakerMaker() (taker, maker *Order) {
+	if af.Buy.PriorityAt.After(af.Sell.PriorityAt) || (af.Buy.PriorityAt.Equal(af.Sell.PriorityAt) && af.Buy.ID > af.Sell.ID) {
+		return af.Buy, af.Sell
+	}
+	return af.Sell, af.Buy
+}
+
+// ComputeEquilibrium finds the uncrossing price of the resting orders, the price executing the most volume wins,
+// ties go to the smallest imbalance, then to the price closest to referencePrice and then to the lowest price.
+// Hidden iceberg reserve takes part in the uncross
+func ComputeEquilibrium(book []*Order, referencePrice lib.Decimal) AuctionResult {
+	return computeEquilibrium(book, referencePrice, (*Order).Open)
+}
+
+// ComputeIndicativeEquilibrium is the equilibrium published during the call phase, it only counts the visible
+// remaining so the indicative volumes never reveal hidden iceberg reserve
+func ComputeIndicativeEquilibrium(book []*Order, referencePrice lib.Decimal) AuctionResult {
+	return computeEquilibrium(book, referencePrice, func(om *Order) lib.Decimal { return om.Remaining })
+}
+
+func computeEquilibrium(book []*Order, referencePrice lib.Decimal, quantity func(om *Order) lib.Decimal) AuctionResult {
+	var best AuctionResult
+	for _, price := range auctionCandidatePrices(book, referencePrice) {
+		candidate := AuctionResult{Price: price}
+		for _, om := range book {
+			if !om.IsResting() || !om.Crosses(price) {
+				continue
+			}
+			if om.Side == BuyOrderSide {
+				candidate.BuyVolume += quantity(om)
+			} else {
+				candidate.SellVolume += quantity(om)
+			}
+		}
+		candidate.Volume = min(candidate.BuyVolume, candidate.SellVolume)
+		candidate.Imbalance = candidate.BuyVolume - candidate.SellVolume
+		if candidate.Volume > 0 && candidate.betterThan(best, referencePrice) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// AllocateAuction pairs crossing orders in price-time priority until the auction volume is executed,
+// market orders come first on both sides
+func AllocateAuction(book []*Order, result AuctionResult) []AuctionFill {
+	var buys, sells []*Order
+	for _, om := range book {
+		if !om.IsResting() || !om.Crosses(result.Price) {
+			continue
+		}
+		if om.Side == BuyOrderSide {
+			buys = append(buys, om)
+		} else {
+			sells = append(sells, om)
+		}
+	}
+	sortAuctionSide(buys)
+	sortAuctionSide(sells)
+	var fills []AuctionFill
+	buyOpen, sellOpen := make([]lib.Decimal, len(buys)), make([]lib.Decimal, len(sells))
+	for i, om := range buys {
+		buyOpen[i] = om.Open()
+	}
+	for i, om := range sells {
+		sellOpen[i] = om.Open()
+	}
+	volume := result.Volume
+	for b, s := 0, 0; volume > 0 && b < len(buys) && s < len(sells); {
+		quantity := min(volume, buyOpen[b], sellOpen[s])
+		fills = append(fills, AuctionFill{Buy: buys[b], Sell: sells[s], Quantity: quantity})
+		volume -= quantity
+		buyOpen[b] -= quantity
+		sellOpen[s] -= quantity
+		if buyOpen[b] == 0 {
+			b++
+		}
+		if sellOpen[s] == 0 {
+			s++
+		}
+	}
+	return fills
+}
+
+func (ar AuctionResult) betterThan(other AuctionResult, referencePrice lib.Decimal) bool {
+	if ar.Volume != other.Volume {
+		return ar.Volume > other.Volume
+	}
+	if ar.Imbalance.Abs() != other.Imbalance.Abs() {
+		return ar.Imbalance.Abs() < other.Imbalance.Abs()
+	}
+	if referencePrice > 0 && (ar.Price-referencePrice).Abs() != (other.Price-referencePrice).Abs() {
+		return (ar.Price - referencePrice).Abs() < (other.Price - referencePrice).Abs()
+	}
+	return ar.Price < other.Price
+}
+
+// auctionCandidatePrices are the limit prices of the book, the reference price is the only candidate
+// when just market orders are resting
+func auctionCandidatePrices(book []*Order, referencePrice lib.Decimal) []lib.Decimal {
+	seen := make(map[lib.Decimal]bool)
+	var prices []lib.Decimal
+	for _, om := range book {
+		if om.IsResting() && om.Type.HasLimitPrice() && !seen[om.Price] {
+			seen[om.Price] = true
+			prices = append(prices, om.Price)
+		}
+	}
+	if len(prices) == 0 && referencePrice > 0 {
+		prices = append(prices, referencePrice)
+	}
+	slices.Sort(prices)
+	return prices
+}
+
+func sortAuctionSide(orders []*Order) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		a, b := orders[i], orders[j]
+		if a.Type.HasLimitPrice() != b.Type.HasLimitPrice() {
+			return !a.Type.HasLimitPrice()
+		}
+		if a.Price != b.Price {
+			if a.Side == BuyOrderSide {
+				return a.Price > b.Price
+			}
+			return a.Price < b.Price
+		}
+		if !a.PriorityAt.Equal(b.PriorityAt) {
+			return a.PriorityAt.Before(b.PriorityAt)
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,111 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuctionTestSuite struct {
+	suite.Suite
+	nextID uint
+}
+
+func TestAuctionTestSuite(t *testing.T) {
+	suite.Run(t, new(AuctionTestSuite))
+}
+
+func (suite *AuctionTestSuite) SetupTest() {
+	suite.nextID = 0
+}
+
+func (suite *AuctionTestSuite) newOrder(side OrderSide, orderType OrderType, price, quantity int64) *Order {
+	suite.nextID++
+	om, err := NewOrder(suite.nextID, "account", "BTC-USD", string(side), string(orderType), dec(price), 0, dec(quantity), time.Unix(int64(suite.nextID), 0))
+	suite.Require().NoError(err)
+	return om
+}
+
+func (suite *AuctionTestSuite) TestMaximizesExecutableVolume() {
+	book := []*Order{
+		suite.newOrder(BuyOrderSide, LimitOrderType, 102, 10),
+		suite.newOrder(BuyOrderSide, LimitOrderType, 101, 5),
+		suite.newOrder(BuyOrderSide, LimitOrderType, 99, 10),
+		suite.newOrder(SellOrderSide, LimitOrderType, 98, 5),
+		suite.newOrder(SellOrderSide, LimitOrderType, 100, 10),
+		suite.newOrder(SellOrderSide, LimitOrderType, 103, 10),
+	}
+	result := ComputeEquilibrium(book, dec(0))
+	suite.Equal(dec(100), result.Price)
+	suite.Equal(dec(15), result.Volume)
+	suite.Equal(dec(0), result.Imbalance)
+}
+
+func (suite *AuctionTestSuite) TestTieBreaksOnImbalanceThenReferencePrice() {
+	book := []*Order{
+		suite.newOrder(BuyOrderSide, LimitOrderType, 105, 10),
+		suite.newOrder(SellOrderSide, LimitOrderType, 95, 10),
+	}
+	suite.Equal(dec(95), ComputeEquilibrium(book, dec(0)).Price)
+	suite.Equal(dec(105), ComputeEquilibrium(book, dec(104)).Price)
+
+	book = append(book, suite.newOrder(BuyOrderSide, LimitOrderType, 95, 4))
+	result := ComputeEquilibrium(book, dec(104))
+	suite.Equal(dec(105), result.Price)
+	suite.Equal(dec(10), result.Volume)
+	suite.Equal(dec(0), result.Imbalance)
+}
+
+func (suite *AuctionTestSuite) TestMarketOrdersUseReferencePrice() {
+	book := []*Order{
+		suite.newOrder(BuyOrderSide, MarketOrderType, 0, 3),
+		suite.newOrder(SellOrderSide, MarketOrderType, 0, 5),
+	}
+	result := ComputeEquilibrium(book, dec(100))
+	suite.Equal(dec(100), result.Price)
+	suite.Equal(dec(3), result.Volume)
+	suite.Equal(dec(-2), result.Imbalance)
+	suite.Equal(AuctionResult{}, ComputeEquilibrium(book, dec(0)))
+}
+
+func (suite *AuctionTestSuite) TestNoCrossLeavesBookUntouched() {
+	book := []*Order{
+		suite.newOrder(BuyOrderSide, LimitOrderType, 99, 10),
+		suite.newOrder(SellOrderSide, LimitOrderType, 100, 10),
+	}
+	result := ComputeEquilibrium(book, dec(0))
+	suite.Equal(dec(0), result.Volume)
+	suite.Empty(AllocateAuction(book, result))
+}
+
+func (suite *AuctionTestSuite) TestAllocatesInPriceTimePriority() {
+	market := suite.newOrder(SellOrderSide, MarketOrderType, 0, 4)
+	early := suite.newOrder(BuyOrderSide, LimitOrderType, 100, 5)
+	late := suite.newOrder(BuyOrderSide, LimitOrderType, 100, 5)
+	better := suite.newOrder(BuyOrderSide, LimitOrderType, 101, 2)
+	limit := suite.newOrder(SellOrderSide, LimitOrderType, 100, 4)
+	book := []*Order{market, early, late, better, limit}
+
+	result := ComputeEquilibrium(book, dec(0))
+	suite.Equal(dec(100), result.Price)
+	suite.Equal(dec(8), result.Volume)
+
+	fills := AllocateAuction(book, result)
+	suite.Equal([]AuctionFill{
+		{Buy: better, Sell: market, Quantity: dec(2)},
+		{Buy: early, Sell: market, Quantity: dec(2)},
+		{Buy: early, Sell: limit, Quantity: dec(3)},
+		{Buy: late, Sell: limit, Quantity: dec(1)},
+	}, fills)
+
+	taker, maker := fills[0].TakerMaker()
+	suite.Equal(better, taker)
+	suite.Equal(market, maker)
+}
+
+func dec(v int64) lib.Decimal {
+	return lib.NewDecimalFromInt(v)
+}
@@ -0,0 +1,48 @@
+package order
+
+import "tradeTornado/internal/lib"
+
+// Book is the in-memory copy of the resting orders of a symbol, the database stays the source of truth
+type Book struct {
+	Symbol string
+	orders map[uint]*Order
+}
+
+func NewBook(symbol string, resting []*Order) *Book {
+	book := &Book{Symbol: symbol, orders: make(map[uint]*Order, len(resting))}
+	book.Apply(resting...)
+	return book
+}
+
+// Apply stores the latest state of orders, orders that no longer rest leave the book
+func (b *Book) Apply(orders ...*Order) {
+	for _, om := range orders {
+		if om.Symbol != b.Symbol {
+			continue
+		}
+		if !om.IsResting() {
+			delete(b.orders, om.ID)
+			continue
+		}
+		copied := *om
+		b.orders[om.ID] = &copied
+	}
+}
+
+func (b *Book) Len() int {
+	return len(b.orders)
+}
+
+// BestPrice is zero when the side is empty, market orders waiting for an uncross are not priced
+func (b *Book) BestPrice(side OrderSide) lib.Decimal {
+	var best lib.Decimal
+	for _, om := range b.orders {
+		if om.Side != side || !om.Type.HasLimitPrice() {
+			continue
+		}
+		if best == 0 || (side == BuyOrderSide && om.Price > best) || (side == SellOrderSide && om.Price < best) {
+			best = om.Price
+		}
+	}
+	return best
+}
@@ -0,0 +1,40 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BookTestSuite struct {
+	suite.Suite
+}
+
+func TestBookTestSuite(t *testing.T) {
+	suite.Run(t, new(BookTestSuite))
+}
+
+func (suite *BookTestSuite) newOrder(id uint, side OrderSide, price int64) *Order {
+	om, err := NewOrder(id, "account", "BTC-USD", string(side), "limit", dec(price), 0, dec(10), time.Now())
+	suite.Require().NoError(err)
+	return om
+}
+
+func (suite *BookTestSuite) TestApplyKeepsOnlyRestingOrders() {
+	bid, ask := suite.newOrder(1, BuyOrderSide, 99), suite.newOrder(2, SellOrderSide, 101)
+	book := NewBook("BTC-USD", []*Order{bid, ask, suite.newOrder(3, BuyOrderSide, 98)})
+	suite.Equal(3, book.Len())
+	suite.Equal(dec(99), book.BestPrice(BuyOrderSide))
+	suite.Equal(dec(101), book.BestPrice(SellOrderSide))
+
+	bid.Fill(dec(10), time.Now())
+	book.Apply(bid)
+	suite.Equal(2, book.Len())
+	suite.Equal(dec(98), book.BestPrice(BuyOrderSide))
+
+	other, err := NewOrder(4, "account", "ETH-USD", "sell", "limit", dec(1), 0, dec(1), time.Now())
+	suite.Require().NoError(err)
+	book.Apply(other)
+	suite.Equal(2, book.Len())
+}
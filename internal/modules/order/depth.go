@@ -0,0 +1,51 @@
+package order
+
+import (
+	"sort"
+	"tradeTornado/internal/lib"
+)
+
+// PriceLevel aggregates the visible quantity resting at a price, hidden iceberg reserve is never included
+type PriceLevel struct {
+	Price    lib.Decimal
+	Quantity lib.Decimal
+	Orders   int
+}
+
+// BookSideSummary counts the resting orders of one side of a book, BestPrice is zero when no resting order is priced
+type BookSideSummary struct {
+	Symbol    string
+	Side      OrderSide
+	Orders    int
+	BestPrice lib.Decimal
+}
+
+// AggregateDepth sums the visible remaining of the resting orders of side by price, best prices first.
+// levels caps the number of price levels when positive
+func AggregateDepth(orders []*Order, side OrderSide, levels int) []*PriceLevel {
+	byPrice := make(map[lib.Decimal]*PriceLevel)
+	var depth []*PriceLevel
+	for _, om := range orders {
+		if om.Side != side || !om.IsResting() {
+			continue
+		}
+		level, ok := byPrice[om.Price]
+		if !ok {
+			level = &PriceLevel{Price: om.Price}
+			byPrice[om.Price] = level
+			depth = append(depth, level)
+		}
+		level.Quantity += om.Remaining
+		level.Orders++
+	}
+	sort.Slice(depth, func(i, j int) bool {
+		if side == BuyOrderSide {
+			return depth[i].Price > depth[j].Price
+		}
+		return depth[i].Price < depth[j].Price
+	})
+	if levels > 0 && len(depth) > levels {
+		depth = depth[:levels]
+	}
+	return depth
+}
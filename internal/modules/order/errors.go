@@ -9,11 +9,12 @@ import (
 var (
 	OrderAlreadyProcessingFound = lib.NewErrorNotification()
 	OrderAlreadyCreated         = lib.NewErrorNotification()
-	NoOrderMatched              = lib.NewErrorNotification()
+	AuctionNotRunning           = lib.NewErrorNotification()
+	OrderNotFound               = lib.NewNotFoundError("order")
 )
 
 func init() {
 	OrderAlreadyProcessingFound.Add("processing_order", errors.New("order is already processing"))
 	OrderAlreadyCreated.Add("created_order", errors.New("order is already created"))
-	NoOrderMatched.Add("no_match", errors.New("no order matched with this order"))
+	AuctionNotRunning.Add("phase", errors.New("indicative prices are only available before the auction uncrosses"))
 }
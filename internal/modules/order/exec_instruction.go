@@ -0,0 +1,85 @@
+package order
+
+import (
+	"errors"
+	"time"
+
+	"tradeTornado/internal/lib"
+)
+
+// ExecInstruction is a bitset of execution flags, new flags only need a new bit
+type ExecInstruction uint32
+
+const (
+	// PostOnlyExecInstruction never lets the order take liquidity, it is rejected when it would cross
+	PostOnlyExecInstruction ExecInstruction = 1 << iota
+	// RepriceExecInstruction re-prices a crossing post only order one tick away from the opposite best price instead of rejecting it
+	RepriceExecInstruction
+	// ReduceOnlyExecInstruction only lets the order reduce the current position of the account
+	ReduceOnlyExecInstruction
+)
+
+const knownExecInstructions = PostOnlyExecInstruction | RepriceExecInstruction | ReduceOnlyExecInstruction
+
+func (ei ExecInstruction) Has(flag ExecInstruction) bool {
+	return ei&flag == flag
+}
+
+func (ei ExecInstruction) validate(orderType OrderType) error {
+	validation := lib.NewErrorNotification()
+	if ei&^knownExecInstructions != 0 {
+		validation.Add("exec_instructions", errors.New("unknown execution instruction"))
+	}
+	if ei.Has(RepriceExecInstruction) && !ei.Has(PostOnlyExecInstruction) {
+		validation.Add("exec_instructions", errors.New("reprice is only allowed on post only orders"))
+	}
+	if ei.Has(PostOnlyExecInstruction) && !orderType.HasLimitPrice() {
+		validation.Add("exec_instructions", errors.New("post only is only allowed on limit orders"))
+	}
+	return validation.Err()
+}
+
+func (order *Order) SetExecInstructions(ei ExecInstruction) error {
+	if err := ei.validate(order.Type); err != nil {
+		return err
+	}
+	order.ExecInstructions = ei
+	return nil
+}
+
+// ApplyPostOnly is evaluated before crossing, a post only order that would take liquidity is re-priced one tick away or rejected
+func (order *Order) ApplyPostOnly(bestOppositePrice, tickSize lib.Decimal) error {
+	if !order.ExecInstructions.Has(PostOnlyExecInstruction) || bestOppositePrice <= 0 || !order.Crosses(bestOppositePrice) {
+		return nil
+	}
+	if !order.ExecInstructions.Has(RepriceExecInstruction) {
+		return NewOrderRejected(PostOnlyRejectReason, "price %s would take liquidity at %s", order.Price, bestOppositePrice)
+	}
+	price := bestOppositePrice + tickSize
+	if order.Side == BuyOrderSide {
+		price = bestOppositePrice - tickSize
+	}
+	if price <= 0 {
+		return NewOrderRejected(PostOnlyRejectReason, "price %s can not be re-priced below %s", order.Price, bestOppositePrice)
+	}
+	order.Price = price
+	return nil
+}
+
+// ApplyReduceOnly caps a reduce only order to the opposite of position, positive position is long
+func (order *Order) ApplyReduceOnly(position lib.Decimal, at time.Time) error {
+	if !order.ExecInstructions.Has(ReduceOnlyExecInstruction) {
+		return nil
+	}
+	reducible := position
+	if order.Side == BuyOrderSide {
+		reducible = -position
+	}
+	if reducible <= 0 {
+		return NewOrderRejected(ReduceOnlyRejectReason, "%s order would increase position %s", order.Side, position)
+	}
+	if order.Open() > reducible {
+		order.Decrement(order.Open()-reducible, at)
+	}
+	return nil
+}
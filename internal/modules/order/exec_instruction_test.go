@@ -0,0 +1,105 @@
+package order
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExecInstructionTestSuite struct {
+	suite.Suite
+}
+
+func TestExecInstructionTestSuite(t *testing.T) {
+	suite.Run(t, new(ExecInstructionTestSuite))
+}
+
+func (suite *ExecInstructionTestSuite) newOrder(side OrderSide, price, quantity int64, ei ExecInstruction) *Order {
+	om, err := NewOrder(1, "account", "BTC-USD", string(side), "limit", dec(price), 0, dec(quantity), time.Unix(1, 0))
+	suite.Require().NoError(err)
+	suite.Require().NoError(om.SetExecInstructions(ei))
+	return om
+}
+
+func (suite *ExecInstructionTestSuite) rejectedWith(reason RejectReason, err error) {
+	var rejected *OrderRejected
+	suite.Require().True(errors.As(err, &rejected), "%v", err)
+	suite.Equal(reason, rejected.Reason)
+}
+
+func (suite *ExecInstructionTestSuite) TestInstructionsAreValidatedAgainstTheOrder() {
+	om, err := NewOrder(1, "account", "BTC-USD", "buy", "market", 0, 0, dec(1), time.Unix(1, 0))
+	suite.Require().NoError(err)
+	suite.Error(om.SetExecInstructions(PostOnlyExecInstruction))
+	suite.NoError(om.SetExecInstructions(ReduceOnlyExecInstruction))
+
+	om = suite.newOrder(BuyOrderSide, 100, 1, 0)
+	suite.Error(om.SetExecInstructions(RepriceExecInstruction))
+	suite.Error(om.SetExecInstructions(1 << 7))
+	suite.NoError(om.SetExecInstructions(PostOnlyExecInstruction | RepriceExecInstruction | ReduceOnlyExecInstruction))
+}
+
+func (suite *ExecInstructionTestSuite) TestPostOnlyRejectsOrReprices() {
+	for _, tc := range []struct {
+		name      string
+		side      OrderSide
+		price     int64
+		ei        ExecInstruction
+		best      int64
+		reprice   int64
+		rejection bool
+	}{
+		{name: "crossing buy", side: BuyOrderSide, price: 101, ei: PostOnlyExecInstruction, best: 100, rejection: true},
+		{name: "buy on the best ask", side: BuyOrderSide, price: 100, ei: PostOnlyExecInstruction, best: 100, rejection: true},
+		{name: "crossing sell", side: SellOrderSide, price: 99, ei: PostOnlyExecInstruction, best: 100, rejection: true},
+		{name: "passive buy", side: BuyOrderSide, price: 99, ei: PostOnlyExecInstruction, best: 100, reprice: 99},
+		{name: "empty opposite side", side: BuyOrderSide, price: 200, ei: PostOnlyExecInstruction, reprice: 200},
+		{name: "repriced buy", side: BuyOrderSide, price: 105, ei: PostOnlyExecInstruction | RepriceExecInstruction, best: 100, reprice: 99},
+		{name: "repriced sell", side: SellOrderSide, price: 95, ei: PostOnlyExecInstruction | RepriceExecInstruction, best: 100, reprice: 101},
+		{name: "no price below the best bid", side: BuyOrderSide, price: 5, ei: PostOnlyExecInstruction | RepriceExecInstruction, best: 1, rejection: true},
+		{name: "not post only", side: BuyOrderSide, price: 101, best: 100, reprice: 101},
+	} {
+		om := suite.newOrder(tc.side, tc.price, 1, tc.ei)
+		err := om.ApplyPostOnly(dec(tc.best), dec(1))
+		if tc.rejection {
+			suite.rejectedWith(PostOnlyRejectReason, err)
+			continue
+		}
+		suite.NoError(err, tc.name)
+		suite.Equal(dec(tc.reprice), om.Price, tc.name)
+	}
+}
+
+func (suite *ExecInstructionTestSuite) TestReduceOnlyIsCappedByTheNetPosition() {
+	for _, tc := range []struct {
+		name      string
+		side      OrderSide
+		quantity  int64
+		position  int64
+		open      int64
+		rejection bool
+	}{
+		{name: "sell reducing a long", side: SellOrderSide, quantity: 3, position: 5, open: 3},
+		{name: "sell closing a long", side: SellOrderSide, quantity: 8, position: 5, open: 5},
+		{name: "buy closing a short", side: BuyOrderSide, quantity: 6, position: -4, open: 4},
+		{name: "buy on a long", side: BuyOrderSide, quantity: 1, position: 5, rejection: true},
+		{name: "sell on a short", side: SellOrderSide, quantity: 1, position: -5, rejection: true},
+		{name: "flat", side: SellOrderSide, quantity: 1, rejection: true},
+	} {
+		om := suite.newOrder(tc.side, 100, tc.quantity, ReduceOnlyExecInstruction)
+		err := om.ApplyReduceOnly(dec(tc.position), time.Unix(2, 0))
+		if tc.rejection {
+			suite.rejectedWith(ReduceOnlyRejectReason, err)
+			continue
+		}
+		suite.NoError(err, tc.name)
+		suite.Equal(dec(tc.open), om.Open(), tc.name)
+		suite.True(om.IsResting(), tc.name)
+	}
+
+	om := suite.newOrder(SellOrderSide, 100, 8, 0)
+	suite.NoError(om.ApplyReduceOnly(0, time.Unix(2, 0)))
+	suite.Equal(dec(8), om.Open())
+}
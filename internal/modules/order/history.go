@@ -0,0 +1,94 @@
+package order
+
+import (
+	"time"
+	"tradeTornado/internal/lib"
+)
+
+type OrderEventType string
+
+const (
+	AcceptedOrderEventType  OrderEventType = "accepted"
+	TriggeredOrderEventType OrderEventType = "triggered"
+	FilledOrderEventType    OrderEventType = "filled"
+	AmendedOrderEventType   OrderEventType = "amended"
+	CancelledOrderEventType OrderEventType = "cancelled"
+	ExpiredOrderEventType   OrderEventType = "expired"
+	RejectedOrderEventType  OrderEventType = "rejected"
+)
+
+// OrderEventReason explains the events the engine applied on its own, rejections keep their RejectReason
+type OrderEventReason string
+
+const (
+	RepricedOrderEventReason           OrderEventReason = "POST_ONLY_REPRICED"
+	ReduceOnlyOrderEventReason         OrderEventReason = "REDUCE_ONLY_CAPPED"
+	SelfTradePreventedOrderEventReason OrderEventReason = "SELF_TRADE_PREVENTED"
+	CancelAllOrderEventReason          OrderEventReason = "CANCEL_ALL"
+	UnfilledMarketOrderEventReason     OrderEventReason = "MARKET_ORDER_UNFILLED"
+)
+
+// OrderEvent is one step of the lifecycle of an order with the state the order was left in. Quantity is what the step
+// traded or removed, Remaining is the visible quantity left in the book and Open is what the order could still trade
+// afterwards including its hidden reserve, Open stays internal to the engine.
+// Events of an order are chronological by ID
+type OrderEvent struct {
+	ID        uint             `gorm:"primarykey;column:id"`
+	OrderID   uint             `gorm:"column:order_id;index"`
+	Type      OrderEventType   `gorm:"column:type"`
+	Status    OrderStatus      `gorm:"column:status"`
+	Price     lib.Decimal      `gorm:"column:price"`
+	Quantity  lib.Decimal      `gorm:"column:quantity;default:0"`
+	Remaining lib.Decimal      `gorm:"column:remaining;default:0"`
+	Open      lib.Decimal      `gorm:"column:open"`
+	TradeID   uint             `gorm:"column:trade_id;default:0"`
+	Reason    OrderEventReason `gorm:"column:reason"`
+	Message   string           `gorm:"column:message"`
+	CreatedAt time.Time        `gorm:"column:created_at"`
+}
+
+func NewOrderEvent(eventType OrderEventType, order *Order, reason OrderEventReason, at time.Time) *OrderEvent {
+	return &OrderEvent{
+		OrderID:   order.ID,
+		Type:      eventType,
+		Status:    order.Status,
+		Price:     order.Price,
+		Remaining: order.Remaining,
+		Open:      order.Open(),
+		Reason:    reason,
+		CreatedAt: at,
+	}
+}
+
+// NewFillEvent is the execution of trade on one of its orders, order should already be filled by the trade
+func NewFillEvent(order *Order, trade *Trade) *OrderEvent {
+	event := NewOrderEvent(FilledOrderEventType, order, "", trade.CreatedAt)
+	event.Price = trade.Price
+	event.Quantity = trade.Quantity
+	event.TradeID = trade.ID
+	return event
+}
+
+func NewRejectEvent(order *Order, rejected *OrderRejected, at time.Time) *OrderEvent {
+	event := NewOrderEvent(RejectedOrderEventType, order, OrderEventReason(rejected.Reason), at)
+	event.Message = rejected.Message
+	return event
+}
+
+// NewSelfTradeEvents are the events of both orders of a prevented self trade, an order the mode left untouched has none
+func NewSelfTradeEvents(prevented *SelfTradePrevented, at time.Time) []*OrderEvent {
+	var events []*OrderEvent
+	for _, om := range []*Order{prevented.Taker, prevented.Maker} {
+		var event *OrderEvent
+		if om.Status == CancelledOrderStatus {
+			event = NewOrderEvent(CancelledOrderEventType, om, SelfTradePreventedOrderEventReason, at)
+		} else if prevented.DecrementedQuantity > 0 {
+			event = NewOrderEvent(AmendedOrderEventType, om, SelfTradePreventedOrderEventReason, at)
+		} else {
+			continue
+		}
+		event.Quantity = prevented.DecrementedQuantity
+		events = append(events, event)
+	}
+	return events
+}
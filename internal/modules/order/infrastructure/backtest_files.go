@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"tradeTornado/internal/modules/order/application"
+)
+
+// BacktestFiles writes the trades and book snapshots of a backtest as JSON lines and its summary as JSON
+type BacktestFiles struct {
+	directory string
+	files     []*os.File
+	buffers   []*bufio.Writer
+	trades    *json.Encoder
+	snapshots *json.Encoder
+}
+
+func NewBacktestFiles(directory string) (*BacktestFiles, error) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, err
+	}
+	bf := &BacktestFiles{directory: directory}
+	trades, err := bf.create("trades.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := bf.create("books.jsonl")
+	if err != nil {
+		return nil, errors.Join(err, bf.Close())
+	}
+	bf.trades, bf.snapshots = trades, snapshots
+	return bf, nil
+}
+
+func (bf *BacktestFiles) WriteTrade(trade *application.BacktestTradeDto) error {
+	return bf.trades.Encode(trade)
+}
+
+func (bf *BacktestFiles) WriteSnapshot(snapshot *application.BacktestSnapshotDto) error {
+	return bf.snapshots.Encode(snapshot)
+}
+
+func (bf *BacktestFiles) WriteSummary(summary *application.BacktestSummaryDto) error {
+	bts, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(bf.directory, "summary.json"), append(bts, '\n'), 0o644)
+}
+
+func (bf *BacktestFiles) Close() error {
+	var errs []error
+	for i, file := range bf.files {
+		errs = append(errs, bf.buffers[i].Flush(), file.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (bf *BacktestFiles) create(name string) (*json.Encoder, error) {
+	file, err := os.Create(filepath.Join(bf.directory, name))
+	if err != nil {
+		return nil, err
+	}
+	buffer := bufio.NewWriter(file)
+	bf.files = append(bf.files, file)
+	bf.buffers = append(bf.buffers, buffer)
+	return json.NewEncoder(buffer), nil
+}
@@ -1,11 +1,12 @@
 package infrastructure
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
 	"tradeTornado/internal/modules/order/application"
-
-	"github.com/gin-gonic/gin"
 )
 
 type OrderController struct {
@@ -18,37 +19,126 @@ func NewOrderController(qh *application.OrderQueryHandler) *OrderController {
 	}
 }
 
-func (oc *OrderController) GetRouters() []func() (method string, url string, handler gin.HandlerFunc) {
-	return []func() (method string, url string, handler gin.HandlerFunc){
-		oc.listOrderBook,
+func (oc *OrderController) GetRoutes() []lib.Route {
+	return []lib.Route{
+		oc.listOrderBook(),
+		oc.getDepth(),
+		oc.getIndicativeAuction(),
+		oc.getOrder(),
+		oc.listOrderEvents(),
 	}
 }
 func (oc *OrderController) GetRoot() string {
 	return "orders"
 }
-func (oc *OrderController) GetMiddlewares() []gin.HandlerFunc {
+
+func (oc *OrderController) GetMiddlewares() []lib.Middleware {
 	return nil
 }
 
-func (oc *OrderController) listOrderBook() (method string, uri string, handler gin.HandlerFunc) {
-	return http.MethodGet, "", func(context *gin.Context) {
-		criteria, err := lib.ParseCriteriaFromRequest(context)
+// listOrdersRequest an Accept of text/csv or application/x-ndjson streams the orders instead of paging them
+type listOrdersRequest struct {
+	Accept   string `header:"Accept"`
+	Criteria lib.Criteria
+}
+
+// listOrdersResponse Total is missing when the criteria skips the count
+type listOrdersResponse struct {
+	Orders     []*application.OrderDto `json:"orders"`
+	Total      *int                    `json:"total,omitempty"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+}
+
+// projectOrdersResponse Orders are the rows of a projection keyed by field and aggregate names
+type projectOrdersResponse struct {
+	Orders []lib.Row `json:"orders"`
+	Total  *int      `json:"total,omitempty"`
+}
+
+func (oc *OrderController) listOrderBook() lib.Route {
+	return lib.Handle(http.MethodGet, "", func(ctx context.Context, req listOrdersRequest) (any, error) {
+		criteria := req.Criteria
+		if format, ok := lib.NegotiateExportFormat(req.Accept); ok {
+			return lib.ExportResponse(format, func(w io.Writer) error {
+				return oc.queryHanlder.ExportOrders(ctx, criteria, format, w)
+			}), nil
+		}
+		if criteria.IsProjection() {
+			rows, page, err := oc.queryHanlder.ProjectOrders(ctx, criteria)
+			if err != nil {
+				return nil, err
+			}
+			response := &projectOrdersResponse{Orders: rows}
+			if !criteria.SkipTotal {
+				response.Total = &page.Total
+			}
+			return response, nil
+		}
+		orders, page, err := oc.queryHanlder.ListOrders(ctx, criteria)
 		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-			})
-			return
+			return nil, err
 		}
-		orders, count, err := oc.queryHanlder.ListOrders(context, *criteria)
+		response := &listOrdersResponse{Orders: orders, NextCursor: page.NextCursor}
+		if !criteria.SkipTotal {
+			response.Total = &page.Total
+		}
+		return response, nil
+	}).WithResponse(listOrdersResponse{}).WithCriteria(order.Order{}).WithExports()
+}
+
+type depthRequest struct {
+	Symbol string `query:"symbol,required"`
+	Levels int    `query:"levels" default:"20"`
+}
+
+func (dr depthRequest) Validate() error {
+	validation := lib.NewErrorNotification()
+	validation.IntShouldBeGT("levels", dr.Levels, 0)
+	return validation.Err()
+}
+
+func (oc *OrderController) getDepth() lib.Route {
+	return lib.Handle(http.MethodGet, "depth", func(ctx context.Context, req depthRequest) (*application.DepthDto, error) {
+		return oc.queryHanlder.GetDepth(ctx, req.Symbol, req.Levels)
+	})
+}
+
+type auctionRequest struct {
+	Symbol string `query:"symbol,required"`
+}
+
+func (oc *OrderController) getIndicativeAuction() lib.Route {
+	return lib.Handle(http.MethodGet, "auction", func(ctx context.Context, req auctionRequest) (*application.IndicativeAuctionDto, error) {
+		return oc.queryHanlder.GetIndicativeAuction(ctx, req.Symbol)
+	})
+}
+
+type orderRequest struct {
+	ID uint `path:"id"`
+}
+
+func (or orderRequest) Validate() error {
+	validation := lib.NewErrorNotification()
+	validation.UintShouldBeGT("id", or.ID, 0)
+	return validation.Err()
+}
+
+func (oc *OrderController) getOrder() lib.Route {
+	return lib.Handle(http.MethodGet, ":id", func(ctx context.Context, req orderRequest) (*application.OrderDetailDto, error) {
+		return oc.queryHanlder.GetOrder(ctx, req.ID)
+	})
+}
+
+type listOrderEventsResponse struct {
+	Events []*application.OrderEventDto `json:"events"`
+}
+
+func (oc *OrderController) listOrderEvents() lib.Route {
+	return lib.Handle(http.MethodGet, ":id/events", func(ctx context.Context, req orderRequest) (*listOrderEventsResponse, error) {
+		events, err := oc.queryHanlder.ListOrderEvents(ctx, req.ID)
 		if err != nil {
-			context.JSON(http.StatusInternalServerError, gin.H{
-				"error": err.Error(),
-			})
-			return
+			return nil, err
 		}
-		context.JSON(http.StatusOK, gin.H{
-			"total":  count,
-			"orders": orders,
-		})
-	}
+		return &listOrderEventsResponse{Events: events}, nil
+	})
 }
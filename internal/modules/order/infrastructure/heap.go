@@ -14,7 +14,8 @@ type orderHeapData struct {
 }
 
 func (ohd *orderHeapData) GetRank() int {
-	return ohd.Price
+	// the scaled units keep the ordering of the prices
+	return int(ohd.Price)
 }
 
 type OrderBook struct {
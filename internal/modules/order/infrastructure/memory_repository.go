@@ -0,0 +1,349 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+)
+
+// MemoryOrderRepository runs the matcher without a database, it keeps the ordering rules of OrderRepository for a
+// single writer. Orders leave the repository once they are filled or cancelled, only their ids are remembered.
+// A failed transaction is undone like a rolled back one
+type MemoryOrderRepository struct {
+	created          map[uint]bool
+	books            map[string]map[uint]*order.Order
+	trades           map[string][]*order.Trade
+	lastTrades       map[string]*order.Trade
+	positions        map[string]map[string]lib.Decimal
+	lastTradeID      uint
+	reservedTradeIDs []uint
+	sequence         uint64
+	journal          []*order.JournaledCommand
+	// undo is nil outside of a transaction
+	undo []func()
+}
+
+func NewMemoryOrderRepository() *MemoryOrderRepository {
+	return &MemoryOrderRepository{
+		created:    make(map[uint]bool),
+		books:      make(map[string]map[uint]*order.Order),
+		trades:     make(map[string][]*order.Trade),
+		lastTrades: make(map[string]*order.Trade),
+		positions:  make(map[string]map[string]lib.Decimal),
+	}
+}
+
+func (r *MemoryOrderRepository) CreateWithHook(ctx context.Context, om *order.Order, process func(ctx context.Context, Order *order.Order) error) error {
+	return r.runTx(func() error {
+		if r.created[om.ID] {
+			return order.OrderAlreadyCreated
+		}
+		r.created[om.ID] = true
+		r.remember(func() { delete(r.created, om.ID) })
+		r.put(om)
+		return process(ctx, om)
+	})
+}
+
+func (r *MemoryOrderRepository) SelectBestLevelForUpdate(ctx context.Context, symbol string, side order.OrderSide, limitPrice lib.Decimal) ([]*order.Order, error) {
+	var best *order.Order
+	for _, om := range r.books[symbol] {
+		if om.Side != side || !om.IsResting() {
+			continue
+		}
+		if limitPrice > 0 && ((side == order.BuyOrderSide && om.Price < limitPrice) || (side == order.SellOrderSide && om.Price > limitPrice)) {
+			continue
+		}
+		if best == nil || (side == order.BuyOrderSide && om.Price > best.Price) || (side == order.SellOrderSide && om.Price < best.Price) {
+			best = om
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return r.selectResting(symbol, func(om *order.Order) bool { return om.Side == side && om.Price == best.Price }), nil
+}
+
+func (r *MemoryOrderRepository) SelectBookForUpdate(ctx context.Context, symbol string, process func(ctx context.Context, book []*order.Order) error) error {
+	return r.runTx(func() error {
+		return process(ctx, r.selectResting(symbol, func(*order.Order) bool { return true }))
+	})
+}
+
+func (r *MemoryOrderRepository) SelectLiveForUpdate(ctx context.Context, symbol string, id uint, process func(ctx context.Context, om *order.Order) error) error {
+	return r.runTx(func() error {
+		live, ok := r.books[symbol][id]
+		if !ok {
+			return order.OrderNotFound
+		}
+		copied := *live
+		return process(ctx, &copied)
+	})
+}
+
+func (r *MemoryOrderRepository) Save(ctx context.Context, om *order.Order) error {
+	r.put(om)
+	return nil
+}
+
+func (r *MemoryOrderRepository) CancelAll(ctx context.Context, symbol string) ([]*order.Order, error) {
+	var cancelled []*order.Order
+	for _, om := range r.books[symbol] {
+		copied := *om
+		copied.Cancel()
+		r.put(&copied)
+		cancelled = append(cancelled, &copied)
+	}
+	sort.Slice(cancelled, func(i, j int) bool { return cancelled[i].ID < cancelled[j].ID })
+	return cancelled, nil
+}
+
+// CreateTrade takes the reserved ids first, the generated ones are never given back like a database sequence. The fee
+// postings are not kept, the fees are compared through the published executions
+func (r *MemoryOrderRepository) CreateTrade(ctx context.Context, trade *order.Trade) error {
+	if len(r.reservedTradeIDs) > 0 {
+		trade.ID, r.reservedTradeIDs = r.reservedTradeIDs[0], r.reservedTradeIDs[1:]
+	} else {
+		trade.ID = r.lastTradeID + 1
+	}
+	r.lastTradeID = max(r.lastTradeID, trade.ID)
+	copied := *trade
+	symbolTrades := r.trades[trade.Symbol]
+	r.trades[trade.Symbol] = append(symbolTrades, &copied)
+	previous, known := r.lastTrades[trade.Symbol]
+	if !known || copied.CreatedAt.After(previous.CreatedAt) || (copied.CreatedAt.Equal(previous.CreatedAt) && copied.ID > previous.ID) {
+		r.lastTrades[trade.Symbol] = &copied
+	}
+	if trade.TakerAccountID != trade.MakerAccountID {
+		bought, sold := trade.TakerAccountID, trade.MakerAccountID
+		if trade.TakerSide == order.SellOrderSide {
+			bought, sold = sold, bought
+		}
+		r.addPosition(trade.Symbol, bought, trade.Quantity)
+		r.addPosition(trade.Symbol, sold, -trade.Quantity)
+	}
+	r.remember(func() {
+		r.trades[trade.Symbol] = symbolTrades
+		if known {
+			r.lastTrades[trade.Symbol] = previous
+		} else {
+			delete(r.lastTrades, trade.Symbol)
+		}
+	})
+	return nil
+}
+
+func (r *MemoryOrderRepository) LastTradePrice(ctx context.Context, symbol string) (lib.Decimal, error) {
+	if last, ok := r.lastTrades[symbol]; ok {
+		return last.Price, nil
+	}
+	return 0, nil
+}
+
+func (r *MemoryOrderRepository) ReferencePrice(ctx context.Context, symbol string, since time.Time) (lib.Decimal, error) {
+	trades := r.trades[symbol]
+	first := sort.Search(len(trades), func(i int) bool { return !trades[i].CreatedAt.Before(since) })
+	if first == len(trades) {
+		return r.LastTradePrice(ctx, symbol)
+	}
+	reference := trades[first]
+	for _, trade := range trades[first+1:] {
+		if !trade.CreatedAt.Equal(reference.CreatedAt) {
+			break
+		}
+		if trade.ID < reference.ID {
+			reference = trade
+		}
+	}
+	return reference.Price, nil
+}
+
+// BestPrice follows the MIN and MAX of the database, market orders resting for an uncross are priced at zero
+func (r *MemoryOrderRepository) BestPrice(ctx context.Context, symbol string, side order.OrderSide) (lib.Decimal, error) {
+	var best *lib.Decimal
+	for _, om := range r.books[symbol] {
+		if om.Side != side || !om.IsResting() {
+			continue
+		}
+		if best == nil || (side == order.BuyOrderSide && om.Price > *best) || (side == order.SellOrderSide && om.Price < *best) {
+			price := om.Price
+			best = &price
+		}
+	}
+	if best == nil {
+		return 0, nil
+	}
+	return *best, nil
+}
+
+func (r *MemoryOrderRepository) CountOpenOrders(ctx context.Context, symbol, accountID string) (int, error) {
+	count := 0
+	for _, om := range r.books[symbol] {
+		if om.AccountID == accountID && om.IsResting() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryOrderRepository) NetPosition(ctx context.Context, symbol, accountID string) (lib.Decimal, error) {
+	return r.positions[symbol][accountID], nil
+}
+
+func (r *MemoryOrderRepository) SelectNextTriggeredForUpdate(ctx context.Context, symbol string, lastTradePrice lib.Decimal) (*order.Order, error) {
+	var next, buy, sell *order.Order
+	for _, om := range r.books[symbol] {
+		if om.Status != order.PendingTriggerOrderStatus {
+			continue
+		}
+		if om.Side == order.BuyOrderSide && om.TriggerPrice <= lastTradePrice &&
+			(buy == nil || om.TriggerPrice < buy.TriggerPrice || (om.TriggerPrice == buy.TriggerPrice && hasPriority(om, buy))) {
+			buy = om
+		}
+		if om.Side == order.SellOrderSide && om.TriggerPrice >= lastTradePrice &&
+			(sell == nil || om.TriggerPrice > sell.TriggerPrice || (om.TriggerPrice == sell.TriggerPrice && hasPriority(om, sell))) {
+			sell = om
+		}
+	}
+	for _, candidate := range []*order.Order{buy, sell} {
+		if candidate != nil && (next == nil || hasPriority(candidate, next)) {
+			next = candidate
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	copied := *next
+	return &copied, nil
+}
+
+// AppendJournal numbers the entries like the journal table, the sequence is not given back on rollback
+func (r *MemoryOrderRepository) AppendJournal(ctx context.Context, command *order.JournalEntry, events ...*order.JournalEntry) error {
+	r.sequence++
+	command.Sequence = r.sequence
+	for _, event := range events {
+		r.sequence++
+		event.Sequence = r.sequence
+		event.CommandSequence = command.Sequence
+	}
+	journal := r.journal
+	r.journal = append(r.journal, &order.JournaledCommand{Command: command, Events: events})
+	r.remember(func() { r.journal = journal })
+	return nil
+}
+
+// AppendOrderEvents forgets the events, offline matchers do not keep the history of the orders
+func (r *MemoryOrderRepository) AppendOrderEvents(ctx context.Context, events ...*order.OrderEvent) error {
+	return nil
+}
+
+func (r *MemoryOrderRepository) TakeJournal() []*order.JournaledCommand {
+	journal := r.journal
+	r.journal = nil
+	return journal
+}
+
+func (r *MemoryOrderRepository) ReserveTradeIDs(ids ...uint) {
+	r.reservedTradeIDs = append(r.reservedTradeIDs, ids...)
+}
+
+func (r *MemoryOrderRepository) Resting(ctx context.Context, symbol string) ([]*order.Order, error) {
+	return r.selectResting(symbol, func(*order.Order) bool { return true }), nil
+}
+
+func (r *MemoryOrderRepository) RestingSymbols(ctx context.Context) ([]string, error) {
+	var symbols []string
+	for symbol, book := range r.books {
+		for _, om := range book {
+			if om.IsResting() {
+				symbols = append(symbols, symbol)
+				break
+			}
+		}
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// Depth sums the visible remaining of the resting orders by price, best prices first
+func (r *MemoryOrderRepository) Depth(ctx context.Context, symbol string, side order.OrderSide, levels int) ([]*order.PriceLevel, error) {
+	return order.AggregateDepth(r.selectResting(symbol, func(*order.Order) bool { return true }), side, levels), nil
+}
+
+// selectResting returns copies of the resting orders of symbol accepted by filter in time priority
+func (r *MemoryOrderRepository) selectResting(symbol string, filter func(om *order.Order) bool) []*order.Order {
+	var selected []*order.Order
+	for _, om := range r.books[symbol] {
+		if om.IsResting() && filter(om) {
+			copied := *om
+			selected = append(selected, &copied)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return hasPriority(selected[i], selected[j]) })
+	return selected
+}
+
+// put stores a copy of om, orders that are neither resting nor waiting for their trigger leave the repository
+func (r *MemoryOrderRepository) put(om *order.Order) {
+	book, ok := r.books[om.Symbol]
+	if !ok {
+		book = make(map[uint]*order.Order)
+		r.books[om.Symbol] = book
+	}
+	previous, existed := book[om.ID]
+	if om.IsResting() || om.Status == order.PendingTriggerOrderStatus {
+		copied := *om
+		book[om.ID] = &copied
+	} else {
+		delete(book, om.ID)
+	}
+	r.remember(func() {
+		if existed {
+			book[om.ID] = previous
+		} else {
+			delete(book, om.ID)
+		}
+	})
+}
+
+func (r *MemoryOrderRepository) addPosition(symbol, accountID string, quantity lib.Decimal) {
+	positions, ok := r.positions[symbol]
+	if !ok {
+		positions = make(map[string]lib.Decimal)
+		r.positions[symbol] = positions
+	}
+	positions[accountID] += quantity
+	r.remember(func() { positions[accountID] -= quantity })
+}
+
+// runTx joins the running transaction, the outermost one undoes every change when process fails
+func (r *MemoryOrderRepository) runTx(process func() error) error {
+	if r.undo != nil {
+		return process()
+	}
+	r.undo = make([]func(), 0)
+	err := process()
+	if err != nil {
+		for i := len(r.undo) - 1; i >= 0; i-- {
+			r.undo[i]()
+		}
+	}
+	r.undo = nil
+	return err
+}
+
+func (r *MemoryOrderRepository) remember(undo func()) {
+	if r.undo != nil {
+		r.undo = append(r.undo, undo)
+	}
+}
+
+func hasPriority(a, b *order.Order) bool {
+	if !a.PriorityAt.Equal(b.PriorityAt) {
+		return a.PriorityAt.Before(b.PriorityAt)
+	}
+	return a.ID < b.ID
+}
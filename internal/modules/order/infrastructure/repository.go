@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
 	"tradeTornado/internal/lib"
 	"tradeTornado/internal/modules/order"
@@ -29,6 +30,120 @@ func (c *OrderRepository) Save(ctx context.Context, cg *order.Order) error {
 	return c.session.Gorm().WithContext(ctx).Save(cg).Error
 }
 
+func (c *OrderRepository) CancelAll(ctx context.Context, symbol string) ([]*order.Order, error) {
+	statuses := append([]order.OrderStatus{order.PendingTriggerOrderStatus}, order.RestingOrderStatuses...)
+	var cancelled []*order.Order
+	if err := c.session.Gorm().WithContext(ctx).
+		Model(&cancelled).
+		Clauses(clause.Returning{}).
+		Where("symbol = ? and status in ?", symbol, statuses).
+		Update("status", order.CancelledOrderStatus).Error; err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// CreateTrade posts the fees of the trade in the same transaction
+func (c *OrderRepository) CreateTrade(ctx context.Context, trade *order.Trade) error {
+	return c.session.RunTx(ctx, func() error {
+		if err := c.session.Gorm().WithContext(ctx).Create(trade).Error; err != nil {
+			return err
+		}
+		postings := trade.FeePostings()
+		if len(postings) == 0 {
+			return nil
+		}
+		return c.session.Gorm().WithContext(ctx).Create(postings).Error
+	})
+}
+
+// AppendJournal joins the transaction of the caller, the events only get their sequences after the command
+func (c *OrderRepository) AppendJournal(ctx context.Context, command *order.JournalEntry, events ...*order.JournalEntry) error {
+	return c.session.RunTx(ctx, func() error {
+		if err := c.session.Gorm().WithContext(ctx).Create(command).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		for _, event := range events {
+			event.CommandSequence = command.Sequence
+		}
+		return c.session.Gorm().WithContext(ctx).Create(events).Error
+	})
+}
+
+func (c *OrderRepository) AppendOrderEvents(ctx context.Context, events ...*order.OrderEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return c.session.Gorm().WithContext(ctx).Create(events).Error
+}
+
+func (c *OrderRepository) ListOrderEvents(ctx context.Context, orderID uint) ([]*order.OrderEvent, error) {
+	var events []*order.OrderEvent
+	if err := c.session.Gorm().WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("id ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (c *OrderRepository) ReadJournal(ctx context.Context, after, until uint64, limit int) ([]*order.JournaledCommand, error) {
+	var commands []*order.JournalEntry
+	if err := c.session.Gorm().WithContext(ctx).
+		Where("kind = ? and sequence > ?", order.CommandJournalEntryKind, after).
+		Scopes(func(db *gorm.DB) *gorm.DB {
+			if until > 0 {
+				return db.Where("sequence <= ?", until)
+			}
+			return db
+		}).
+		Order("sequence ASC").
+		Limit(limit).
+		Find(&commands).Error; err != nil {
+		return nil, err
+	}
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	journaled := make([]*order.JournaledCommand, 0, len(commands))
+	bySequence := make(map[uint64]*order.JournaledCommand, len(commands))
+	sequences := make([]uint64, 0, len(commands))
+	for _, command := range commands {
+		jc := &order.JournaledCommand{Command: command}
+		journaled = append(journaled, jc)
+		bySequence[command.Sequence] = jc
+		sequences = append(sequences, command.Sequence)
+	}
+	var events []*order.JournalEntry
+	if err := c.session.Gorm().WithContext(ctx).
+		Where("kind = ? and command_sequence in ?", order.EventJournalEntryKind, sequences).
+		Order("sequence ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		jc := bySequence[event.CommandSequence]
+		jc.Events = append(jc.Events, event)
+	}
+	return journaled, nil
+}
+
+func (c *OrderRepository) LastJournalSequence(ctx context.Context) (uint64, error) {
+	var sequence uint64
+	if err := c.session.Gorm().WithContext(ctx).
+		Model(&order.JournalEntry{}).
+		Where("kind = ?", order.CommandJournalEntryKind).
+		Select("coalesce(max(sequence), 0)").
+		Scan(&sequence).Error; err != nil {
+		return 0, err
+	}
+	return sequence, nil
+}
+
 func (c *OrderRepository) CreateWithHook(ctx context.Context, or *order.Order, process func(ctx context.Context, Order *order.Order) error) error {
 	return c.session.RunTx(ctx, func() error {
 		err := c.session.Gorm().WithContext(ctx).Create(or).Error
@@ -52,45 +167,312 @@ func (c *OrderRepository) SaveWithHook(ctx context.Context, order *order.Order,
 	})
 }
 
-func (c *OrderRepository) SelectForUpdate(ctx context.Context, side order.OrderSide, price, quantity int, updateFn func(context.Context, *order.Order) error) error {
+func (c *OrderRepository) SelectBestLevelForUpdate(ctx context.Context, symbol string, side order.OrderSide, limitPrice lib.Decimal) ([]*order.Order, error) {
+	priceOrder, crossing := "price ASC", "price <= ?"
+	if side == order.BuyOrderSide {
+		priceOrder, crossing = "price DESC", "price >= ?"
+	}
+	var best []*order.Order
+	if err := c.session.Gorm().
+		WithContext(ctx).
+		Where("symbol = ? and side = ? and status in ?", symbol, side, order.RestingOrderStatuses).
+		Scopes(func(db *gorm.DB) *gorm.DB {
+			if limitPrice > 0 {
+				return db.Where(crossing, limitPrice)
+			}
+			return db
+		}).
+		Order(priceOrder).
+		Limit(1).
+		Find(&best).Error; err != nil {
+		return nil, err
+	}
+	if len(best) == 0 {
+		return nil, nil
+	}
+	var level []*order.Order
+	if err := c.session.Gorm().
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("symbol = ? and side = ? and status in ? and price = ?", symbol, side, order.RestingOrderStatuses, best[0].Price).
+		Order("priority_at ASC, id ASC").
+		Find(&level).Error; err != nil {
+		return nil, err
+	}
+	return level, nil
+}
+
+func (c *OrderRepository) SelectBookForUpdate(ctx context.Context, symbol string, process func(ctx context.Context, book []*order.Order) error) error {
 	return c.session.RunTx(ctx, func() error {
-		var matchedOrder *order.Order
+		var book []*order.Order
 		if err := c.session.Gorm().
 			WithContext(ctx).
 			Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("matched = ? and side = ? and price = ? and quantity = ?", false, side, price, quantity).
-			First(&matchedOrder).Error; err != nil {
+			Where("symbol = ? and status in ?", symbol, order.RestingOrderStatuses).
+			Order("priority_at ASC, id ASC").
+			Find(&book).Error; err != nil {
+			return err
+		}
+		return process(ctx, book)
+	})
+}
+
+func (c *OrderRepository) SelectLiveForUpdate(ctx context.Context, symbol string, id uint, process func(ctx context.Context, om *order.Order) error) error {
+	return c.session.RunTx(ctx, func() error {
+		var om *order.Order
+		if err := c.session.Gorm().
+			WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? and symbol = ? and status in ?", id, symbol, order.LiveOrderStatuses).
+			First(&om).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return order.NoOrderMatched
+				return order.OrderNotFound
 			}
 			return err
 		}
-		return updateFn(ctx, matchedOrder)
+		return process(ctx, om)
 	})
 }
 
-func (c *OrderRepository) List(ctx context.Context, cr lib.Criteria) ([]*order.Order, int, error) {
+func (c *OrderRepository) Resting(ctx context.Context, symbol string) ([]*order.Order, error) {
+	var book []*order.Order
+	if err := c.session.Gorm().
+		WithContext(ctx).
+		Where("symbol = ? and status in ?", symbol, order.RestingOrderStatuses).
+		Order("priority_at ASC, id ASC").
+		Find(&book).Error; err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+func (c *OrderRepository) RestingSymbols(ctx context.Context) ([]string, error) {
+	var symbols []string
+	if err := c.session.Gorm().
+		WithContext(ctx).
+		Model(&order.Order{}).
+		Distinct("symbol").
+		Where("status in ?", order.RestingOrderStatuses).
+		Pluck("symbol", &symbols).Error; err != nil {
+		return nil, err
+	}
+	return symbols, nil
+}
+
+// SelectNextTriggeredForUpdate walks buy stops from the lowest trigger price and sell stops from the highest one,
+// between both sides the order with time priority is triggered first so cascades are deterministic
+func (c *OrderRepository) SelectNextTriggeredForUpdate(ctx context.Context, symbol string, lastTradePrice lib.Decimal) (*order.Order, error) {
+	triggerBooks := []struct {
+		side      order.OrderSide
+		condition string
+		priority  string
+	}{
+		{side: order.BuyOrderSide, condition: "trigger_price <= ?", priority: "trigger_price ASC"},
+		{side: order.SellOrderSide, condition: "trigger_price >= ?", priority: "trigger_price DESC"},
+	}
+	var candidates []*order.Order
+	for _, book := range triggerBooks {
+		var triggered []*order.Order
+		if err := c.session.Gorm().
+			WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("symbol = ? and status = ? and side = ?", symbol, order.PendingTriggerOrderStatus, book.side).
+			Where(book.condition, lastTradePrice).
+			Order(book.priority).
+			Order("priority_at ASC, id ASC").
+			Limit(1).
+			Find(&triggered).Error; err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, triggered...)
+	}
+	var next *order.Order
+	for _, candidate := range candidates {
+		if next == nil || candidate.PriorityAt.Before(next.PriorityAt) ||
+			(candidate.PriorityAt.Equal(next.PriorityAt) && candidate.ID < next.ID) {
+			next = candidate
+		}
+	}
+	return next, nil
+}
+
+func (c *OrderRepository) LastTradePrice(ctx context.Context, symbol string) (lib.Decimal, error) {
+	var trades []*order.Trade
+	if err := c.session.Gorm().WithContext(ctx).
+		Where("symbol = ?", symbol).
+		Order("created_at DESC, id DESC").
+		Limit(1).
+		Find(&trades).Error; err != nil {
+		return 0, err
+	}
+	if len(trades) == 0 {
+		return 0, nil
+	}
+	return trades[0].Price, nil
+}
+
+func (c *OrderRepository) ReferencePrice(ctx context.Context, symbol string, since time.Time) (lib.Decimal, error) {
+	var trades []*order.Trade
+	if err := c.session.Gorm().WithContext(ctx).
+		Where("symbol = ? and created_at >= ?", symbol, since).
+		Order("created_at ASC, id ASC").
+		Limit(1).
+		Find(&trades).Error; err != nil {
+		return 0, err
+	}
+	if len(trades) == 0 {
+		return c.LastTradePrice(ctx, symbol)
+	}
+	return trades[0].Price, nil
+}
+
+func (c *OrderRepository) ListTrades(ctx context.Context, symbol string, afterID uint, from, to time.Time, limit int) ([]*order.Trade, error) {
+	var trades []*order.Trade
+	query := c.session.Gorm().WithContext(ctx).Where("created_at >= ? and created_at < ? and id > ?", from, to, afterID)
+	if symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+	if err := query.
+		Order("id ASC").
+		Limit(limit).
+		Find(&trades).Error; err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+func (c *OrderRepository) BestPrice(ctx context.Context, symbol string, side order.OrderSide) (lib.Decimal, error) {
+	aggregate := "MIN(price)"
+	if side == order.BuyOrderSide {
+		aggregate = "MAX(price)"
+	}
+	// NULL when the side is empty scans as zero
+	var price lib.Decimal
+	if err := c.session.Gorm().WithContext(ctx).
+		Model(&order.Order{}).
+		Select(aggregate).
+		Where("symbol = ? and side = ? and status in ?", symbol, side, order.RestingOrderStatuses).
+		Scan(&price).Error; err != nil {
+		return 0, err
+	}
+	return price, nil
+}
+
+func (c *OrderRepository) CountOpenOrders(ctx context.Context, symbol, accountID string) (int, error) {
+	var count int64
+	if err := c.session.Gorm().WithContext(ctx).
+		Model(&order.Order{}).
+		Where("symbol = ? and account_id = ? and status in ?", symbol, accountID, order.RestingOrderStatuses).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (c *OrderRepository) Get(ctx context.Context, id uint) (*order.Order, error) {
+	var om *order.Order
+	if err := c.session.Gorm().WithContext(ctx).Where("id = ?", id).First(&om).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, order.OrderNotFound
+		}
+		return nil, err
+	}
+	return om, nil
+}
+
+func (c *OrderRepository) List(ctx context.Context, cr lib.Criteria) ([]*order.Order, lib.Page, error) {
 	var orders []*order.Order
-	query, err := lib.GenericApplyGormCriteria(c.session.Gorm().WithContext(ctx), order.Order{}, &cr)
+	page, err := lib.GenericListGormCriteria(func() *gorm.DB {
+		return c.session.Gorm().WithContext(ctx)
+	}, order.Order{}, cr, &orders)
 	if err != nil {
-		return nil, 0, err
+		return nil, lib.Page{}, err
 	}
-	err = query.Find(&orders).Error
-	if err != nil {
-		return nil, 0, err
+	return orders, page, nil
+}
+
+func (c *OrderRepository) Stream(ctx context.Context, cr lib.Criteria, each func(om *order.Order) error) error {
+	var om order.Order
+	return lib.GenericStreamGormCriteria(c.session.Gorm().WithContext(ctx), order.Order{}, cr, &om, func() error {
+		return each(&om)
+	})
+}
+
+func (c *OrderRepository) StreamProjection(ctx context.Context, cr lib.Criteria, each func(row lib.Row) error) error {
+	return lib.GenericStreamProjectionGormCriteria(c.session.Gorm().WithContext(ctx), order.Order{}, cr, each)
+}
+
+func (c *OrderRepository) Project(ctx context.Context, cr lib.Criteria) ([]lib.Row, lib.Page, error) {
+	return lib.GenericProjectGormCriteria(func() *gorm.DB {
+		return c.session.Gorm().WithContext(ctx)
+	}, order.Order{}, cr)
+}
+
+func (c *OrderRepository) NetPosition(ctx context.Context, symbol, accountID string) (lib.Decimal, error) {
+	var position lib.Decimal
+	if err := c.session.Gorm().WithContext(ctx).
+		Model(&order.Trade{}).
+		Select(`COALESCE(SUM(CASE
+			WHEN taker_account_id = maker_account_id THEN 0
+			WHEN (taker_account_id = @account AND taker_side = @buy) OR (maker_account_id = @account AND taker_side = @sell) THEN quantity
+			WHEN (taker_account_id = @account AND taker_side = @sell) OR (maker_account_id = @account AND taker_side = @buy) THEN -quantity
+			ELSE 0 END), 0)`,
+			map[string]any{"account": accountID, "buy": order.BuyOrderSide, "sell": order.SellOrderSide}).
+		Where("symbol = ? and (taker_account_id = ? or maker_account_id = ?)", symbol, accountID, accountID).
+		Scan(&position).Error; err != nil {
+		return 0, err
 	}
-	cr.Pagination = nil
-	var total int64
-	countQ, err := lib.GenericApplyGormCriteria(c.session.Gorm().WithContext(ctx), order.Order{}, &cr)
-	if err != nil {
-		return nil, 0, err
+	return position, nil
+}
+
+func (c *OrderRepository) Depth(ctx context.Context, symbol string, side order.OrderSide, levels int) ([]*order.PriceLevel, error) {
+	priceOrder := "price ASC"
+	if side == order.BuyOrderSide {
+		priceOrder = "price DESC"
 	}
-	if err := countQ.Model(order.Order{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	var depth []*order.PriceLevel
+	if err := c.session.Gorm().WithContext(ctx).
+		Model(&order.Order{}).
+		Select("price, SUM(remaining) as quantity, COUNT(*) as orders").
+		Where("symbol = ? and side = ? and status in ?", symbol, side, order.RestingOrderStatuses).
+		Group("price").
+		Order(priceOrder).
+		Limit(levels).
+		Scan(&depth).Error; err != nil {
+		return nil, err
 	}
-	return orders, int(total), nil
+	return depth, nil
+}
+
+func (c *OrderRepository) SummarizeBooks(ctx context.Context) ([]*order.BookSideSummary, error) {
+	var summaries []*order.BookSideSummary
+	if err := c.session.Gorm().WithContext(ctx).
+		Model(&order.Order{}).
+		Select("symbol, side, COUNT(*) as orders, CASE WHEN side = ? THEN MAX(price) ELSE MIN(NULLIF(price, 0)) END as best_price", order.BuyOrderSide).
+		Where("status in ?", order.RestingOrderStatuses).
+		Group("symbol, side").
+		Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+	return summaries, nil
 }
 
 func (c *OrderRepository) Migrate(ctx context.Context) error {
-	return c.session.Gorm().WithContext(ctx).AutoMigrate(&order.Order{})
+	if err := c.session.Gorm().WithContext(ctx).AutoMigrate(&order.Order{}, &order.Trade{}, &order.FeePosting{}, &order.JournalEntry{}, &order.OrderEvent{}); err != nil {
+		return err
+	}
+	// orders created before partial fills only knew about matched
+	if err := c.session.Gorm().WithContext(ctx).Model(&order.Order{}).
+		Where("(status is null or status = '') and matched = ?", true).
+		Updates(map[string]any{"status": order.FilledOrderStatus, "remaining": 0}).Error; err != nil {
+		return err
+	}
+	if err := c.session.Gorm().WithContext(ctx).Model(&order.Order{}).
+		Where("(status is null or status = '') and matched = ?", false).
+		Updates(map[string]any{"status": order.OpenOrderStatus, "remaining": gorm.Expr("quantity")}).Error; err != nil {
+		return err
+	}
+	return c.session.Gorm().WithContext(ctx).Model(&order.Order{}).
+		Where("type is null or type = ''").
+		Updates(map[string]any{"type": order.LimitOrderType, "priority_at": gorm.Expr("created_at")}).Error
 }
@@ -0,0 +1,37 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order/application"
+)
+
+type StatusController struct {
+	queryHandler *application.MatcherStatusQueryHandler
+}
+
+func NewStatusController(qh *application.MatcherStatusQueryHandler) *StatusController {
+	return &StatusController{queryHandler: qh}
+}
+
+func (sc *StatusController) GetRoutes() []lib.Route {
+	return []lib.Route{
+		sc.getStatus(),
+	}
+}
+
+func (sc *StatusController) GetRoot() string {
+	return "status"
+}
+
+func (sc *StatusController) GetMiddlewares() []lib.Middleware {
+	return nil
+}
+
+// getStatus reports whether the instance is the active matcher and the books it holds
+func (sc *StatusController) getStatus() lib.Route {
+	return lib.Handle(http.MethodGet, "", func(_ context.Context, _ struct{}) (*application.MatcherStatusDto, error) {
+		return sc.queryHandler.GetStatus(), nil
+	})
+}
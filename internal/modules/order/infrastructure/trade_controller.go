@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+)
+
+type TradeController struct {
+	queryHandler *application.TradeQueryHandler
+}
+
+func NewTradeController(qh *application.TradeQueryHandler) *TradeController {
+	return &TradeController{
+		queryHandler: qh,
+	}
+}
+
+func (tc *TradeController) GetRoutes() []lib.Route {
+	return []lib.Route{
+		tc.listTrades(),
+	}
+}
+
+func (tc *TradeController) GetRoot() string {
+	return "trades"
+}
+
+func (tc *TradeController) GetMiddlewares() []lib.Middleware {
+	return nil
+}
+
+// listTradesRequest an Accept of text/csv or application/x-ndjson streams the trades instead of paging them
+type listTradesRequest struct {
+	OrderID   uint   `query:"orderID"`
+	AccountID string `query:"accountID"`
+	Accept    string `header:"Accept"`
+	Criteria  lib.Criteria
+}
+
+// listTradesResponse Total is missing when the criteria skips the count
+type listTradesResponse struct {
+	Trades     []*application.TradeDto `json:"trades"`
+	Total      *int                    `json:"total,omitempty"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+}
+
+func (tc *TradeController) listTrades() lib.Route {
+	return lib.Handle(http.MethodGet, "", func(ctx context.Context, req listTradesRequest) (any, error) {
+		query := application.ListTradesQuery{
+			Criteria:  req.Criteria,
+			OrderID:   req.OrderID,
+			AccountID: req.AccountID,
+		}
+		if format, ok := lib.NegotiateExportFormat(req.Accept); ok {
+			return lib.ExportResponse(format, func(w io.Writer) error {
+				return tc.queryHandler.ExportTrades(ctx, query, format, w)
+			}), nil
+		}
+		trades, page, err := tc.queryHandler.ListTrades(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		response := &listTradesResponse{Trades: trades, NextCursor: page.NextCursor}
+		if !query.Criteria.SkipTotal {
+			response.Total = &page.Total
+		}
+		return response, nil
+	}).WithResponse(listTradesResponse{}).WithCriteria(order.Trade{}).WithExports()
+}
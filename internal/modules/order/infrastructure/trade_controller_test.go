@@ -0,0 +1,108 @@
+package infrastructure_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/modules/order/infrastructure"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// tradeLister keeps the participant of the last listing it was asked for
+type tradeLister struct {
+	orderID   uint
+	accountID string
+	trades    []*order.Trade
+}
+
+func (tl *tradeLister) List(_ context.Context, _ lib.Criteria, orderID uint, accountID string) ([]*order.Trade, lib.Page, error) {
+	tl.orderID, tl.accountID = orderID, accountID
+	return tl.trades, lib.Page{Total: len(tl.trades)}, nil
+}
+
+func (tl *tradeLister) Stream(_ context.Context, _ lib.Criteria, orderID uint, accountID string, each func(trade *order.Trade) error) error {
+	tl.orderID, tl.accountID = orderID, accountID
+	for _, trade := range tl.trades {
+		if err := each(trade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type TradeControllerTestSuite struct {
+	suite.Suite
+	repository *tradeLister
+	handler    http.Handler
+}
+
+func TestTradeControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(TradeControllerTestSuite))
+}
+
+func (suite *TradeControllerTestSuite) SetupTest() {
+	suite.repository = &tradeLister{trades: []*order.Trade{
+		{ID: 7, Symbol: "BTC-USD", TakerSide: order.BuyOrderSide, Price: lib.NewDecimalFromInt(101), CreatedAt: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+	}}
+	controller := infrastructure.NewTradeController(application.NewTradeQueryHandler(suite.repository))
+	suite.handler = lib.NewHTTPHandler(controller)
+}
+
+func (suite *TradeControllerTestSuite) serve(target, accept string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	recorder := httptest.NewRecorder()
+	suite.handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (suite *TradeControllerTestSuite) TestListTradesOfAParticipant() {
+	recorder := suite.serve("/trades?orderID=3&accountID=maker", "")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal(uint(3), suite.repository.orderID)
+	suite.Equal("maker", suite.repository.accountID)
+
+	var body struct {
+		Trades []*application.TradeDto `json:"trades"`
+		Total  *int                    `json:"total"`
+	}
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &body))
+	suite.Require().Len(body.Trades, 1)
+	suite.Equal(uint(7), body.Trades[0].ID)
+	suite.Require().NotNil(body.Total)
+	suite.Equal(1, *body.Total)
+}
+
+func (suite *TradeControllerTestSuite) TestListTradesRejectsAnInvalidOrderID() {
+	recorder := suite.serve("/trades?orderID=abc", "")
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+
+	var body lib.ErrorBody
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &body))
+	suite.Contains(body.Errors, "orderID")
+}
+
+func (suite *TradeControllerTestSuite) TestListTradesStreamsCSV() {
+	recorder := suite.serve("/trades", "text/csv")
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("text/csv", recorder.Header().Get("Content-Type"))
+	suite.Contains(recorder.Body.String(), "ID,Symbol,")
+	suite.Contains(recorder.Body.String(), "7,BTC-USD,")
+}
+
+func (suite *TradeControllerTestSuite) TestExportsRejectAnInvalidSortOperator() {
+	recorder := suite.serve("/trades?sorts="+url.QueryEscape("price,ASC;DROP TABLE trades"), "text/csv")
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.NotContains(recorder.Body.String(), "7,BTC-USD,")
+}
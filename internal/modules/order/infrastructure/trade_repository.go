@@ -0,0 +1,52 @@
+package infrastructure
+
+import (
+	"context"
+
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/service/provider"
+
+	"gorm.io/gorm"
+)
+
+type TradeRepository struct {
+	session *provider.GormSession
+}
+
+func NewTradeRepository(session *provider.GormSession) *TradeRepository {
+	return &TradeRepository{
+		session: session,
+	}
+}
+
+func (c *TradeRepository) List(ctx context.Context, cr lib.Criteria, orderID uint, accountID string) ([]*order.Trade, lib.Page, error) {
+	participant := func() *gorm.DB {
+		return c.participant(ctx, orderID, accountID)
+	}
+	var trades []*order.Trade
+	page, err := lib.GenericListGormCriteria(participant, order.Trade{}, cr, &trades)
+	if err != nil {
+		return nil, lib.Page{}, err
+	}
+	return trades, page, nil
+}
+
+func (c *TradeRepository) Stream(ctx context.Context, cr lib.Criteria, orderID uint, accountID string, each func(trade *order.Trade) error) error {
+	var trade order.Trade
+	return lib.GenericStreamGormCriteria(c.participant(ctx, orderID, accountID), order.Trade{}, cr, &trade, func() error {
+		return each(&trade)
+	})
+}
+
+// participant keeps the trades the order or the account was either side of
+func (c *TradeRepository) participant(ctx context.Context, orderID uint, accountID string) *gorm.DB {
+	db := c.session.Gorm().WithContext(ctx)
+	if orderID != 0 {
+		db = db.Where("(taker_order_id = ? or maker_order_id = ?)", orderID, orderID)
+	}
+	if accountID != "" {
+		db = db.Where("(taker_account_id = ? or maker_account_id = ?)", accountID, accountID)
+	}
+	return db
+}
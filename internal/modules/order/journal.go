@@ -0,0 +1,59 @@
+package order
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JournalEntryKind separates the inputs of the engine from the events it produced
+type JournalEntryKind string
+
+const (
+	CommandJournalEntryKind JournalEntryKind = "command"
+	EventJournalEntryKind   JournalEntryKind = "event"
+)
+
+type JournalEntryType string
+
+const (
+	CreateOrderJournalEntryType        JournalEntryType = "create_order"
+	CancelOrderJournalEntryType        JournalEntryType = "cancel_order"
+	AmendOrderJournalEntryType         JournalEntryType = "amend_order"
+	UncrossJournalEntryType            JournalEntryType = "uncross"
+	CancelAllJournalEntryType          JournalEntryType = "cancel_all"
+	OrderMatchedJournalEntryType       JournalEntryType = "order_matched"
+	OrderRejectedJournalEntryType      JournalEntryType = "order_rejected"
+	SelfTradePreventedJournalEntryType JournalEntryType = "self_trade_prevented"
+)
+
+// JournalEntry is one record of the append-only journal, Sequence is global and gives the order of the records.
+// An event points to the command that produced it and Payload is the exact message that was published. Reference is
+// the reference data a command read, empty for events
+type JournalEntry struct {
+	Sequence        uint64           `gorm:"primarykey;column:sequence"`
+	CommandSequence uint64           `gorm:"column:command_sequence;index"`
+	Kind            JournalEntryKind `gorm:"column:kind;index"`
+	Type            JournalEntryType `gorm:"column:type"`
+	Symbol          string           `gorm:"column:symbol;index"`
+	Payload         string           `gorm:"column:payload;type:text"`
+	Reference       string           `gorm:"column:reference;type:text"`
+	CreatedAt       time.Time        `gorm:"column:created_at"`
+}
+
+func NewJournalCommand(entryType JournalEntryType, symbol string, command any, at time.Time) (*JournalEntry, error) {
+	payload, err := json.Marshal(command)
+	if err != nil {
+		return nil, err
+	}
+	return &JournalEntry{Kind: CommandJournalEntryKind, Type: entryType, Symbol: symbol, Payload: string(payload), CreatedAt: at}, nil
+}
+
+func NewJournalEvent(entryType JournalEntryType, symbol string, payload string, at time.Time) *JournalEntry {
+	return &JournalEntry{Kind: EventJournalEntryKind, Type: entryType, Symbol: symbol, Payload: payload, CreatedAt: at}
+}
+
+// JournaledCommand is a command with the events it produced in publication order
+type JournaledCommand struct {
+	Command *JournalEntry
+	Events  []*JournalEntry
+}
@@ -1,17 +1,30 @@
 package order
 
 import (
+	"errors"
+	"fmt"
 	"time"
 	"tradeTornado/internal/lib"
 )
 
 type Order struct {
-	ID        uint `gorm:"primarykey;column:id"`
-	CreatedAt time.Time
-	Matched   bool      `criteria:"matched" gorm:"column:matched;index:idx_matched_side_price_quantity,priority:1"`
-	Side      OrderSide `criteria:"side" gorm:"column:side;index:idx_matched_side_price_quantity,priority:2"`
-	Price     int       `criteria:"price" gorm:"column:price;index:idx_matched_side_price_quantity,priority:3"`
-	Quantity  int       `criteria:"quantity" gorm:"column:quantity;index:idx_matched_side_price_quantity,priority:4"`
+	ID                  uint            `gorm:"primarykey;column:id"`
+	CreatedAt           time.Time       `criteria:"created_at" gorm:"column:created_at;index"`
+	AccountID           string          `criteria:"account" gorm:"column:account_id;index"`
+	Symbol              string          `criteria:"symbol" gorm:"column:symbol;index:idx_book,priority:1;index:idx_trigger_book,priority:1"`
+	Status              OrderStatus     `criteria:"status" gorm:"column:status;index:idx_book,priority:2;index:idx_trigger_book,priority:2"`
+	Side                OrderSide       `criteria:"side" gorm:"column:side;index:idx_book,priority:3;index:idx_trigger_book,priority:3"`
+	Price               lib.Decimal     `criteria:"price" gorm:"column:price;index:idx_book,priority:4"`
+	Matched             bool            `criteria:"matched" gorm:"column:matched;index"`
+	Quantity            lib.Decimal     `criteria:"quantity" gorm:"column:quantity;index"`
+	Remaining           lib.Decimal     `criteria:"remaining" gorm:"column:remaining;index"`
+	DisplayQuantity     lib.Decimal     `gorm:"column:display_quantity;default:0"`
+	Hidden              lib.Decimal     `gorm:"column:hidden;default:0"`
+	SelfTradePrevention STPMode         `gorm:"column:stp_mode"`
+	Type                OrderType       `criteria:"type" gorm:"column:type;index:idx_trigger_book,priority:4"`
+	TriggerPrice        lib.Decimal     `gorm:"column:trigger_price;index:idx_trigger_book,priority:5"`
+	PriorityAt          time.Time       `gorm:"column:priority_at"`
+	ExecInstructions    ExecInstruction `gorm:"column:exec_instructions;default:0"`
 }
 
 type OrderSide string
@@ -29,26 +42,247 @@ func (os OrderSide) GetMatchSide() OrderSide {
 	}
 }
 
-func NewOrder(id uint, side string, price int, quantity int) (*Order, error) {
+type OrderType string
+
+const (
+	LimitOrderType     OrderType = "limit"
+	MarketOrderType    OrderType = "market"
+	StopOrderType      OrderType = "stop"
+	StopLimitOrderType OrderType = "stop_limit"
+)
+
+// IsConditional reports whether orders of the type wait for a trigger price before matching
+func (ot OrderType) IsConditional() bool {
+	return ot == StopOrderType || ot == StopLimitOrderType
+}
+
+// HasLimitPrice reports whether orders of the type never trade through their price
+func (ot OrderType) HasLimitPrice() bool {
+	return ot == LimitOrderType || ot == StopLimitOrderType
+}
+
+type OrderStatus string
+
+const (
+	OpenOrderStatus            OrderStatus = "open"
+	PartiallyFilledOrderStatus OrderStatus = "partially_filled"
+	FilledOrderStatus          OrderStatus = "filled"
+	CancelledOrderStatus       OrderStatus = "cancelled"
+	PendingTriggerOrderStatus  OrderStatus = "pending_trigger"
+)
+
+// RestingOrderStatuses are the statuses of orders that are still in the book
+var RestingOrderStatuses = []OrderStatus{OpenOrderStatus, PartiallyFilledOrderStatus}
+
+// LiveOrderStatuses are the statuses of orders that can still be cancelled or amended, in the book or the trigger book
+var LiveOrderStatuses = []OrderStatus{OpenOrderStatus, PartiallyFilledOrderStatus, PendingTriggerOrderStatus}
+
+// NewOrder creates a limit order when orderType is empty, conditional orders wait outside the book for their trigger price.
+// receivedAt is the time priority of the order
+func NewOrder(id uint, accountID string, symbol string, side string, orderType string, price lib.Decimal, triggerPrice lib.Decimal, quantity lib.Decimal, receivedAt time.Time) (*Order, error) {
 	order := &Order{
-		Price:     price,
-		Quantity:  quantity,
-		ID:        id,
-		Side:      OrderSide(side),
-		CreatedAt: time.Now(),
+		Price:        price,
+		Quantity:     quantity,
+		Remaining:    quantity,
+		Status:       OpenOrderStatus,
+		ID:           id,
+		AccountID:    accountID,
+		Symbol:       symbol,
+		Side:         OrderSide(side),
+		Type:         OrderType(orderType),
+		TriggerPrice: triggerPrice,
+		CreatedAt:    receivedAt,
+		PriorityAt:   receivedAt,
+	}
+	if order.Type == "" {
+		order.Type = LimitOrderType
+	}
+	if order.Type.IsConditional() {
+		order.Status = PendingTriggerOrderStatus
 	}
 	return order, order.validate()
 }
 
+// Quantity and Remaining only contain what was ever shown in the book, iceberg reserve stays in Hidden
+func (order *Order) TotalQuantity() lib.Decimal {
+	return order.Quantity + order.Hidden
+}
+
+// Open is the quantity the order can still trade including the hidden reserve
+func (order *Order) Open() lib.Decimal {
+	return order.Remaining + order.Hidden
+}
+
+func (order *Order) IsIceberg() bool {
+	return order.DisplayQuantity > 0
+}
+
+// SetDisplayQuantity turns a new order into an iceberg, only a slice of displayQuantity rests visible in the book
+func (order *Order) SetDisplayQuantity(displayQuantity lib.Decimal) error {
+	if displayQuantity == 0 {
+		return nil
+	}
+	validation := lib.NewErrorNotification()
+	validation.DecimalShouldBePositive("display_quantity", displayQuantity)
+	if displayQuantity >= order.TotalQuantity() {
+		validation.Add("display_quantity", fmt.Errorf("should be less than %s", order.TotalQuantity()))
+	}
+	if !order.Type.HasLimitPrice() {
+		validation.Add("display_quantity", errors.New("only limit orders can have a display quantity"))
+	}
+	if err := validation.Err(); err != nil {
+		return err
+	}
+	order.DisplayQuantity = displayQuantity
+	order.Hidden = order.TotalQuantity() - displayQuantity
+	order.Quantity = displayQuantity
+	order.Remaining = displayQuantity
+	return nil
+}
+
+func (order *Order) Notional() lib.Decimal {
+	return order.Price.Mul(order.TotalQuantity())
+}
+
+func (order *Order) IsResting() bool {
+	return order.Status == OpenOrderStatus || order.Status == PartiallyFilledOrderStatus
+}
+
+// IsTriggeredBy reports whether a pending conditional order is activated by the last trade price
+func (order *Order) IsTriggeredBy(lastTradePrice lib.Decimal) bool {
+	if order.Status != PendingTriggerOrderStatus || lastTradePrice <= 0 {
+		return false
+	}
+	if order.Side == BuyOrderSide {
+		return lastTradePrice >= order.TriggerPrice
+	}
+	return lastTradePrice <= order.TriggerPrice
+}
+
+// Trigger activates a conditional order, stop orders match as market orders and stop limit orders as limit orders
+func (order *Order) Trigger(at time.Time) {
+	order.Status = OpenOrderStatus
+	order.PriorityAt = at
+}
+
+// Crosses reports whether the order can trade against a resting order at price
+func (order *Order) Crosses(price lib.Decimal) bool {
+	if !order.Type.HasLimitPrice() {
+		return true
+	}
+	if order.Side == BuyOrderSide {
+		return order.Price >= price
+	}
+	return order.Price <= price
+}
+
+// Fill executes quantity of the order at the given time, the order is matched when nothing remains
+func (order *Order) Fill(quantity lib.Decimal, at time.Time) {
+	order.reduce(quantity, at)
+	if order.Open() == 0 {
+		order.Match()
+		return
+	}
+	order.Status = PartiallyFilledOrderStatus
+}
+
+// reduce consumes the visible slice first and then the hidden reserve, a consumed slice is replenished
+// from the reserve and loses its time priority to at
+func (order *Order) reduce(quantity lib.Decimal, at time.Time) {
+	fromVisible := min(quantity, order.Remaining)
+	fromHidden := min(quantity-fromVisible, order.Hidden)
+	order.Remaining -= fromVisible
+	order.Hidden -= fromHidden
+	order.Quantity += fromHidden
+	if order.Remaining == 0 && order.Hidden > 0 {
+		slice := min(order.DisplayQuantity, order.Hidden)
+		order.Hidden -= slice
+		order.Remaining += slice
+		order.Quantity += slice
+		order.PriorityAt = at
+	}
+}
+
+// Amend changes the price and the open quantity of a live order, quantity is what the order can still trade.
+// A new price or a larger quantity loses the time priority to at while a smaller quantity keeps it, the reserve of
+// an iceberg absorbs the change before its visible slice
+func (order *Order) Amend(price, quantity lib.Decimal, at time.Time) error {
+	validation := lib.NewErrorNotification()
+	validation.DecimalShouldBePositive("quantity", quantity)
+	if price != order.Price && !order.Type.HasLimitPrice() {
+		validation.Add("price", errors.New("only limit orders can change their price"))
+	}
+	if err := validation.Err(); err != nil {
+		return err
+	}
+	if quantity < order.Open() {
+		decrement := order.Open() - quantity
+		fromHidden := min(decrement, order.Hidden)
+		order.Hidden -= fromHidden
+		order.Remaining -= decrement - fromHidden
+		order.Quantity -= decrement - fromHidden
+	} else if quantity > order.Open() {
+		if order.IsIceberg() {
+			order.Hidden += quantity - order.Open()
+		} else {
+			order.Quantity += quantity - order.Open()
+			order.Remaining = quantity
+		}
+		order.PriorityAt = at
+	}
+	if price != order.Price {
+		order.Price = price
+		order.PriorityAt = at
+	}
+	return order.validate()
+}
+
 func (order *Order) Match() {
 	order.Matched = true
+	order.Status = FilledOrderStatus
+}
+
+// Decrement removes quantity from the order without trading it, the order is cancelled when nothing remains
+func (order *Order) Decrement(quantity lib.Decimal, at time.Time) {
+	order.reduce(quantity, at)
+	if order.Open() == 0 {
+		order.Cancel()
+	}
+}
+
+func (order *Order) Cancel() {
+	order.Status = CancelledOrderStatus
+}
+
+func (order *Order) SetSelfTradePrevention(mode STPMode) error {
+	if err := mode.validate(); err != nil {
+		return err
+	}
+	order.SelfTradePrevention = mode
+	return nil
 }
 
 func (order *Order) validate() error {
 	validation := lib.NewErrorNotification()
 
-	validation.UintShouldBeGT("price", uint(order.Price), 0)
-	validation.UintShouldBeGT("quantity", uint(order.Quantity), 0)
+	validation.StringNotEmpty("account_id", order.AccountID)
+	validation.StringNotEmpty("symbol", order.Symbol)
+
+	validation.DecimalShouldBePositive("quantity", order.Quantity)
+	switch order.Type {
+	case LimitOrderType:
+		validation.DecimalShouldBePositive("price", order.Price)
+	case MarketOrderType:
+		validation.DecimalShouldBeZero("price", order.Price)
+	case StopOrderType:
+		validation.DecimalShouldBeZero("price", order.Price)
+		validation.DecimalShouldBePositive("trigger_price", order.TriggerPrice)
+	case StopLimitOrderType:
+		validation.DecimalShouldBePositive("price", order.Price)
+		validation.DecimalShouldBePositive("trigger_price", order.TriggerPrice)
+	default:
+		validation.Add("type", errors.New("invalid order type"))
+	}
 
 	return validation.Err()
 }
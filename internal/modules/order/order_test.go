@@ -0,0 +1,153 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderTestSuite struct {
+	suite.Suite
+}
+
+func TestOrderTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderTestSuite))
+}
+
+func (suite *OrderTestSuite) newOrder(id uint, side OrderSide, price, quantity, displayQuantity int64) *Order {
+	om, err := NewOrder(id, "account", "BTC-USD", string(side), "limit", dec(price), 0, dec(quantity), time.Unix(int64(id), 0))
+	suite.Require().NoError(err)
+	suite.Require().NoError(om.SetDisplayQuantity(dec(displayQuantity)))
+	return om
+}
+
+func (suite *OrderTestSuite) TestStopsAreTriggeredThroughTheirTriggerPrice() {
+	buy, err := NewOrder(1, "account", "BTC-USD", "buy", "stop", 0, dec(102), dec(1), time.Unix(1, 0))
+	suite.Require().NoError(err)
+	sell, err := NewOrder(2, "account", "BTC-USD", "sell", "stop_limit", dec(97), dec(98), dec(1), time.Unix(2, 0))
+	suite.Require().NoError(err)
+	suite.Equal(PendingTriggerOrderStatus, buy.Status)
+	suite.False(buy.IsResting())
+
+	suite.False(buy.IsTriggeredBy(0))
+	suite.False(buy.IsTriggeredBy(dec(101)))
+	suite.True(buy.IsTriggeredBy(dec(102)))
+	suite.False(sell.IsTriggeredBy(dec(99)))
+	suite.True(sell.IsTriggeredBy(dec(98)))
+
+	buy.Trigger(time.Unix(3, 0))
+	suite.True(buy.IsResting())
+	suite.False(buy.IsTriggeredBy(dec(102)))
+	suite.True(buy.Crosses(dec(1000)))
+	sell.Trigger(time.Unix(3, 0))
+	suite.True(sell.Crosses(dec(97)))
+	suite.False(sell.Crosses(dec(96)))
+}
+
+func (suite *OrderTestSuite) TestFillDrawsFromTheReserve() {
+	iceberg := suite.newOrder(1, BuyOrderSide, 100, 10, 3)
+	suite.Equal(dec(3), iceberg.Remaining)
+	suite.Equal(dec(7), iceberg.Hidden)
+	suite.Equal(dec(10), iceberg.Open())
+
+	iceberg.Fill(dec(5), time.Unix(10, 0))
+	suite.Equal(PartiallyFilledOrderStatus, iceberg.Status)
+	suite.Equal(dec(3), iceberg.Remaining)
+	suite.Equal(dec(2), iceberg.Hidden)
+	suite.Equal(dec(5), iceberg.Open())
+	suite.Equal(dec(10), iceberg.TotalQuantity())
+
+	iceberg.Fill(dec(5), time.Unix(11, 0))
+	suite.Equal(FilledOrderStatus, iceberg.Status)
+	suite.Equal(dec(0), iceberg.Open())
+}
+
+func (suite *OrderTestSuite) TestReplenishedSliceGoesToTheBackOfTheQueue() {
+	iceberg := suite.newOrder(1, SellOrderSide, 100, 10, 3)
+	regular := suite.newOrder(2, SellOrderSide, 100, 5, 0)
+
+	iceberg.Fill(dec(2), time.Unix(10, 0))
+	suite.Equal(time.Unix(1, 0), iceberg.PriorityAt)
+	iceberg.Fill(dec(1), time.Unix(11, 0))
+	suite.Equal(dec(3), iceberg.Remaining)
+	suite.Equal(time.Unix(11, 0), iceberg.PriorityAt)
+
+	buy := suite.newOrder(3, BuyOrderSide, 100, 6, 0)
+	book := []*Order{iceberg, regular, buy}
+	fills := AllocateAuction(book, ComputeEquilibrium(book, dec(0)))
+	suite.Require().Len(fills, 2)
+	suite.Equal(regular, fills[0].Sell)
+	suite.Equal(dec(5), fills[0].Quantity)
+	suite.Equal(iceberg, fills[1].Sell)
+	suite.Equal(dec(1), fills[1].Quantity)
+}
+
+func (suite *OrderTestSuite) TestDepthNeverShowsTheReserve() {
+	book := []*Order{
+		suite.newOrder(1, BuyOrderSide, 100, 10, 2),
+		suite.newOrder(2, BuyOrderSide, 100, 4, 0),
+		suite.newOrder(3, BuyOrderSide, 99, 50, 1),
+		suite.newOrder(4, SellOrderSide, 101, 8, 0),
+	}
+	suite.Equal([]*PriceLevel{
+		{Price: dec(100), Quantity: dec(6), Orders: 2},
+		{Price: dec(99), Quantity: dec(1), Orders: 1},
+	}, AggregateDepth(book, BuyOrderSide, 0))
+	suite.Equal([]*PriceLevel{{Price: dec(100), Quantity: dec(6), Orders: 2}}, AggregateDepth(book, BuyOrderSide, 1))
+}
+
+func (suite *OrderTestSuite) TestIndicativeVolumesOnlyCountTheVisibleQuantity() {
+	book := []*Order{
+		suite.newOrder(1, BuyOrderSide, 101, 20, 2),
+		suite.newOrder(2, SellOrderSide, 100, 5, 0),
+	}
+	indicative := ComputeIndicativeEquilibrium(book, dec(0))
+	suite.Equal(dec(2), indicative.BuyVolume)
+	suite.Equal(dec(5), indicative.SellVolume)
+	suite.Equal(dec(2), indicative.Volume)
+	suite.Equal(dec(-3), indicative.Imbalance)
+
+	uncross := ComputeEquilibrium(book, dec(0))
+	suite.Equal(dec(20), uncross.BuyVolume)
+	suite.Equal(dec(5), uncross.Volume)
+}
+
+func (suite *OrderTestSuite) TestAmendKeepsThePriorityOfSmallerQuantities() {
+	iceberg := suite.newOrder(1, BuyOrderSide, 100, 10, 3)
+	suite.Require().NoError(iceberg.Amend(dec(100), dec(8), time.Unix(10, 0)))
+	suite.Equal(dec(3), iceberg.Remaining)
+	suite.Equal(dec(5), iceberg.Hidden)
+	suite.Equal(time.Unix(1, 0), iceberg.PriorityAt)
+
+	suite.Require().NoError(iceberg.Amend(dec(100), dec(2), time.Unix(11, 0)))
+	suite.Equal(dec(2), iceberg.Remaining)
+	suite.Equal(dec(0), iceberg.Hidden)
+	suite.Equal(dec(2), iceberg.TotalQuantity())
+	suite.Equal(time.Unix(1, 0), iceberg.PriorityAt)
+
+	suite.Require().NoError(iceberg.Amend(dec(100), dec(4), time.Unix(12, 0)))
+	suite.Equal(dec(2), iceberg.Remaining)
+	suite.Equal(dec(2), iceberg.Hidden)
+	suite.Equal(time.Unix(12, 0), iceberg.PriorityAt)
+
+	regular := suite.newOrder(2, SellOrderSide, 101, 5, 0)
+	suite.Require().NoError(regular.Amend(dec(101), dec(7), time.Unix(13, 0)))
+	suite.Equal(dec(7), regular.Remaining)
+	suite.Equal(dec(7), regular.TotalQuantity())
+	suite.Equal(time.Unix(13, 0), regular.PriorityAt)
+
+	suite.Require().NoError(regular.Amend(dec(102), dec(7), time.Unix(14, 0)))
+	suite.Equal(dec(102), regular.Price)
+	suite.Equal(time.Unix(14, 0), regular.PriorityAt)
+}
+
+func (suite *OrderTestSuite) TestAmendRejectsAnEmptyQuantityAndRepricedMarketOrders() {
+	suite.Error(suite.newOrder(1, BuyOrderSide, 100, 10, 0).Amend(dec(100), dec(0), time.Unix(10, 0)))
+
+	market, err := NewOrder(2, "account", "BTC-USD", "sell", "stop", dec(0), dec(90), dec(5), time.Unix(2, 0))
+	suite.Require().NoError(err)
+	suite.Error(market.Amend(dec(95), dec(5), time.Unix(10, 0)))
+	suite.Require().NoError(market.Amend(dec(0), dec(3), time.Unix(10, 0)))
+	suite.Equal(dec(3), market.Remaining)
+}
@@ -2,17 +2,97 @@ package order
 
 import (
 	"context"
+	"time"
 	"tradeTornado/internal/lib"
 )
 
 type IOrderWriteRepository interface {
 	CreateWithHook(ctx context.Context, order *Order, process func(ctx context.Context, Order *Order) error) error
-	SelectForUpdate(ctx context.Context, side OrderSide, price, quantity int, updateFn func(ctx context.Context, Order *Order) error) error
+	// SelectBestLevelForUpdate locks the resting orders of the best price of side in time priority, only prices crossing limitPrice are considered
+	SelectBestLevelForUpdate(ctx context.Context, symbol string, side OrderSide, limitPrice lib.Decimal) ([]*Order, error)
+	// SelectBookForUpdate locks every resting order of symbol and runs process in the same transaction
+	SelectBookForUpdate(ctx context.Context, symbol string, process func(ctx context.Context, book []*Order) error) error
+	// SelectLiveForUpdate locks the order id of symbol and runs process in the same transaction, OrderNotFound is
+	// returned when the order is neither resting nor waiting for its trigger
+	SelectLiveForUpdate(ctx context.Context, symbol string, id uint, process func(ctx context.Context, om *Order) error) error
 	Save(ctx context.Context, cg *Order) error
+	// CancelAll cancels the resting and pending conditional orders of symbol and returns them
+	CancelAll(ctx context.Context, symbol string) ([]*Order, error)
+	// CreateTrade stores the trade with its fee postings
+	CreateTrade(ctx context.Context, trade *Trade) error
+	IOrderMarketRepository
+	ITriggerBook
+	IJournal
+	IOrderHistory
+}
+
+// IOrderHistory keeps the lifecycle of every order, the events are appended in the transaction that applied them
+type IOrderHistory interface {
+	AppendOrderEvents(ctx context.Context, events ...*OrderEvent) error
+}
+
+// IJournal is the append-only log of the engine, a command is appended with the events it produced in the
+// transaction that applied it
+type IJournal interface {
+	AppendJournal(ctx context.Context, command *JournalEntry, events ...*JournalEntry) error
+}
+
+type IJournalReader interface {
+	// ReadJournal returns up to limit commands with a sequence in (after, until] with their events, a zero until reads to the end
+	ReadJournal(ctx context.Context, after, until uint64, limit int) ([]*JournaledCommand, error)
+	// LastJournalSequence is the sequence of the last journaled command, zero for an empty journal
+	LastJournalSequence(ctx context.Context) (uint64, error)
+}
+
+// IReplayRepository is the order repository of offline matchers, the journal they append is taken back after every command
+type IReplayRepository interface {
+	IOrderWriteRepository
+	Resting(ctx context.Context, symbol string) ([]*Order, error)
+	RestingSymbols(ctx context.Context) ([]string, error)
+	Depth(ctx context.Context, symbol string, side OrderSide, levels int) ([]*PriceLevel, error)
+	TakeJournal() []*JournaledCommand
+	// ReserveTradeIDs gives the next trades the ids the database gave them when the journal was written
+	ReserveTradeIDs(ids ...uint)
+}
+
+// ITriggerBook keeps pending conditional orders of every side keyed by their trigger price
+type ITriggerBook interface {
+	// SelectNextTriggeredForUpdate locks the pending order with the highest trigger priority for lastTradePrice, nil when nothing is triggered
+	SelectNextTriggeredForUpdate(ctx context.Context, symbol string, lastTradePrice lib.Decimal) (*Order, error)
+}
+
+// IOrderMarketRepository provides the market snapshot used by pre-trade checks, zero prices mean no data
+type IOrderMarketRepository interface {
+	LastTradePrice(ctx context.Context, symbol string) (lib.Decimal, error)
+	// ReferencePrice is the price of the first trade since the given time, the last trade price when there was none
+	ReferencePrice(ctx context.Context, symbol string, since time.Time) (lib.Decimal, error)
+	BestPrice(ctx context.Context, symbol string, side OrderSide) (lib.Decimal, error)
+	CountOpenOrders(ctx context.Context, symbol, accountID string) (int, error)
+	// NetPosition is the traded quantity bought minus sold by the account
+	NetPosition(ctx context.Context, symbol, accountID string) (lib.Decimal, error)
 }
 
 type IOrderReadRepository interface {
-	List(ctx context.Context, cr lib.Criteria) ([]*Order, int, error)
+	Get(ctx context.Context, id uint) (*Order, error)
+	List(ctx context.Context, cr lib.Criteria) ([]*Order, lib.Page, error)
+	// Project lists the fields or the aggregates the criteria projects the orders to
+	Project(ctx context.Context, cr lib.Criteria) ([]lib.Row, lib.Page, error)
+	// Stream reads the orders of the criteria one at a time, each should not keep the order it is given
+	Stream(ctx context.Context, cr lib.Criteria, each func(om *Order) error) error
+	StreamProjection(ctx context.Context, cr lib.Criteria, each func(row lib.Row) error) error
+	// ListOrderEvents returns the lifecycle of the order oldest first
+	ListOrderEvents(ctx context.Context, orderID uint) ([]*OrderEvent, error)
+	Depth(ctx context.Context, symbol string, side OrderSide, levels int) ([]*PriceLevel, error)
+	Resting(ctx context.Context, symbol string) ([]*Order, error)
+	RestingSymbols(ctx context.Context) ([]string, error)
+	LastTradePrice(ctx context.Context, symbol string) (lib.Decimal, error)
+}
+
+// ITradeReadRepository lists the executions, a non zero orderID or a non empty accountID matches either side of a trade
+type ITradeReadRepository interface {
+	List(ctx context.Context, cr lib.Criteria, orderID uint, accountID string) ([]*Trade, lib.Page, error)
+	// Stream reads the trades of the criteria one at a time, each should not keep the trade it is given
+	Stream(ctx context.Context, cr lib.Criteria, orderID uint, accountID string, each func(trade *Trade) error) error
 }
 
 type IOrderBook interface {
@@ -26,4 +106,51 @@ type IOrderGenericRepository interface {
 	IOrderWriteRepository
 }
 
+type IFeeSchedule interface {
+	GetFeeRates(ctx context.Context, accountID string) (makerRateBps int, takerRateBps int, err error)
+}
+
+type ISelfTradePolicy interface {
+	GetSTPMode(ctx context.Context, accountID string) (STPMode, error)
+}
+
+type IRiskLimitsProvider interface {
+	GetRiskLimits(ctx context.Context, symbol string) (RiskLimits, error)
+}
+
+type ITradingRulesProvider interface {
+	GetTradingRules(ctx context.Context, symbol string) (TradingRules, error)
+}
+
+// IBookReplica is told about the orders the matcher committed so in-memory books follow the database
+type IBookReplica interface {
+	Apply(ctx context.Context, symbol string, orders ...*Order)
+	Reset(ctx context.Context, symbol string)
+}
+
+type IAllocationRulesProvider interface {
+	GetAllocationRules(ctx context.Context, symbol string) (AllocationRules, error)
+}
+
+type IVolatilityBandProvider interface {
+	GetVolatilityBand(ctx context.Context, symbol string) (VolatilityBand, error)
+}
+
+// ICircuitBreaker moves the trading session of a symbol out of continuous trading after a volatility band breach
+type ICircuitBreaker interface {
+	Trip(ctx context.Context, symbol string) error
+}
+
+// OrderTransaction is an order repository and a circuit breaker sharing one database session, so a volatility band
+// breach moves the session out of continuous trading in the transaction of the trades before it
+type OrderTransaction struct {
+	Orders         IOrderWriteRepository
+	CircuitBreaker ICircuitBreaker
+}
+
+// ITradingSessionProvider returns the current phase of the trading session of a symbol
+type ITradingSessionProvider interface {
+	GetTradingPhase(ctx context.Context, symbol string) (TradingPhase, error)
+}
+
 // type IOrderCacheRepository interface{}
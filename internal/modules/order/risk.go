@@ -0,0 +1,158 @@
+package order
+
+import (
+	"fmt"
+	"tradeTornado/internal/lib"
+)
+
+type RejectReason string
+
+const (
+	InvalidOrderRejectReason  RejectReason = "INVALID_ORDER"
+	MaxNotionalRejectReason   RejectReason = "MAX_NOTIONAL_EXCEEDED"
+	MaxQuantityRejectReason   RejectReason = "MAX_QUANTITY_EXCEEDED"
+	PriceCollarRejectReason   RejectReason = "PRICE_OUTSIDE_COLLAR"
+	MaxOpenOrdersRejectReason RejectReason = "MAX_OPEN_ORDERS_EXCEEDED"
+	FatFingerRejectReason     RejectReason = "FAT_FINGER_PRICE"
+	PostOnlyRejectReason      RejectReason = "POST_ONLY_WOULD_TAKE"
+	ReduceOnlyRejectReason    RejectReason = "REDUCE_ONLY_WOULD_INCREASE"
+	TradingHaltedRejectReason RejectReason = "TRADING_HALTED"
+	MarketClosedRejectReason  RejectReason = "MARKET_CLOSED"
+)
+
+// OrderRejected is returned when an order must not enter the book, Reason is machine readable
+type OrderRejected struct {
+	Reason  RejectReason
+	Message string
+}
+
+func (r *OrderRejected) Error() string {
+	return fmt.Sprintf("order rejected (%s): %s", r.Reason, r.Message)
+}
+
+func NewOrderRejected(reason RejectReason, format string, args ...any) *OrderRejected {
+	return &OrderRejected{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// RiskLimits are configured per instrument, zero value disables the related check
+type RiskLimits struct {
+	MaxOrderNotional lib.Decimal
+	MaxOrderQuantity lib.Decimal
+	PriceCollarBps   int
+	MaxOpenOrders    int
+	FatFingerBps     int
+}
+
+// RiskContext is the market snapshot the checks are evaluated against, zero prices mean unknown
+type RiskContext struct {
+	Limits            RiskLimits
+	LastTradePrice    lib.Decimal
+	BestOppositePrice lib.Decimal
+	OpenOrders        int
+}
+
+type IRiskCheck interface {
+	Check(order *Order, rc RiskContext) error
+}
+
+// RiskChain runs checks in order and stops at the first rejection
+type RiskChain []IRiskCheck
+
+func NewRiskChain(checks ...IRiskCheck) RiskChain {
+	return checks
+}
+
+func (chain RiskChain) Check(order *Order, rc RiskContext) error {
+	for _, check := range chain {
+		if err := check.Check(order, rc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultRiskChain contains every pre-trade check shipped with the matcher
+func DefaultRiskChain() RiskChain {
+	return NewRiskChain(
+		MaxQuantityCheck{},
+		MaxNotionalCheck{},
+		PriceCollarCheck{},
+		FatFingerCheck{},
+		MaxOpenOrdersCheck{},
+	)
+}
+
+type MaxQuantityCheck struct{}
+
+func (MaxQuantityCheck) Check(order *Order, rc RiskContext) error {
+	if rc.Limits.MaxOrderQuantity > 0 && order.TotalQuantity() > rc.Limits.MaxOrderQuantity {
+		return NewOrderRejected(MaxQuantityRejectReason, "quantity %s is more than %s", order.TotalQuantity(), rc.Limits.MaxOrderQuantity)
+	}
+	return nil
+}
+
+// MaxNotionalCheck values orders without a limit price at the last trade price, or the best opposite price before
+// the first trade. They are rejected when neither is known since their notional can not be bounded
+type MaxNotionalCheck struct{}
+
+func (MaxNotionalCheck) Check(order *Order, rc RiskContext) error {
+	if rc.Limits.MaxOrderNotional <= 0 {
+		return nil
+	}
+	notional := order.Notional()
+	if !order.Type.HasLimitPrice() {
+		reference := rc.LastTradePrice
+		if reference <= 0 {
+			reference = rc.BestOppositePrice
+		}
+		if reference <= 0 {
+			return NewOrderRejected(MaxNotionalRejectReason, "notional of a %s order is unknown without a reference price", order.Type)
+		}
+		notional = reference.Mul(order.TotalQuantity())
+	}
+	if notional > rc.Limits.MaxOrderNotional {
+		return NewOrderRejected(MaxNotionalRejectReason, "notional %s is more than %s", notional, rc.Limits.MaxOrderNotional)
+	}
+	return nil
+}
+
+// PriceCollarCheck keeps prices in a band around the last trade price
+type PriceCollarCheck struct{}
+
+func (PriceCollarCheck) Check(order *Order, rc RiskContext) error {
+	if rc.Limits.PriceCollarBps <= 0 || rc.LastTradePrice <= 0 || !order.Type.HasLimitPrice() {
+		return nil
+	}
+	if deviationBps(order.Price, rc.LastTradePrice) > rc.Limits.PriceCollarBps {
+		return NewOrderRejected(PriceCollarRejectReason, "price %s is more than %d bps away from last trade price %s", order.Price, rc.Limits.PriceCollarBps, rc.LastTradePrice)
+	}
+	return nil
+}
+
+// FatFingerCheck rejects aggressive prices far through the best opposite price
+type FatFingerCheck struct{}
+
+func (FatFingerCheck) Check(order *Order, rc RiskContext) error {
+	if rc.Limits.FatFingerBps <= 0 || rc.BestOppositePrice <= 0 || !order.Type.HasLimitPrice() {
+		return nil
+	}
+	aggressive := (order.Side == BuyOrderSide && order.Price > rc.BestOppositePrice) ||
+		(order.Side == SellOrderSide && order.Price < rc.BestOppositePrice)
+	if aggressive && deviationBps(order.Price, rc.BestOppositePrice) > rc.Limits.FatFingerBps {
+		return NewOrderRejected(FatFingerRejectReason, "price %s is more than %d bps through best %s price %s", order.Price, rc.Limits.FatFingerBps, order.Side.GetMatchSide(), rc.BestOppositePrice)
+	}
+	return nil
+}
+
+type MaxOpenOrdersCheck struct{}
+
+func (MaxOpenOrdersCheck) Check(order *Order, rc RiskContext) error {
+	if rc.Limits.MaxOpenOrders > 0 && rc.OpenOrders >= rc.Limits.MaxOpenOrders {
+		return NewOrderRejected(MaxOpenOrdersRejectReason, "account %s already has %d open orders", order.AccountID, rc.OpenOrders)
+	}
+	return nil
+}
+
+func deviationBps(price, reference lib.Decimal) int {
+	return int((price - reference).Abs().MulInt(basisPointsPerUnit).Div(reference).IntPart())
+}
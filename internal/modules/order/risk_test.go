@@ -0,0 +1,140 @@
+package order
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RiskTestSuite struct {
+	suite.Suite
+}
+
+func TestRiskTestSuite(t *testing.T) {
+	suite.Run(t, new(RiskTestSuite))
+}
+
+type riskCase struct {
+	name     string
+	side     OrderSide
+	price    string
+	quantity int64
+	display  int64
+	// trigger makes a stop order of the case
+	trigger int64
+	rc      RiskContext
+	reason  RejectReason
+}
+
+func (suite *RiskTestSuite) run(check IRiskCheck, cases []riskCase) {
+	for _, tc := range cases {
+		orderType, price := "limit", lib.MustParseDecimal(tc.price)
+		if price == 0 {
+			orderType = "market"
+		}
+		if tc.trigger > 0 {
+			orderType = "stop_" + orderType
+			if price == 0 {
+				orderType = "stop"
+			}
+		}
+		om, err := NewOrder(1, "account", "BTC-USD", string(tc.side), orderType, price, dec(tc.trigger), dec(tc.quantity), time.Unix(1, 0))
+		suite.Require().NoError(err, tc.name)
+		suite.Require().NoError(om.SetDisplayQuantity(dec(tc.display)), tc.name)
+
+		err = check.Check(om, tc.rc)
+		if tc.reason == "" {
+			suite.NoError(err, tc.name)
+			continue
+		}
+		var rejected *OrderRejected
+		suite.Require().True(errors.As(err, &rejected), tc.name)
+		suite.Equal(tc.reason, rejected.Reason, tc.name)
+	}
+}
+
+func (suite *RiskTestSuite) TestMaxQuantity() {
+	limits := RiskContext{Limits: RiskLimits{MaxOrderQuantity: dec(10)}}
+	suite.run(MaxQuantityCheck{}, []riskCase{
+		{name: "at the limit", side: BuyOrderSide, price: "100", quantity: 10, rc: limits},
+		{name: "over the limit", side: SellOrderSide, price: "100", quantity: 11, rc: limits, reason: MaxQuantityRejectReason},
+		{name: "iceberg reserve counts", side: BuyOrderSide, price: "100", quantity: 11, display: 2, rc: limits, reason: MaxQuantityRejectReason},
+		{name: "market order", side: BuyOrderSide, price: "0", quantity: 11, rc: limits, reason: MaxQuantityRejectReason},
+		{name: "disabled", side: BuyOrderSide, price: "100", quantity: 1000},
+	})
+}
+
+func (suite *RiskTestSuite) TestMaxNotional() {
+	limits := RiskContext{Limits: RiskLimits{MaxOrderNotional: dec(1000)}}
+	suite.run(MaxNotionalCheck{}, []riskCase{
+		{name: "at the limit", side: BuyOrderSide, price: "100", quantity: 10, rc: limits},
+		{name: "over the limit", side: SellOrderSide, price: "100.01", quantity: 10, rc: limits, reason: MaxNotionalRejectReason},
+		{name: "iceberg reserve counts", side: BuyOrderSide, price: "100", quantity: 11, display: 1, rc: limits, reason: MaxNotionalRejectReason},
+		{name: "stop limit at its limit price", side: BuyOrderSide, price: "100", trigger: 90, quantity: 11, rc: limits, reason: MaxNotionalRejectReason},
+		{name: "disabled", side: BuyOrderSide, price: "100", quantity: 1000},
+	})
+}
+
+func (suite *RiskTestSuite) TestMaxNotionalValuesPricelessOrdersAtTheMarket() {
+	limits := RiskLimits{MaxOrderNotional: dec(1000)}
+	suite.run(MaxNotionalCheck{}, []riskCase{
+		{name: "market at the last trade", side: BuyOrderSide, price: "0", quantity: 10, rc: RiskContext{Limits: limits, LastTradePrice: dec(100), BestOppositePrice: dec(200)}},
+		{name: "market over the limit", side: SellOrderSide, price: "0", quantity: 11, rc: RiskContext{Limits: limits, LastTradePrice: dec(100)}, reason: MaxNotionalRejectReason},
+		{name: "market at the best price before the first trade", side: BuyOrderSide, price: "0", quantity: 6, rc: RiskContext{Limits: limits, BestOppositePrice: dec(200)}, reason: MaxNotionalRejectReason},
+		{name: "market without a reference price", side: BuyOrderSide, price: "0", quantity: 1, rc: RiskContext{Limits: limits}, reason: MaxNotionalRejectReason},
+		{name: "stop at the last trade", side: SellOrderSide, price: "0", trigger: 90, quantity: 10, rc: RiskContext{Limits: limits, LastTradePrice: dec(100)}},
+		{name: "stop over the limit", side: SellOrderSide, price: "0", trigger: 90, quantity: 11, rc: RiskContext{Limits: limits, LastTradePrice: dec(100)}, reason: MaxNotionalRejectReason},
+		{name: "stop without a reference price", side: BuyOrderSide, price: "0", trigger: 110, quantity: 1, rc: RiskContext{Limits: limits}, reason: MaxNotionalRejectReason},
+		{name: "disabled", side: BuyOrderSide, price: "0", quantity: 1000},
+	})
+}
+
+func (suite *RiskTestSuite) TestPriceCollar() {
+	limits := RiskContext{Limits: RiskLimits{PriceCollarBps: 100}, LastTradePrice: dec(100)}
+	suite.run(PriceCollarCheck{}, []riskCase{
+		{name: "on the upper band", side: BuyOrderSide, price: "101", quantity: 1, rc: limits},
+		{name: "on the lower band", side: SellOrderSide, price: "99", quantity: 1, rc: limits},
+		{name: "above the band", side: SellOrderSide, price: "101.02", quantity: 1, rc: limits, reason: PriceCollarRejectReason},
+		{name: "below the band", side: BuyOrderSide, price: "98.98", quantity: 1, rc: limits, reason: PriceCollarRejectReason},
+		{name: "market order", side: BuyOrderSide, price: "0", quantity: 1, rc: limits},
+		{name: "no trade yet", side: BuyOrderSide, price: "200", quantity: 1, rc: RiskContext{Limits: limits.Limits}},
+		{name: "disabled", side: BuyOrderSide, price: "200", quantity: 1, rc: RiskContext{LastTradePrice: dec(100)}},
+	})
+}
+
+func (suite *RiskTestSuite) TestFatFinger() {
+	limits := RiskContext{Limits: RiskLimits{FatFingerBps: 500}, BestOppositePrice: dec(100)}
+	suite.run(FatFingerCheck{}, []riskCase{
+		{name: "buy on the limit", side: BuyOrderSide, price: "105", quantity: 1, rc: limits},
+		{name: "buy through the limit", side: BuyOrderSide, price: "105.01", quantity: 1, rc: limits, reason: FatFingerRejectReason},
+		{name: "sell through the limit", side: SellOrderSide, price: "94.99", quantity: 1, rc: limits, reason: FatFingerRejectReason},
+		{name: "passive buy far from the best", side: BuyOrderSide, price: "50", quantity: 1, rc: limits},
+		{name: "passive sell far from the best", side: SellOrderSide, price: "200", quantity: 1, rc: limits},
+		{name: "market order", side: BuyOrderSide, price: "0", quantity: 1, rc: limits},
+		{name: "empty opposite side", side: BuyOrderSide, price: "200", quantity: 1, rc: RiskContext{Limits: limits.Limits}},
+		{name: "disabled", side: BuyOrderSide, price: "200", quantity: 1, rc: RiskContext{BestOppositePrice: dec(100)}},
+	})
+}
+
+func (suite *RiskTestSuite) TestMaxOpenOrders() {
+	limits := RiskLimits{MaxOpenOrders: 2}
+	suite.run(MaxOpenOrdersCheck{}, []riskCase{
+		{name: "below the limit", side: BuyOrderSide, price: "100", quantity: 1, rc: RiskContext{Limits: limits, OpenOrders: 1}},
+		{name: "at the limit", side: BuyOrderSide, price: "100", quantity: 1, rc: RiskContext{Limits: limits, OpenOrders: 2}, reason: MaxOpenOrdersRejectReason},
+		{name: "disabled", side: BuyOrderSide, price: "100", quantity: 1, rc: RiskContext{OpenOrders: 100}},
+	})
+}
+
+func (suite *RiskTestSuite) TestTheChainStopsAtTheFirstRejection() {
+	rc := RiskContext{Limits: RiskLimits{MaxOrderQuantity: dec(10), MaxOrderNotional: dec(100), MaxOpenOrders: 1}, OpenOrders: 1}
+	suite.run(DefaultRiskChain(), []riskCase{
+		{name: "quantity first", side: BuyOrderSide, price: "100", quantity: 11, rc: rc, reason: MaxQuantityRejectReason},
+		{name: "then notional", side: BuyOrderSide, price: "100", quantity: 2, rc: rc, reason: MaxNotionalRejectReason},
+		{name: "open orders last", side: BuyOrderSide, price: "100", quantity: 1, rc: rc, reason: MaxOpenOrdersRejectReason},
+		{name: "accepted", side: BuyOrderSide, price: "100", quantity: 1, rc: RiskContext{Limits: rc.Limits}},
+	})
+}
@@ -0,0 +1,63 @@
+package order
+
+import (
+	"fmt"
+	"time"
+	"tradeTornado/internal/lib"
+)
+
+type TradingPhase string
+
+const (
+	PreOpenTradingPhase    TradingPhase = "pre_open"
+	AuctionTradingPhase    TradingPhase = "auction"
+	ContinuousTradingPhase TradingPhase = "continuous"
+	HaltedTradingPhase     TradingPhase = "halted"
+	ClosedTradingPhase     TradingPhase = "closed"
+)
+
+// AcceptsOrders reports whether new orders enter the book during the phase
+func (tp TradingPhase) AcceptsOrders() bool {
+	return tp == PreOpenTradingPhase || tp == AuctionTradingPhase || tp == ContinuousTradingPhase
+}
+
+// IsContinuous reports whether incoming orders are matched on arrival, in the other phases they only rest
+func (tp TradingPhase) IsContinuous() bool {
+	return tp == ContinuousTradingPhase
+}
+
+// RejectReason is the reason given to orders arriving while the phase does not accept them
+func (tp TradingPhase) RejectReason() RejectReason {
+	if tp == HaltedTradingPhase {
+		return TradingHaltedRejectReason
+	}
+	return MarketClosedRejectReason
+}
+
+// VolatilityBand stops continuous trading when an execution moves more than BandBps away from the reference price,
+// the reference is the first trade of the trailing Window. Phase is the phase the session is moved to
+type VolatilityBand struct {
+	BandBps int
+	Window  time.Duration
+	Phase   TradingPhase
+}
+
+func (vb VolatilityBand) Enabled() bool {
+	return vb.BandBps > 0
+}
+
+// Breached reports whether executing at price leaves the band, unknown reference prices never breach
+func (vb VolatilityBand) Breached(price, referencePrice lib.Decimal) bool {
+	return vb.Enabled() && referencePrice > 0 && deviationBps(price, referencePrice) > vb.BandBps
+}
+
+// CircuitBreakerTripped is returned by the matcher when an execution was stopped by the volatility band
+type CircuitBreakerTripped struct {
+	Symbol         string
+	Price          lib.Decimal
+	ReferencePrice lib.Decimal
+}
+
+func (cbt *CircuitBreakerTripped) Error() string {
+	return fmt.Sprintf("circuit breaker tripped on %s: price %s is outside the band of reference price %s", cbt.Symbol, cbt.Price, cbt.ReferencePrice)
+}
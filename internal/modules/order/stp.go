@@ -0,0 +1,74 @@
+package order
+
+import (
+	"errors"
+	"time"
+
+	"tradeTornado/internal/lib"
+)
+
+// STPMode decides what happens when an incoming order would trade against a resting order of the same account
+type STPMode string
+
+const (
+	NoneSTPMode               STPMode = ""
+	CancelNewestSTPMode       STPMode = "cancel_newest"
+	CancelOldestSTPMode       STPMode = "cancel_oldest"
+	CancelBothSTPMode         STPMode = "cancel_both"
+	DecrementAndCancelSTPMode STPMode = "decrement_and_cancel"
+)
+
+func (mode STPMode) validate() error {
+	switch mode {
+	case NoneSTPMode, CancelNewestSTPMode, CancelOldestSTPMode, CancelBothSTPMode, DecrementAndCancelSTPMode:
+		return nil
+	}
+	validation := lib.NewErrorNotification()
+	validation.Add("stp_mode", errors.New("invalid self trade prevention mode"))
+	return validation.Err()
+}
+
+func ParseSTPMode(mode string) (STPMode, error) {
+	stp := STPMode(mode)
+	return stp, stp.validate()
+}
+
+// SelfTradePrevented describes how a self trade between the incoming taker and the resting maker was resolved
+type SelfTradePrevented struct {
+	Mode                STPMode
+	Taker               *Order
+	Maker               *Order
+	CancelledOrderIDs   []uint
+	DecrementedQuantity lib.Decimal
+}
+
+func IsSelfTrade(taker, maker *Order) bool {
+	return taker.AccountID == maker.AccountID
+}
+
+// PreventSelfTrade applies mode on both orders at the given time, with NoneSTPMode the orders are allowed to trade and nil is returned
+func PreventSelfTrade(mode STPMode, taker, maker *Order, at time.Time) *SelfTradePrevented {
+	if mode == NoneSTPMode || !IsSelfTrade(taker, maker) {
+		return nil
+	}
+	prevented := &SelfTradePrevented{Mode: mode, Taker: taker, Maker: maker}
+	switch mode {
+	case CancelNewestSTPMode:
+		taker.Cancel()
+	case CancelOldestSTPMode:
+		maker.Cancel()
+	case CancelBothSTPMode:
+		taker.Cancel()
+		maker.Cancel()
+	case DecrementAndCancelSTPMode:
+		prevented.DecrementedQuantity = min(taker.Open(), maker.Open())
+		taker.Decrement(prevented.DecrementedQuantity, at)
+		maker.Decrement(prevented.DecrementedQuantity, at)
+	}
+	for _, or := range []*Order{taker, maker} {
+		if or.Status == CancelledOrderStatus {
+			prevented.CancelledOrderIDs = append(prevented.CancelledOrderIDs, or.ID)
+		}
+	}
+	return prevented
+}
@@ -0,0 +1,74 @@
+package order
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type STPTestSuite struct {
+	suite.Suite
+}
+
+func TestSTPTestSuite(t *testing.T) {
+	suite.Run(t, new(STPTestSuite))
+}
+
+func (suite *STPTestSuite) newOrder(id uint, accountID string, side OrderSide, quantity int64) *Order {
+	om, err := NewOrder(id, accountID, "BTC-USD", string(side), "limit", dec(100), 0, dec(quantity), time.Unix(int64(id), 0))
+	suite.Require().NoError(err)
+	return om
+}
+
+func (suite *STPTestSuite) TestEveryModeResolvesTheSelfTrade() {
+	for _, tc := range []struct {
+		mode        STPMode
+		taker       int64
+		maker       int64
+		takerOpen   lib.Decimal
+		makerOpen   lib.Decimal
+		cancelled   []uint
+		decremented lib.Decimal
+	}{
+		{mode: CancelNewestSTPMode, taker: 5, maker: 3, takerOpen: dec(5), makerOpen: dec(3), cancelled: []uint{2}},
+		{mode: CancelOldestSTPMode, taker: 5, maker: 3, takerOpen: dec(5), makerOpen: dec(3), cancelled: []uint{1}},
+		{mode: CancelBothSTPMode, taker: 5, maker: 3, takerOpen: dec(5), makerOpen: dec(3), cancelled: []uint{2, 1}},
+		{mode: DecrementAndCancelSTPMode, taker: 5, maker: 3, takerOpen: dec(2), makerOpen: dec(0), cancelled: []uint{1}, decremented: dec(3)},
+		{mode: DecrementAndCancelSTPMode, taker: 2, maker: 3, takerOpen: dec(0), makerOpen: dec(1), cancelled: []uint{2}, decremented: dec(2)},
+		{mode: DecrementAndCancelSTPMode, taker: 3, maker: 3, takerOpen: dec(0), makerOpen: dec(0), cancelled: []uint{2, 1}, decremented: dec(3)},
+	} {
+		maker, taker := suite.newOrder(1, "account", SellOrderSide, tc.maker), suite.newOrder(2, "account", BuyOrderSide, tc.taker)
+		prevented := PreventSelfTrade(tc.mode, taker, maker, time.Unix(3, 0))
+		suite.Require().NotNil(prevented, tc.mode)
+		suite.Equal(tc.mode, prevented.Mode)
+		suite.Equal(tc.cancelled, prevented.CancelledOrderIDs, tc.mode)
+		suite.Equal(tc.decremented, prevented.DecrementedQuantity, tc.mode)
+		suite.Equal(tc.takerOpen, taker.Open(), tc.mode)
+		suite.Equal(tc.makerOpen, maker.Open(), tc.mode)
+		for _, om := range []*Order{taker, maker} {
+			suite.Equal(om.Status == CancelledOrderStatus, slices.Contains(tc.cancelled, om.ID), tc.mode)
+		}
+	}
+}
+
+func (suite *STPTestSuite) TestOrdersOfDifferentAccountsOrWithoutModeTrade() {
+	maker, taker := suite.newOrder(1, "maker", SellOrderSide, 3), suite.newOrder(2, "taker", BuyOrderSide, 5)
+	suite.Nil(PreventSelfTrade(CancelBothSTPMode, taker, maker, time.Unix(3, 0)))
+
+	maker, taker = suite.newOrder(1, "account", SellOrderSide, 3), suite.newOrder(2, "account", BuyOrderSide, 5)
+	suite.Nil(PreventSelfTrade(NoneSTPMode, taker, maker, time.Unix(3, 0)))
+	suite.Equal(OpenOrderStatus, taker.Status)
+	suite.Equal(OpenOrderStatus, maker.Status)
+}
+
+func (suite *STPTestSuite) TestUnknownModesAreRejected() {
+	_, err := ParseSTPMode("cancel_everything")
+	suite.Error(err)
+	mode, err := ParseSTPMode("cancel_oldest")
+	suite.NoError(err)
+	suite.Equal(CancelOldestSTPMode, mode)
+}
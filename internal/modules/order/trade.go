@@ -0,0 +1,82 @@
+package order
+
+import (
+	"time"
+	"tradeTornado/internal/lib"
+)
+
+const basisPointsPerUnit = 10000
+
+// Trade is a single execution between an incoming (taker) order and a resting (maker) order
+type Trade struct {
+	ID             uint        `criteria:"id" gorm:"primarykey;column:id"`
+	CreatedAt      time.Time   `criteria:"created_at" gorm:"column:created_at;index:idx_symbol_created_at,priority:2"`
+	Symbol         string      `criteria:"symbol" gorm:"column:symbol;index:idx_symbol_created_at,priority:1;index:idx_symbol_price,priority:1"`
+	TakerOrderID   uint        `criteria:"taker_order_id" gorm:"column:taker_order_id;index"`
+	MakerOrderID   uint        `criteria:"maker_order_id" gorm:"column:maker_order_id;index"`
+	TakerAccountID string      `criteria:"taker_account" gorm:"column:taker_account_id;index"`
+	MakerAccountID string      `criteria:"maker_account" gorm:"column:maker_account_id;index"`
+	TakerSide      OrderSide   `gorm:"column:taker_side"`
+	Price          lib.Decimal `criteria:"price" gorm:"column:price;index:idx_symbol_price,priority:2"`
+	Quantity       lib.Decimal `gorm:"column:quantity"`
+	TakerFee       lib.Decimal `gorm:"column:taker_fee"`
+	MakerFee       lib.Decimal `gorm:"column:maker_fee"`
+	FeeAccountID   string      `gorm:"column:fee_account_id"`
+}
+
+func NewTrade(taker, maker *Order, price, quantity lib.Decimal, at time.Time) *Trade {
+	return &Trade{
+		CreatedAt:      at,
+		Symbol:         taker.Symbol,
+		TakerOrderID:   taker.ID,
+		MakerOrderID:   maker.ID,
+		TakerAccountID: taker.AccountID,
+		MakerAccountID: maker.AccountID,
+		TakerSide:      taker.Side,
+		Price:          price,
+		Quantity:       quantity,
+	}
+}
+
+func (t *Trade) Notional() lib.Decimal {
+	return t.Price.Mul(t.Quantity)
+}
+
+// ApplyFees charges both sides of the trade based on their rates in basis points of the exact notional, negative
+// rates are rebates. Fees are rounded up to the decimal scale, a charge is never short of its rate and a rebate
+// never exceeds it
+func (t *Trade) ApplyFees(makerRateBps, takerRateBps int, feeAccountID string) {
+	t.MakerFee = t.Price.MulFracCeil(t.Quantity, int64(makerRateBps), basisPointsPerUnit)
+	t.TakerFee = t.Price.MulFracCeil(t.Quantity, int64(takerRateBps), basisPointsPerUnit)
+	t.FeeAccountID = feeAccountID
+}
+
+// FeePostings are the ledger entries of the fees, every fee is taken from the account that pays it and given to
+// the fee account. A rebate is a negative fee so it goes the other way, the postings of a trade always sum to zero
+func (t *Trade) FeePostings() []*FeePosting {
+	var postings []*FeePosting
+	for _, charged := range []struct {
+		accountID string
+		fee       lib.Decimal
+	}{{t.MakerAccountID, t.MakerFee}, {t.TakerAccountID, t.TakerFee}} {
+		if charged.fee.IsZero() {
+			continue
+		}
+		postings = append(postings,
+			&FeePosting{TradeID: t.ID, Symbol: t.Symbol, AccountID: charged.accountID, Amount: -charged.fee, CreatedAt: t.CreatedAt},
+			&FeePosting{TradeID: t.ID, Symbol: t.Symbol, AccountID: t.FeeAccountID, Amount: charged.fee, CreatedAt: t.CreatedAt},
+		)
+	}
+	return postings
+}
+
+// FeePosting is one entry of the fee ledger, the balance of an account is the sum of its amounts in the quote
+// currency of the symbols
+type FeePosting struct {
+	ID        uint        `gorm:"primarykey;column:id"`
+	TradeID   uint        `gorm:"column:trade_id;index"`
+	Symbol    string      `gorm:"column:symbol"`
+	AccountID string      `gorm:"column:account_id;index"`
+	Amount    lib.Decimal `gorm:"column:amount"`
+	CreatedAt time.Time   `gorm:"column:created_at"`
+}
@@ -0,0 +1,72 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"tradeTornado/internal/lib"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TradeTestSuite struct {
+	suite.Suite
+}
+
+func TestTradeTestSuite(t *testing.T) {
+	suite.Run(t, new(TradeTestSuite))
+}
+
+func (suite *TradeTestSuite) newTrade(price, quantity string) *Trade {
+	taker, err := NewOrder(1, "taker", "BTC-USD", "buy", "limit", lib.MustParseDecimal(price), 0, lib.MustParseDecimal(quantity), time.Unix(1, 0))
+	suite.Require().NoError(err)
+	maker, err := NewOrder(2, "maker", "BTC-USD", "sell", "limit", lib.MustParseDecimal(price), 0, lib.MustParseDecimal(quantity), time.Unix(2, 0))
+	suite.Require().NoError(err)
+	trade := NewTrade(taker, maker, lib.MustParseDecimal(price), lib.MustParseDecimal(quantity), time.Unix(3, 0))
+	trade.ID = 7
+	return trade
+}
+
+func (suite *TradeTestSuite) TestMakerAndTakerPayTheirOwnRate() {
+	trade := suite.newTrade("20000", "0.5")
+	trade.ApplyFees(-2, 10, "fee-collector")
+	suite.Equal(lib.MustParseDecimal("-2"), trade.MakerFee)
+	suite.Equal(lib.MustParseDecimal("10"), trade.TakerFee)
+	suite.Equal("fee-collector", trade.FeeAccountID)
+}
+
+func (suite *TradeTestSuite) TestFeesAreRoundedUpFromTheExactNotional() {
+	trade := suite.newTrade("0.00000003", "0.1")
+	suite.Equal(lib.Decimal(0), trade.Notional())
+	trade.ApplyFees(-1, 1, "fee-collector")
+	suite.Equal(lib.MustParseDecimal("0.00000001"), trade.TakerFee)
+	suite.Equal(lib.Decimal(0), trade.MakerFee)
+
+	trade = suite.newTrade("100.00000001", "0.3")
+	trade.ApplyFees(-10, 10, "fee-collector")
+	suite.Equal(lib.MustParseDecimal("0.03000001"), trade.TakerFee)
+	suite.Equal(lib.MustParseDecimal("-0.03"), trade.MakerFee)
+}
+
+func (suite *TradeTestSuite) TestFeePostingsMoveTheFeesToTheFeeAccount() {
+	trade := suite.newTrade("20000", "0.5")
+	trade.ApplyFees(-2, 10, "fee-collector")
+	balances := map[string]lib.Decimal{}
+	var total lib.Decimal
+	for _, posting := range trade.FeePostings() {
+		suite.Equal(uint(7), posting.TradeID)
+		suite.Equal("BTC-USD", posting.Symbol)
+		suite.Equal(time.Unix(3, 0), posting.CreatedAt)
+		balances[posting.AccountID] += posting.Amount
+		total += posting.Amount
+	}
+	suite.Equal(map[string]lib.Decimal{
+		"maker":         lib.MustParseDecimal("2"),
+		"taker":         lib.MustParseDecimal("-10"),
+		"fee-collector": lib.MustParseDecimal("8"),
+	}, balances)
+	suite.Equal(lib.Decimal(0), total)
+
+	trade.ApplyFees(0, 0, "fee-collector")
+	suite.Empty(trade.FeePostings())
+}
@@ -0,0 +1,34 @@
+package order
+
+import (
+	"fmt"
+
+	"tradeTornado/internal/lib"
+)
+
+// TradingRules are the price and quantity increments of an instrument, every order price is a multiple of
+// TickSize and every quantity a multiple of LotSize. Zero MinOrderQuantity disables the minimum
+type TradingRules struct {
+	TickSize         lib.Decimal
+	LotSize          lib.Decimal
+	MinOrderQuantity lib.Decimal
+}
+
+// DefaultTradingRules accepts whole prices and quantities
+func DefaultTradingRules() TradingRules {
+	return TradingRules{TickSize: lib.NewDecimalFromInt(1), LotSize: lib.NewDecimalFromInt(1)}
+}
+
+// ValidateTradingRules checks the order against the increments of its instrument, the maximum order size is
+// enforced by the risk chain
+func (order *Order) ValidateTradingRules(rules TradingRules) error {
+	validation := lib.NewErrorNotification()
+	validation.DecimalShouldBeMultipleOf("price", order.Price, rules.TickSize)
+	validation.DecimalShouldBeMultipleOf("trigger_price", order.TriggerPrice, rules.TickSize)
+	validation.DecimalShouldBeMultipleOf("quantity", order.TotalQuantity(), rules.LotSize)
+	validation.DecimalShouldBeMultipleOf("display_quantity", order.DisplayQuantity, rules.LotSize)
+	if rules.MinOrderQuantity > 0 && order.TotalQuantity() < rules.MinOrderQuantity {
+		validation.Add("quantity", fmt.Errorf("should be at least %s", rules.MinOrderQuantity))
+	}
+	return validation.Err()
+}
@@ -0,0 +1,62 @@
+package order
+
+import (
+	"testing"
+	"time"
+	"tradeTornado/internal/lib"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TradingRulesTestSuite struct {
+	suite.Suite
+}
+
+func TestTradingRulesTestSuite(t *testing.T) {
+	suite.Run(t, new(TradingRulesTestSuite))
+}
+
+func (suite *TradingRulesTestSuite) TestNegativeValuesAreRejected() {
+	_, err := NewOrder(1, "account", "BTC-USD", "buy", "limit", dec(-100), dec(0), dec(10), time.Now())
+	suite.Error(err)
+	_, err = NewOrder(1, "account", "BTC-USD", "buy", "limit", dec(100), dec(0), dec(-10), time.Now())
+	suite.Error(err)
+	_, err = NewOrder(1, "account", "BTC-USD", "buy", "stop", dec(0), dec(-5), dec(10), time.Now())
+	suite.Error(err)
+}
+
+func (suite *TradingRulesTestSuite) TestIncrements() {
+	rules := TradingRules{TickSize: dec(5), LotSize: dec(10), MinOrderQuantity: dec(20)}
+
+	om, err := NewOrder(1, "account", "BTC-USD", "buy", "limit", dec(105), dec(0), dec(30), time.Now())
+	suite.Require().NoError(err)
+	suite.NoError(om.ValidateTradingRules(rules))
+
+	om, err = NewOrder(2, "account", "BTC-USD", "buy", "limit", dec(103), dec(0), dec(30), time.Now())
+	suite.Require().NoError(err)
+	suite.Error(om.ValidateTradingRules(rules))
+
+	om, err = NewOrder(3, "account", "BTC-USD", "buy", "limit", dec(105), dec(0), dec(25), time.Now())
+	suite.Require().NoError(err)
+	suite.Error(om.ValidateTradingRules(rules))
+
+	om, err = NewOrder(4, "account", "BTC-USD", "buy", "limit", dec(105), dec(0), dec(10), time.Now())
+	suite.Require().NoError(err)
+	suite.Error(om.ValidateTradingRules(rules))
+}
+
+func (suite *TradingRulesTestSuite) TestFractionalIncrements() {
+	rules := TradingRules{TickSize: lib.MustParseDecimal("0.0001"), LotSize: lib.MustParseDecimal("0.01")}
+
+	om, err := NewOrder(1, "account", "BTC-USD", "buy", "limit", lib.MustParseDecimal("1.2345"), dec(0), lib.MustParseDecimal("0.25"), time.Now())
+	suite.Require().NoError(err)
+	suite.NoError(om.ValidateTradingRules(rules))
+
+	om, err = NewOrder(2, "account", "BTC-USD", "buy", "limit", lib.MustParseDecimal("1.23456"), dec(0), lib.MustParseDecimal("0.25"), time.Now())
+	suite.Require().NoError(err)
+	suite.Error(om.ValidateTradingRules(rules))
+
+	om, err = NewOrder(3, "account", "BTC-USD", "buy", "limit", lib.MustParseDecimal("1.2345"), dec(0), lib.MustParseDecimal("0.255"), time.Now())
+	suite.Require().NoError(err)
+	suite.Error(om.ValidateTradingRules(rules))
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"tradeTornado/internal/service/provider"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+type LeadershipRole string
+
+const (
+	LeaderRole  LeadershipRole = "leader"
+	StandbyRole LeadershipRole = "standby"
+)
+
+type LeadershipStatus struct {
+	Role  LeadershipRole
+	Since time.Time
+}
+
+// LeaderElector campaigns for the lease every interval. A leader that can not renew steps down at once, so a standby
+// takes over at most one interval after the lease is released
+type LeaderElector struct {
+	lease    provider.ILeaderLease
+	interval time.Duration
+	gauge    prometheus.Gauge
+	lock     sync.RWMutex
+	status   LeadershipStatus
+	changed  chan struct{}
+}
+
+func NewLeaderElector(lease provider.ILeaderLease, interval time.Duration) *LeaderElector {
+	return &LeaderElector{
+		lease:    lease,
+		interval: interval,
+		gauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "matcher_leader",
+			Help: "1 while the instance is the active matcher, 0 while it is a standby",
+		}),
+		status:  LeadershipStatus{Role: StandbyRole, Since: time.Now()},
+		changed: make(chan struct{}),
+	}
+}
+
+func (le *LeaderElector) GetRepresentation() string {
+	return "LeaderElector"
+}
+
+func (le *LeaderElector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(le.interval)
+	defer ticker.Stop()
+	for {
+		le.campaign(ctx)
+		select {
+		case <-ctx.Done():
+			if le.Status().Role == LeaderRole {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), le.interval)
+				defer cancel()
+				if err := le.lease.Release(releaseCtx); err != nil {
+					logrus.WithError(err).Errorln("can not release the leader lease")
+				}
+			}
+			le.setRole(StandbyRole)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (le *LeaderElector) campaign(ctx context.Context) {
+	attemptCtx, cancel := context.WithTimeout(ctx, le.interval)
+	defer cancel()
+	if le.Status().Role == LeaderRole {
+		if err := le.lease.Renew(attemptCtx); err != nil {
+			logrus.WithError(err).Errorln("leader lease lost")
+			le.setRole(StandbyRole)
+		}
+		return
+	}
+	acquired, err := le.lease.TryAcquire(attemptCtx)
+	if err != nil {
+		logrus.WithError(err).Errorln("can not campaign for the leader lease")
+		return
+	}
+	if acquired {
+		le.setRole(LeaderRole)
+	}
+}
+
+func (le *LeaderElector) setRole(role LeadershipRole) {
+	le.lock.Lock()
+	defer le.lock.Unlock()
+	if le.status.Role == role {
+		return
+	}
+	le.status = LeadershipStatus{Role: role, Since: time.Now()}
+	close(le.changed)
+	le.changed = make(chan struct{})
+	if role == LeaderRole {
+		le.gauge.Set(1)
+	} else {
+		le.gauge.Set(0)
+	}
+	logrus.WithField("role", role).Warningln("leadership changed")
+}
+
+func (le *LeaderElector) Status() LeadershipStatus {
+	le.lock.RLock()
+	defer le.lock.RUnlock()
+	return le.status
+}
+
+func (le *LeaderElector) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{le.gauge}
+}
+
+// watch returns the current role and a channel closed on the next change
+func (le *LeaderElector) watch() (LeadershipRole, <-chan struct{}) {
+	le.lock.RLock()
+	defer le.lock.RUnlock()
+	return le.status.Role, le.changed
+}
+
+// WhileRole runs executor only while the instance has role, the executor is stopped through its context on every
+// change and started again when the role comes back
+func (le *LeaderElector) WhileRole(role LeadershipRole, executor IExecutor) IExecutor {
+	return &roleExecutor{elector: le, role: role, executor: executor}
+}
+
+type roleExecutor struct {
+	elector  *LeaderElector
+	role     LeadershipRole
+	executor IExecutor
+}
+
+func (re *roleExecutor) GetRepresentation() string {
+	return fmt.Sprintf("%s(%s)", re.executor.GetRepresentation(), re.role)
+}
+
+func (re *roleExecutor) Run(ctx context.Context) error {
+	for {
+		current, changed := re.elector.watch()
+		if current == re.role {
+			roleCtx, cancel := context.WithCancel(ctx)
+			go func() {
+				select {
+				case <-changed:
+					cancel()
+				case <-roleCtx.Done():
+				}
+			}()
+			err := re.executor.Run(roleCtx)
+			cancel()
+			if err != nil {
+				logrus.WithField("Executor", re.GetRepresentation()).Errorln(err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-time.After(time.Second):
+			// an executor that failed while the role stayed the same is started again
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeLease struct {
+	available atomic.Bool
+	held      atomic.Bool
+}
+
+func (fl *fakeLease) TryAcquire(context.Context) (bool, error) {
+	if fl.available.Load() {
+		fl.held.Store(true)
+	}
+	return fl.held.Load(), nil
+}
+
+func (fl *fakeLease) Renew(context.Context) error {
+	if !fl.available.Load() {
+		fl.held.Store(false)
+		return errors.New("lease lost")
+	}
+	return nil
+}
+
+func (fl *fakeLease) Release(context.Context) error {
+	fl.held.Store(false)
+	return nil
+}
+
+type countingExecutor struct {
+	running atomic.Int32
+}
+
+func (ce *countingExecutor) Run(ctx context.Context) error {
+	ce.running.Add(1)
+	defer ce.running.Add(-1)
+	<-ctx.Done()
+	return nil
+}
+
+func (ce *countingExecutor) GetRepresentation() string {
+	return "countingExecutor"
+}
+
+type LeaderElectionTestSuite struct {
+	suite.Suite
+}
+
+func TestLeaderElectionTestSuite(t *testing.T) {
+	suite.Run(t, new(LeaderElectionTestSuite))
+}
+
+func (suite *LeaderElectionTestSuite) TestRoleExecutorsFollowTheLease() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lease := &fakeLease{}
+	elector := NewLeaderElector(lease, 10*time.Millisecond)
+	leader, standby := &countingExecutor{}, &countingExecutor{}
+	go elector.Run(ctx)
+	go elector.WhileRole(LeaderRole, leader).Run(ctx)
+	go elector.WhileRole(StandbyRole, standby).Run(ctx)
+
+	suite.Eventually(func() bool { return standby.running.Load() == 1 && leader.running.Load() == 0 }, time.Second, 5*time.Millisecond)
+	suite.Equal(StandbyRole, elector.Status().Role)
+
+	lease.available.Store(true)
+	suite.Eventually(func() bool { return leader.running.Load() == 1 && standby.running.Load() == 0 }, time.Second, 5*time.Millisecond)
+	suite.Equal(LeaderRole, elector.Status().Role)
+
+	lease.available.Store(false)
+	suite.Eventually(func() bool { return standby.running.Load() == 1 && leader.running.Load() == 0 }, time.Second, 5*time.Millisecond)
+	suite.Equal(StandbyRole, elector.Status().Role)
+}
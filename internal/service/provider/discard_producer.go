@@ -0,0 +1,14 @@
+package provider
+
+import "context"
+
+// DiscardProducer drops every message, offline runs journal their events instead of publishing them
+type DiscardProducer struct{}
+
+func (DiscardProducer) Produce(ctx context.Context, topic, message string) error {
+	return nil
+}
+
+func (DiscardProducer) ProduceWithKey(ctx context.Context, topic, key, message string) error {
+	return nil
+}
@@ -21,18 +21,33 @@ type ServerConfigs struct {
 
 const ErrorsHeaderName = "errors"
 
+const (
+	openAPIPath    = "/openapi.json"
+	openAPITitle   = "tradeTornado API"
+	openAPIVersion = "1.0.0"
+)
+
 type GinServer struct {
-	cnf    ServerConfigs
-	Router *gin.Engine
+	cnf     ServerConfigs
+	Router  *gin.Engine
+	openAPI *lib.OpenAPI
 }
 
 func NewGinServer(cnf ServerConfigs) *GinServer {
 	ginServer := gin.Default()
-	server := &GinServer{cnf: cnf}
+	server := &GinServer{cnf: cnf, openAPI: lib.NewOpenAPI(openAPITitle, openAPIVersion)}
 	server.Router = ginServer
+	ginServer.GET(openAPIPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, server.openAPI)
+	})
 	return server
 }
 
+// OpenAPI documents the routes added so far
+func (s *GinServer) OpenAPI() *lib.OpenAPI {
+	return s.openAPI
+}
+
 func (s *GinServer) GetRepresentation() string {
 	return "GIN_API"
 }
@@ -61,16 +76,28 @@ func (s *GinServer) Run(ctx context.Context) error {
 	}
 }
 
-func (s *GinServer) AddRouter(router lib.IController) {
-	gr2 := s.Router.Group(router.GetRoot())
-	for _, handlerFunc := range router.GetMiddlewares() {
-		gr2.Use(handlerFunc)
-	}
-	for _, handler := range router.GetRouters() {
-		gr2.Handle(handler())
+func (s *GinServer) AddRouter(controller lib.IController) {
+	group := s.Router.Group(controller.GetRoot())
+	for _, route := range controller.GetRoutes() {
+		group.Handle(route.Method, route.Path, ginHandler(lib.ControllerHandler(controller, route)))
+		s.openAPI.AddRoute(controller.GetRoot(), route)
 	}
 }
 
 func (s *GinServer) AddMiddleWare(middleware gin.HandlerFunc) {
 	s.Router.Use(middleware)
 }
+
+// ginHandler adapts a handler to gin, an error of a stream that already started is kept on the gin context
+func ginHandler(handler lib.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+		if err := lib.WriteResponse(c.Writer, handler(lib.NewRequest(c.Request, params))); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+		}
+	}
+}
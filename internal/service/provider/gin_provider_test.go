@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tradeTornado/internal/lib"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type echoRequest struct {
+	ID   uint   `path:"id"`
+	Note string `json:"note"`
+}
+
+type echoController struct{}
+
+func (echoController) GetRoutes() []lib.Route {
+	return []lib.Route{
+		lib.Handle(http.MethodPut, ":id", func(_ context.Context, req echoRequest) (echoRequest, error) {
+			return req, nil
+		}),
+	}
+}
+
+func (echoController) GetRoot() string {
+	return "echo"
+}
+
+func (echoController) GetMiddlewares() []lib.Middleware {
+	return []lib.Middleware{func(next lib.HandlerFunc) lib.HandlerFunc {
+		return func(r *lib.Request) *lib.Response {
+			response := next(r)
+			response.Header = http.Header{"X-Served-By": {"echo"}}
+			return response
+		}
+	}}
+}
+
+type GinServerTestSuite struct {
+	suite.Suite
+	server *GinServer
+}
+
+func TestGinServerTestSuite(t *testing.T) {
+	suite.Run(t, new(GinServerTestSuite))
+}
+
+func (suite *GinServerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.server = NewGinServer(ServerConfigs{})
+	suite.server.AddRouter(echoController{})
+}
+
+func (suite *GinServerTestSuite) TestServesTheRoutesBehindTheirMiddlewares() {
+	recorder := httptest.NewRecorder()
+	suite.server.Router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/echo/5", strings.NewReader(`{"note":"n"}`)))
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("echo", recorder.Header().Get("X-Served-By"))
+	var body echoRequest
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &body))
+	suite.Equal(echoRequest{ID: 5, Note: "n"}, body)
+}
+
+func (suite *GinServerTestSuite) TestServesTheOpenAPIDocument() {
+	recorder := httptest.NewRecorder()
+	suite.server.Router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	suite.Equal(http.StatusOK, recorder.Code)
+	var document struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	suite.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &document))
+	suite.Contains(document.Paths["/echo/{id}"], "put")
+}
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
-	"time"
+	"tradeTornado/internal/lib"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/sirupsen/logrus"
@@ -15,14 +15,41 @@ type KafkaConsumerConfig struct {
 	Brokers    string
 	RetryTopic string
 	BatchSize  int
+	// OffsetReset is where a group without committed offsets starts, earliest when empty
+	OffsetReset string
+	// SkipCommit never commits the offsets of the group, it starts from OffsetReset every time
+	SkipCommit bool
 }
 
+// PartitionAssignment is a set of partitions of a topic, keys are mapped to partitions like the producers do
+type PartitionAssignment struct {
+	Topic          string
+	Partitions     []int32
+	PartitionCount int
+}
+
+// Owns reports whether messages keyed by key land on one of the partitions
+func (pa PartitionAssignment) Owns(key string) bool {
+	partition := lib.PartitionForKey(key, pa.PartitionCount)
+	for _, p := range pa.Partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
+
+// KafkaConsumerProvider only Consume connects, polls and closes the consumer, the lock keeps the health checks off a
+// consumer being replaced
 type KafkaConsumerProvider struct {
-	consumer *kafka.Consumer
-	producer *KafkaProducerProvider
-	cnf      KafkaConsumerConfig
-	GroupID  string
-	Topic    string
+	lock               sync.Mutex
+	consumer           *kafka.Consumer
+	producer           *KafkaProducerProvider
+	cnf                KafkaConsumerConfig
+	GroupID            string
+	Topic              string
+	partitionListeners []IPartitionListener
+	assigned           map[int32]bool
 }
 
 func NewKafkaConsumerProvider(cnf KafkaConsumerConfig, pr *KafkaProducerProvider, topic, consumerGroup string) (*KafkaConsumerProvider, error) {
@@ -34,13 +61,21 @@ func NewKafkaConsumerProvider(cnf KafkaConsumerConfig, pr *KafkaProducerProvider
 	return kafkaProvider, nil
 }
 
+func (receiver *KafkaConsumerProvider) AddPartitionListener(listener IPartitionListener) {
+	receiver.partitionListeners = append(receiver.partitionListeners, listener)
+}
+
 func (receiver *KafkaConsumerProvider) GetRepresentation() string {
 	return "KafkaConsumerProvider"
 }
 
+// Recover connects a consumer when there is none, it is only called by Consume so a subscribed consumer is never
+// replaced while it polls
 func (receiver *KafkaConsumerProvider) Recover(ctx context.Context) error {
-	if err := receiver.IsAlive(ctx); err != nil {
-		logrus.WithField("error", err.Error()).Errorln("trying to reconnect...")
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.consumer == nil {
+		logrus.WithField("topic", receiver.Topic).Infoln("connecting the consumer...")
 		kafkaProvider, err := NewKafkaConnection(receiver.cnf, receiver.Topic, receiver.GroupID)
 		if err != nil {
 			return err
@@ -50,37 +85,50 @@ func (receiver *KafkaConsumerProvider) Recover(ctx context.Context) error {
 	return nil
 }
 
+// Run waits for the shutdown, the consumer is connected and closed by Consume and librdkafka reconnects to the brokers
 func (receiver *KafkaConsumerProvider) Run(ctx context.Context) error {
-	if err := receiver.Recover(ctx); err != nil {
-		return err
-	}
-	for {
-		select {
-		case <-time.After(time.Minute):
-			if err := receiver.Recover(ctx); err != nil {
-				logrus.Errorln(err)
-			}
-		case <-ctx.Done():
-			logrus.Infoln("Shutting down Kafka consumer...")
-			return nil
-		}
-	}
+	<-ctx.Done()
+	logrus.Infoln("Shutting down Kafka consumer...")
+	return nil
 }
 
 func (receiver *KafkaConsumerProvider) IsAlive(ctx context.Context) error {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
 	if receiver.consumer == nil {
 		return errors.New("kafka consumer is not alive")
 	}
 	return nil
 }
 
+func (receiver *KafkaConsumerProvider) connected() *kafka.Consumer {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	return receiver.consumer
+}
+
+func (receiver *KafkaConsumerProvider) close() error {
+	receiver.lock.Lock()
+	defer receiver.lock.Unlock()
+	if receiver.consumer == nil {
+		return nil
+	}
+	err := receiver.consumer.Close()
+	receiver.consumer = nil
+	return err
+}
+
 func NewKafkaConnection(config KafkaConsumerConfig, topic, group string) (*KafkaConsumerProvider, error) {
-	provider := KafkaConsumerProvider{cnf: config, Topic: topic, GroupID: group}
+	provider := KafkaConsumerProvider{cnf: config, Topic: topic, GroupID: group, assigned: make(map[int32]bool)}
 
+	offsetReset := config.OffsetReset
+	if offsetReset == "" {
+		offsetReset = "earliest"
+	}
 	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
 		"bootstrap.servers":    config.Brokers,
 		"group.id":             group,
-		"auto.offset.reset":    "earliest",
+		"auto.offset.reset":    offsetReset,
 		"enable.auto.commit":   false,
 		"enable.partition.eof": false,
 	})
@@ -94,8 +142,15 @@ func NewKafkaConnection(config KafkaConsumerConfig, topic, group string) (*Kafka
 	return &provider, nil
 }
 
+// Consume processes the messages of a partition one after the other, messages keyed by the same value keep their
+// order while partitions are processed concurrently
+// Consume can be called again after its context is done, the closed consumer is replaced by a new connection
 func (receiver *KafkaConsumerProvider) Consume(ctx context.Context, process func(string) error) error {
-	if err := receiver.consumer.SubscribeTopics([]string{receiver.Topic}, nil); err != nil {
+	if err := receiver.Recover(ctx); err != nil {
+		return err
+	}
+	consumer := receiver.connected()
+	if err := consumer.SubscribeTopics([]string{receiver.Topic}, receiver.rebalance(ctx)); err != nil {
 		return err
 	}
 	var wg sync.WaitGroup
@@ -103,31 +158,86 @@ func (receiver *KafkaConsumerProvider) Consume(ctx context.Context, process func
 		select {
 		case <-ctx.Done():
 			wg.Wait()
-			return receiver.consumer.Close()
+			return receiver.close()
 		default:
-			batch := receiver.fetchBatch(ctx)
+			batch := receiver.fetchBatch(consumer)
 			if len(batch) == 0 {
 				continue
 			}
-			wg.Add(len(batch))
+			partitions := make(map[int32][]*kafka.Message)
 			for _, msg := range batch {
-				go func(msg *kafka.Message) {
+				partitions[msg.TopicPartition.Partition] = append(partitions[msg.TopicPartition.Partition], msg)
+			}
+			wg.Add(len(partitions))
+			for _, messages := range partitions {
+				go func(messages []*kafka.Message) {
 					defer wg.Done()
-					if err := process(string(msg.Value)); err != nil {
-						receiver.reproduce(ctx, msg)
+					for _, msg := range messages {
+						if err := process(string(msg.Value)); err != nil {
+							receiver.reproduce(ctx, msg)
+						}
 					}
-				}(msg)
+				}(messages)
 			}
 			wg.Wait()
-			receiver.commitOffsets(batch)
+			if !receiver.cnf.SkipCommit {
+				receiver.commitOffsets(consumer, batch)
+			}
+		}
+	}
+}
+
+// rebalance runs inside Poll, so no message of the batch being fetched is processed while the assignment changes
+func (receiver *KafkaConsumerProvider) rebalance(ctx context.Context) kafka.RebalanceCb {
+	return func(consumer *kafka.Consumer, ev kafka.Event) error {
+		switch e := ev.(type) {
+		case kafka.AssignedPartitions:
+			assignment, err := receiver.assignment(consumer, e.Partitions)
+			if err != nil {
+				return err
+			}
+			for _, partition := range assignment.Partitions {
+				receiver.assigned[partition] = true
+			}
+			logrus.WithField("topic", receiver.Topic).WithField("partitions", assignment.Partitions).Infoln("partitions assigned")
+			for _, listener := range receiver.partitionListeners {
+				listener.PartitionsAssigned(ctx, assignment)
+			}
+			return consumer.Assign(e.Partitions)
+		case kafka.RevokedPartitions:
+			assignment, err := receiver.assignment(consumer, e.Partitions)
+			if err != nil {
+				return err
+			}
+			for _, partition := range assignment.Partitions {
+				delete(receiver.assigned, partition)
+			}
+			logrus.WithField("topic", receiver.Topic).WithField("partitions", assignment.Partitions).Infoln("partitions revoked")
+			for _, listener := range receiver.partitionListeners {
+				listener.PartitionsRevoked(ctx, assignment)
+			}
+			return consumer.Unassign()
 		}
+		return nil
+	}
+}
+
+func (receiver *KafkaConsumerProvider) assignment(consumer *kafka.Consumer, topicPartitions []kafka.TopicPartition) (PartitionAssignment, error) {
+	metadata, err := consumer.GetMetadata(&receiver.Topic, false, 5000)
+	if err != nil {
+		return PartitionAssignment{}, err
 	}
+	assignment := PartitionAssignment{Topic: receiver.Topic, PartitionCount: len(metadata.Topics[receiver.Topic].Partitions)}
+	for _, tp := range topicPartitions {
+		assignment.Partitions = append(assignment.Partitions, tp.Partition)
+	}
+	return assignment, nil
 }
 
-func (receiver *KafkaConsumerProvider) fetchBatch(ctx context.Context) []*kafka.Message {
+func (receiver *KafkaConsumerProvider) fetchBatch(consumer *kafka.Consumer) []*kafka.Message {
 	var batch []*kafka.Message
 	for len(batch) < receiver.cnf.BatchSize {
-		ev := receiver.consumer.Poll(100)
+		ev := consumer.Poll(100)
 		if ev == nil {
 			break
 		}
@@ -143,10 +253,17 @@ func (receiver *KafkaConsumerProvider) fetchBatch(ctx context.Context) []*kafka.
 			logrus.Infof("Ignored %v\n", e)
 		}
 	}
-	return batch
+	// a rebalance during the poll may have revoked partitions of the batch, their new owner reads them again
+	owned := batch[:0]
+	for _, msg := range batch {
+		if receiver.assigned[msg.TopicPartition.Partition] {
+			owned = append(owned, msg)
+		}
+	}
+	return owned
 }
 
-func (receiver *KafkaConsumerProvider) commitOffsets(batch []*kafka.Message) {
+func (receiver *KafkaConsumerProvider) commitOffsets(consumer *kafka.Consumer, batch []*kafka.Message) {
 	// Kafka prefers batch commit
 	offsets := make([]kafka.TopicPartition, len(batch))
 	for i, msg := range batch {
@@ -156,7 +273,7 @@ func (receiver *KafkaConsumerProvider) commitOffsets(batch []*kafka.Message) {
 			Offset:    msg.TopicPartition.Offset + 1,
 		}
 	}
-	if _, err := receiver.consumer.CommitOffsets(offsets); err != nil {
+	if _, err := consumer.CommitOffsets(offsets); err != nil {
 		logrus.WithError(err).Error("Failed to commit offsets")
 	}
 }
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KafkaConsumerTestSuite struct {
+	suite.Suite
+}
+
+func TestKafkaConsumerTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaConsumerTestSuite))
+}
+
+// no broker listens on the port, the consumer polls errors until it is closed
+func (suite *KafkaConsumerTestSuite) TestOnlyConsumeReplacesTheConsumer() {
+	receiver, err := NewKafkaConsumerProvider(KafkaConsumerConfig{Brokers: "127.0.0.1:1", BatchSize: 1}, nil, "orders", "group")
+	suite.Require().NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		suite.NoError(receiver.Run(ctx))
+	}()
+	go func() {
+		defer wg.Done()
+		suite.NoError(receiver.Consume(ctx, func(string) error { return nil }))
+	}()
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			_ = receiver.IsAlive(ctx)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	suite.NoError(receiver.IsAlive(ctx))
+	cancel()
+	wg.Wait()
+
+	suite.Error(receiver.IsAlive(context.Background()))
+}
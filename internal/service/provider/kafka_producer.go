@@ -76,7 +76,8 @@ func (receiver *KafkaProducerProvider) IsAlive(ctx context.Context) error {
 func NewKafkaProducer(config KafkaProducerConfig) (*KafkaProducerProvider, error) {
 	provider := KafkaProducerProvider{cnf: config}
 
-	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": config.Brokers})
+	// murmur2 is the partitioner of the Java client, consumers map keys to partitions with lib.PartitionForKey
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": config.Brokers, "partitioner": "murmur2_random"})
 	if err != nil {
 		return &provider, err
 	}
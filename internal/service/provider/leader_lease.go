@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// PostgresAdvisoryLease holds a session advisory lock on a dedicated connection, Postgres releases the lock as soon as
+// the session ends so a crashed leader frees the lease without waiting for an expiry
+type PostgresAdvisoryLease struct {
+	db   *gorm.DB
+	key  int64
+	conn *sql.Conn
+}
+
+func NewPostgresAdvisoryLease(db *gorm.DB, key int64) *PostgresAdvisoryLease {
+	return &PostgresAdvisoryLease{db: db, key: key}
+}
+
+func (l *PostgresAdvisoryLease) TryAcquire(ctx context.Context) (bool, error) {
+	if l.conn == nil {
+		sqlDB, err := l.db.DB()
+		if err != nil {
+			return false, err
+		}
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return false, err
+		}
+		l.conn = conn
+	}
+	var acquired bool
+	if err := l.conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		l.close()
+		return false, err
+	}
+	return acquired, nil
+}
+
+// Renew checks the session holding the lock is still alive
+func (l *PostgresAdvisoryLease) Renew(ctx context.Context) error {
+	if l.conn == nil {
+		return errors.New("advisory lock is not held")
+	}
+	if _, err := l.conn.ExecContext(ctx, "SELECT 1"); err != nil {
+		l.close()
+		return err
+	}
+	return nil
+}
+
+func (l *PostgresAdvisoryLease) Release(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+	defer l.close()
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	return err
+}
+
+func (l *PostgresAdvisoryLease) close() {
+	// the connection is discarded instead of going back to the pool, ending the session releases the lock
+	_ = l.conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = l.conn.Close()
+	l.conn = nil
+}
+
+// AlwaysHeldLease makes every instance a leader, it is used when matchers are sharded instead of replicated
+type AlwaysHeldLease struct{}
+
+func (AlwaysHeldLease) TryAcquire(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (AlwaysHeldLease) Renew(context.Context) error {
+	return nil
+}
+
+func (AlwaysHeldLease) Release(context.Context) error {
+	return nil
+}
@@ -11,6 +11,21 @@ type IProducer interface {
 	ProduceWithKey(ctx context.Context, topic, key, message string) error
 }
 
+// IPartitionListener follows the partitions a consumer owns, it is called from the rebalance before messages of
+// an assignment are read and after the messages of revoked partitions stopped being processed
+type IPartitionListener interface {
+	PartitionsAssigned(ctx context.Context, assignment PartitionAssignment)
+	PartitionsRevoked(ctx context.Context, assignment PartitionAssignment)
+}
+
+// ILeaderLease is held by at most one instance at a time
+type ILeaderLease interface {
+	TryAcquire(ctx context.Context) (bool, error)
+	// Renew fails once the lease is no longer held
+	Renew(ctx context.Context) error
+	Release(ctx context.Context) error
+}
+
 type IEventBus interface {
 	IConsumer
 	IProducer
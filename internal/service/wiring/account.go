@@ -0,0 +1,36 @@
+package wiring
+
+import (
+	"tradeTornado/internal/modules/account/application"
+	"tradeTornado/internal/modules/account/infrastructure"
+	"tradeTornado/internal/service/provider"
+)
+
+func (c *ContainerBuilder) NewAccountController(routesOnly bool) *infrastructure.AccountController {
+	return infrastructure.NewAccountController(dependency(routesOnly, c.NewAccountCommandHandler), dependency(routesOnly, c.NewAccountQueryHandler))
+}
+
+func (c *ContainerBuilder) NewAccountCommandHandler() *application.AccountCommandHandler {
+	return application.NewAccountCommandHandler(c.NewAccountWriteRepository())
+}
+
+func (c *ContainerBuilder) NewAccountQueryHandler() *application.AccountQueryHandler {
+	return application.NewAccountQueryHandler(c.NewAccountReadRepository())
+}
+
+// NewSelfTradePolicy reads from master since the matcher applies preferences right after they change
+func (c *ContainerBuilder) NewSelfTradePolicy() *application.AccountQueryHandler {
+	return application.NewAccountQueryHandler(c.NewAccountWriteRepository())
+}
+
+func (c *ContainerBuilder) NewAccountWriteRepository() *infrastructure.AccountRepository {
+	return infrastructure.NewAccountRepository(c.NewMasterGormSession())
+}
+
+func (c *ContainerBuilder) NewAccountReadRepository() *infrastructure.AccountRepository {
+	return infrastructure.NewAccountRepository(c.NewSlaveGormSession())
+}
+
+func (c *ContainerBuilder) NewAccountWriteRepositoryTx(session *provider.GormSession) *infrastructure.AccountRepository {
+	return infrastructure.NewAccountRepository(session)
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"tradeTornado/internal/lib"
+	marketdata "tradeTornado/internal/modules/marketdata/application"
+	"tradeTornado/internal/modules/order/application"
 	"tradeTornado/internal/service"
 	"tradeTornado/internal/service/provider"
 )
@@ -21,9 +24,18 @@ type ContainerBuilder struct {
 	prometheusService                *provider.PrometheusMetricsServer
 	kafkaCreateOrderConsumerProvider *provider.KafkaConsumerProvider
 	kafkaProducerProvider            *provider.KafkaProducerProvider
+	bookRegistry                     *application.BookRegistry
+	leaderElector                    *service.LeaderElector
+	kafkaMarketDataConsumerProvider  *provider.KafkaConsumerProvider
+	kafkaTickerConsumerProvider      *provider.KafkaConsumerProvider
+	tickerRegistry                   *marketdata.TickerRegistry
 }
 
 func NewContainer(cnf configs.Configs) *ContainerBuilder {
+	// prices and quantities are scaled once, before any of them is decoded
+	if err := lib.SetDecimalScale(cnf.DecimalScale); err != nil {
+		logrus.Fatalln(err)
+	}
 	tmp := &ContainerBuilder{cnf: cnf}
 	return tmp
 }
@@ -58,13 +70,27 @@ func (c *ContainerBuilder) initThreadPool() {
 	pool.AddExecutor(c.GetSlaveDB())
 	pool.AddExecutor(c.GetApiServer())
 	pool.AddExecutor(c.GetKafkaCreateOrderConsumerProvider())
-	pool.AddExecutor(c.NewOrderEventHandler())
+	pool.AddExecutor(c.GetLeaderElector())
+	pool.AddExecutor(c.GetLeaderElector().WhileRole(service.LeaderRole, c.NewOrderEventHandler()))
+	pool.AddExecutor(c.GetLeaderElector().WhileRole(service.LeaderRole, c.NewTradingSessionScheduler()))
+	if c.cnf.MatcherMode == configs.ActiveStandbyMatcherMode {
+		pool.AddExecutor(c.GetLeaderElector().WhileRole(service.StandbyRole, c.NewJournalFollower()))
+	}
+	pool.AddExecutor(c.GetKafkaMarketDataConsumerProvider())
+	pool.AddExecutor(c.NewMarketDataEventHandler())
+	pool.AddExecutor(c.GetKafkaTickerConsumerProvider())
+	pool.AddExecutor(c.NewTickerEventHandler())
+	pool.AddExecutor(c.GetLeaderElector().WhileRole(service.LeaderRole, c.NewTickerPublisher()))
 	pool.AddExecutor(c.GetMetricsService())
 }
 
 func (c *ContainerBuilder) initMigrationRegistry() {
 	session := c.NewMasterGormSession()
 	c.getMigrationRegistry().RegisterMigration("orders", c.NewOrderWriteRepositoryTx(session))
+	c.getMigrationRegistry().RegisterMigration("fees", c.NewFeeWriteRepositoryTx(session))
+	c.getMigrationRegistry().RegisterMigration("instruments", c.NewInstrumentWriteRepositoryTx(session))
+	c.getMigrationRegistry().RegisterMigration("accounts", c.NewAccountWriteRepositoryTx(session))
+	c.getMigrationRegistry().RegisterMigration("marketdata", c.NewCandleWriteRepositoryTx(session))
 }
 
 func (c *ContainerBuilder) getMigrationRegistry() *service.MigrationRegistry {
@@ -116,6 +142,41 @@ func (c *ContainerBuilder) GetApiServer() *provider.GinServer {
 	return c.apiServer
 }
 
+// OpenAPI documents the routes of the api server on a server of its own, the controllers are built without their
+// dependencies so neither the databases nor kafka are reached
+func (c *ContainerBuilder) OpenAPI() *lib.OpenAPI {
+	server := c.NewGinServer(c.cnf.ServerConfigs)
+	for _, controller := range c.apiControllers(true) {
+		server.AddRouter(controller)
+	}
+	return server.OpenAPI()
+}
+
 func (c *ContainerBuilder) initApiServer() {
-	c.GetApiServer().AddRouter(c.NewOrdereController())
+	for _, controller := range c.apiControllers(false) {
+		c.GetApiServer().AddRouter(controller)
+	}
+}
+
+// apiControllers are the controllers the api server serves, routesOnly builds them for their routes alone
+func (c *ContainerBuilder) apiControllers(routesOnly bool) []lib.IController {
+	return []lib.IController{
+		c.NewOrdereController(routesOnly),
+		c.NewTradeController(routesOnly),
+		c.NewFeeController(routesOnly),
+		c.NewInstrumentController(routesOnly),
+		c.NewAccountController(routesOnly),
+		c.NewStatusController(routesOnly),
+		c.NewMarketDataController(routesOnly),
+	}
+}
+
+// dependency builds a dependency of a controller, a controller built for its routes alone gets none since a route
+// only uses the handlers of its controller while serving a request
+func dependency[T any](routesOnly bool, build func() T) T {
+	if routesOnly {
+		var none T
+		return none
+	}
+	return build()
 }
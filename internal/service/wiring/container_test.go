@@ -0,0 +1,38 @@
+package wiring
+
+import (
+	"encoding/json"
+	"testing"
+
+	configs "tradeTornado/config"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ContainerTestSuite struct {
+	suite.Suite
+}
+
+func TestContainerTestSuite(t *testing.T) {
+	suite.Run(t, new(ContainerTestSuite))
+}
+
+func (suite *ContainerTestSuite) TestOpenAPIIsWrittenWithoutDatabasesOrKafka() {
+	cnf := configs.ConfigFromEnv()
+	cnf.MasterDatabase.Host, cnf.MasterDatabase.Port = "127.0.0.1", "1"
+	cnf.SlaveDatabase.Host, cnf.SlaveDatabase.Port = "127.0.0.1", "1"
+	c := NewContainer(cnf)
+
+	raw, err := json.Marshal(c.OpenAPI())
+	suite.Require().NoError(err)
+	var document struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	suite.Require().NoError(json.Unmarshal(raw, &document))
+	suite.Contains(document.Paths["/orders"], "get")
+	suite.Contains(document.Paths["/trades"], "get")
+	suite.Contains(document.Paths["/status"], "get")
+	suite.Nil(c.masterDb)
+	suite.Nil(c.slaveDb)
+	suite.Nil(c.kafkaProducerProvider)
+}
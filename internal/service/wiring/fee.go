@@ -0,0 +1,36 @@
+package wiring
+
+import (
+	"tradeTornado/internal/modules/fee/application"
+	"tradeTornado/internal/modules/fee/infrastructure"
+	"tradeTornado/internal/service/provider"
+)
+
+func (c *ContainerBuilder) NewFeeController(routesOnly bool) *infrastructure.FeeController {
+	return infrastructure.NewFeeController(dependency(routesOnly, c.NewFeeCommandHandler), dependency(routesOnly, c.NewFeeQueryHandler))
+}
+
+func (c *ContainerBuilder) NewFeeCommandHandler() *application.FeeCommandHandler {
+	return application.NewFeeCommandHandler(c.NewFeeWriteRepository())
+}
+
+func (c *ContainerBuilder) NewFeeQueryHandler() *application.FeeQueryHandler {
+	return application.NewFeeQueryHandler(c.NewFeeReadRepository())
+}
+
+// NewFeeSchedule reads from master since fees are charged while matching
+func (c *ContainerBuilder) NewFeeSchedule() *application.FeeQueryHandler {
+	return application.NewFeeQueryHandler(c.NewFeeWriteRepository())
+}
+
+func (c *ContainerBuilder) NewFeeWriteRepository() *infrastructure.FeeRepository {
+	return infrastructure.NewFeeRepository(c.NewMasterGormSession())
+}
+
+func (c *ContainerBuilder) NewFeeReadRepository() *infrastructure.FeeRepository {
+	return infrastructure.NewFeeRepository(c.NewSlaveGormSession())
+}
+
+func (c *ContainerBuilder) NewFeeWriteRepositoryTx(session *provider.GormSession) *infrastructure.FeeRepository {
+	return infrastructure.NewFeeRepository(session)
+}
@@ -0,0 +1,69 @@
+package wiring
+
+import (
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/instrument"
+	"tradeTornado/internal/modules/instrument/application"
+	"tradeTornado/internal/modules/instrument/infrastructure"
+	"tradeTornado/internal/modules/order"
+	"tradeTornado/internal/service/provider"
+)
+
+func (c *ContainerBuilder) NewInstrumentController(routesOnly bool) *infrastructure.InstrumentController {
+	return infrastructure.NewInstrumentController(dependency(routesOnly, c.NewInstrumentCommandHandler), dependency(routesOnly, c.NewInstrumentQueryHandler))
+}
+
+func (c *ContainerBuilder) NewInstrumentCommandHandler() *application.InstrumentCommandHandler {
+	return application.NewInstrumentCommandHandler(c.NewInstrumentWriteRepository(), c.newPhaseTransition, c.NewOrderMatcher(), lib.SystemClock{})
+}
+
+// newPhaseTransition shares one master session between the instrument repository and the matcher so the phase is
+// stored in the transaction of the uncross
+func (c *ContainerBuilder) newPhaseTransition() instrument.PhaseTransition {
+	session := c.NewMasterGormSession()
+	return instrument.PhaseTransition{
+		Instruments: c.NewInstrumentWriteRepositoryTx(session),
+		AuctionUncrosser: c.newOrderMatcher(c.GetKafkaProducerProvider(),
+			func() order.OrderTransaction {
+				return order.OrderTransaction{Orders: c.NewOrderWriteRepositoryTx(session), CircuitBreaker: c.NewCircuitBreakerTx(session)}
+			},
+			c.NewInstrumentRules(),
+			c.GetBookRegistry(),
+			lib.SystemClock{}),
+	}
+}
+
+func (c *ContainerBuilder) NewCircuitBreakerTx(session *provider.GormSession) *application.CircuitBreaker {
+	return application.NewCircuitBreaker(c.NewInstrumentWriteRepositoryTx(session), lib.SystemClock{})
+}
+
+// NewTradingSessionScheduler resumes the symbols whose orders this instance matches
+func (c *ContainerBuilder) NewTradingSessionScheduler() *application.TradingSessionScheduler {
+	return application.NewTradingSessionScheduler(c.NewInstrumentWriteRepository(),
+		c.GetBookRegistry(),
+		c.NewInstrumentCommandHandler(),
+		time.Duration(c.cnf.SessionSchedulerIntervalMS)*time.Millisecond,
+		lib.SystemClock{})
+}
+
+func (c *ContainerBuilder) NewInstrumentQueryHandler() *application.InstrumentQueryHandler {
+	return application.NewInstrumentQueryHandler(c.NewInstrumentReadRepository())
+}
+
+// NewInstrumentRules reads from master since the matcher enforces the rules right after they change
+func (c *ContainerBuilder) NewInstrumentRules() *application.InstrumentQueryHandler {
+	return application.NewInstrumentQueryHandler(c.NewInstrumentWriteRepository())
+}
+
+func (c *ContainerBuilder) NewInstrumentWriteRepository() *infrastructure.InstrumentRepository {
+	return infrastructure.NewInstrumentRepository(c.NewMasterGormSession())
+}
+
+func (c *ContainerBuilder) NewInstrumentReadRepository() *infrastructure.InstrumentRepository {
+	return infrastructure.NewInstrumentRepository(c.NewSlaveGormSession())
+}
+
+func (c *ContainerBuilder) NewInstrumentWriteRepositoryTx(session *provider.GormSession) *infrastructure.InstrumentRepository {
+	return infrastructure.NewInstrumentRepository(session)
+}
@@ -0,0 +1,45 @@
+package wiring
+
+import (
+	"log"
+	"time"
+
+	configs "tradeTornado/config"
+	"tradeTornado/internal/modules/order/application"
+	"tradeTornado/internal/modules/order/infrastructure"
+	"tradeTornado/internal/service"
+	"tradeTornado/internal/service/provider"
+)
+
+// GetLeaderElector sharded matchers are all leaders of their own partitions, active/standby matchers compete for
+// a Postgres advisory lock
+func (c *ContainerBuilder) GetLeaderElector() *service.LeaderElector {
+	if c.leaderElector == nil {
+		var lease provider.ILeaderLease
+		switch c.cnf.MatcherMode {
+		case configs.ShardedMatcherMode:
+			lease = provider.AlwaysHeldLease{}
+		case configs.ActiveStandbyMatcherMode:
+			lease = provider.NewPostgresAdvisoryLease(c.GetMasterDB().DB, c.cnf.LeaderLockKey)
+		default:
+			log.Fatalf("unknown matcher mode %s", c.cnf.MatcherMode)
+		}
+		c.leaderElector = service.NewLeaderElector(lease, time.Duration(c.cnf.LeaderElectionIntervalMS)*time.Millisecond)
+	}
+	return c.leaderElector
+}
+
+// NewJournalFollower tails the journal from the master, the books it reloads are read from the master too
+func (c *ContainerBuilder) NewJournalFollower() *application.JournalFollower {
+	return application.NewJournalFollower(c.NewOrderWriteRepository(),
+		c.GetBookRegistry(),
+		time.Duration(c.cnf.JournalFollowIntervalMS)*time.Millisecond)
+}
+
+func (c *ContainerBuilder) NewStatusController(routesOnly bool) *infrastructure.StatusController {
+	return infrastructure.NewStatusController(dependency(routesOnly, c.NewMatcherStatusQueryHandler))
+}
+
+func (c *ContainerBuilder) NewMatcherStatusQueryHandler() *application.MatcherStatusQueryHandler {
+	return application.NewMatcherStatusQueryHandler(c.GetLeaderElector(), c.GetBookRegistry())
+}
@@ -0,0 +1,97 @@
+package wiring
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+	"tradeTornado/internal/lib"
+	"tradeTornado/internal/modules/marketdata/application"
+	"tradeTornado/internal/modules/marketdata/infrastructure"
+	"tradeTornado/internal/service/provider"
+)
+
+func (c *ContainerBuilder) NewMarketDataController(routesOnly bool) *infrastructure.MarketDataController {
+	return infrastructure.NewMarketDataController(dependency(routesOnly, c.NewCandleQueryHandler), dependency(routesOnly, c.GetTickerRegistry))
+}
+
+func (c *ContainerBuilder) NewCandleQueryHandler() *application.CandleQueryHandler {
+	return application.NewCandleQueryHandler(c.NewCandleReadRepository(), lib.SystemClock{})
+}
+
+// NewCandleCommandHandler backfills from the trades of the slave
+func (c *ContainerBuilder) NewCandleCommandHandler() *application.CandleCommandHandler {
+	return application.NewCandleCommandHandler(c.NewCandleWriteRepository(), c.NewOrderReadRepository())
+}
+
+func (c *ContainerBuilder) NewMarketDataEventHandler() *application.MarketDataEventHandler {
+	return application.NewMarketDataEventHandler(c.GetKafkaMarketDataConsumerProvider(), c.NewCandleCommandHandler())
+}
+
+func (c *ContainerBuilder) NewCandleWriteRepository() *infrastructure.CandleRepository {
+	return infrastructure.NewCandleRepository(c.NewMasterGormSession())
+}
+
+func (c *ContainerBuilder) NewCandleReadRepository() *infrastructure.CandleRepository {
+	return infrastructure.NewCandleRepository(c.NewSlaveGormSession())
+}
+
+func (c *ContainerBuilder) NewCandleWriteRepositoryTx(session *provider.GormSession) *infrastructure.CandleRepository {
+	return infrastructure.NewCandleRepository(session)
+}
+
+// GetKafkaMarketDataConsumerProvider the instances share one group so every execution is aggregated once
+func (c *ContainerBuilder) GetKafkaMarketDataConsumerProvider() *provider.KafkaConsumerProvider {
+	if c.kafkaMarketDataConsumerProvider == nil {
+		pv, err := provider.NewKafkaConsumerProvider(c.cnf.KafkaConsumerConfig, c.GetKafkaProducerProvider(), c.cnf.OrderMatchedTopic, c.cnf.MarketDataConsumerGroup)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		c.kafkaMarketDataConsumerProvider = pv
+	}
+	return c.kafkaMarketDataConsumerProvider
+}
+
+// GetTickerRegistry is shared by the controller, the ticker consumer and the publisher, books are read from the slave
+// and the warm up from the master since the executions read right after it are already committed there
+func (c *ContainerBuilder) GetTickerRegistry() *application.TickerRegistry {
+	if c.tickerRegistry == nil {
+		c.tickerRegistry = application.NewTickerRegistry(c.NewOrderWriteRepository(), c.NewOrderReadRepository(), lib.SystemClock{})
+	}
+	return c.tickerRegistry
+}
+
+func (c *ContainerBuilder) NewTickerEventHandler() *application.TickerEventHandler {
+	return application.NewTickerEventHandler(c.GetKafkaTickerConsumerProvider(), c.GetTickerRegistry())
+}
+
+// NewTickerPublisher publishes the tickers of the symbols whose orders this instance matches
+func (c *ContainerBuilder) NewTickerPublisher() *application.TickerPublisher {
+	return application.NewTickerPublisher(c.GetTickerRegistry(),
+		c.GetBookRegistry(),
+		c.GetKafkaProducerProvider(),
+		c.cnf.TickerTopic,
+		time.Duration(c.cnf.TickerPublishIntervalMS)*time.Millisecond)
+}
+
+// GetKafkaTickerConsumerProvider every instance reads the whole match topic from its own group, starting at the
+// latest execution every time since the tickers warm up from the trades when the partitions are assigned
+func (c *ContainerBuilder) GetKafkaTickerConsumerProvider() *provider.KafkaConsumerProvider {
+	if c.kafkaTickerConsumerProvider == nil {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		cnf := c.cnf.KafkaConsumerConfig
+		cnf.OffsetReset = "latest"
+		cnf.SkipCommit = true
+		group := fmt.Sprintf("%s-%s", c.cnf.TickerConsumerGroup, hostname)
+		pv, err := provider.NewKafkaConsumerProvider(cnf, c.GetKafkaProducerProvider(), c.cnf.OrderMatchedTopic, group)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		pv.AddPartitionListener(c.GetTickerRegistry())
+		c.kafkaTickerConsumerProvider = pv
+	}
+	return c.kafkaTickerConsumerProvider
+}
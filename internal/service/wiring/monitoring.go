@@ -12,4 +12,5 @@ func (c *ContainerBuilder) GetMetricsService() *provider.PrometheusMetricsServer
 }
 
 func (c *ContainerBuilder) initMetrics() {
+	c.GetMetricsService().AddMetricCollectorGroup(c.GetLeaderElector().Collectors()...)
 }
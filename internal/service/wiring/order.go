@@ -2,18 +2,32 @@ package wiring
 
 import (
 	"log"
+	"time"
+	"tradeTornado/internal/lib"
 	"tradeTornado/internal/modules/order"
 	"tradeTornado/internal/modules/order/application"
 	"tradeTornado/internal/modules/order/infrastructure"
 	"tradeTornado/internal/service/provider"
 )
 
-func (c *ContainerBuilder) NewOrdereController() *infrastructure.OrderController {
-	return infrastructure.NewOrderController(c.NewOrdereQueryHandler())
+func (c *ContainerBuilder) NewOrdereController(routesOnly bool) *infrastructure.OrderController {
+	return infrastructure.NewOrderController(dependency(routesOnly, c.NewOrdereQueryHandler))
 }
 
 func (c *ContainerBuilder) NewOrdereQueryHandler() *application.OrderQueryHandler {
-	return application.NewOrderQueryHandler(c.NewOrderReadRepository())
+	return application.NewOrderQueryHandler(c.NewOrderReadRepository(), c.NewInstrumentRules())
+}
+
+func (c *ContainerBuilder) NewTradeController(routesOnly bool) *infrastructure.TradeController {
+	return infrastructure.NewTradeController(dependency(routesOnly, c.NewTradeQueryHandler))
+}
+
+func (c *ContainerBuilder) NewTradeQueryHandler() *application.TradeQueryHandler {
+	return application.NewTradeQueryHandler(c.NewTradeReadRepository())
+}
+
+func (c *ContainerBuilder) NewTradeReadRepository() *infrastructure.TradeRepository {
+	return infrastructure.NewTradeRepository(c.NewSlaveGormSession())
 }
 
 func (c *ContainerBuilder) NewOrderWriteRepository() *infrastructure.OrderRepository {
@@ -29,12 +43,103 @@ func (c *ContainerBuilder) NewOrderWriteRepositoryTx(session *provider.GormSessi
 }
 
 func (c *ContainerBuilder) NewOrderEventHandler() *application.OrderEventHandler {
-	return application.NewOrderEventHandler(c.GetKafkaCreateOrderConsumerProvider(),
-		c.GetKafkaProducerProvider(),
-		c.cnf.OrderMatchedTopic,
-		func() order.IOrderWriteRepository {
-			return c.NewOrderWriteRepository()
-		})
+	return application.NewOrderEventHandler(c.GetKafkaCreateOrderConsumerProvider(), c.NewOrderMatcher(), c.GetBookRegistry())
+}
+
+// NewOrderMatcher trips the circuit breaker on the master session of the command so the phase is stored with its trades
+func (c *ContainerBuilder) NewOrderMatcher() *application.OrderMatcher {
+	return c.newOrderMatcher(c.GetKafkaProducerProvider(),
+		func() order.OrderTransaction {
+			session := c.NewMasterGormSession()
+			return order.OrderTransaction{Orders: c.NewOrderWriteRepositoryTx(session), CircuitBreaker: c.NewCircuitBreakerTx(session)}
+		},
+		c.NewInstrumentRules(),
+		c.GetBookRegistry(),
+		lib.SystemClock{})
+}
+
+// NewJournalReplayer reads the journal from the master, commands journaled without their reference data read the
+// current instruments, fees and accounts
+func (c *ContainerBuilder) NewJournalReplayer() *application.JournalReplayer {
+	repository := infrastructure.NewMemoryOrderRepository()
+	sessions := application.NewJournaledSessions(order.ContinuousTradingPhase)
+	reference := application.NewJournaledReference(c.NewFeeSchedule(), c.NewInstrumentRules(), c.NewInstrumentRules(), c.NewSelfTradePolicy(), c.NewInstrumentRules(), c.NewInstrumentRules())
+	clock := lib.NewSimulatedClock(time.Time{})
+	matcher := application.NewOrderMatcher(provider.DiscardProducer{},
+		c.orderEventTopics(),
+		func() order.OrderTransaction {
+			return order.OrderTransaction{Orders: repository, CircuitBreaker: sessions}
+		},
+		reference,
+		c.cnf.FeeCollectionAccount,
+		reference,
+		reference,
+		order.DefaultRiskChain(),
+		reference,
+		sessions,
+		reference,
+		reference,
+		application.DetachedBooks{},
+		clock)
+	return application.NewJournalReplayer(c.NewOrderWriteRepository(), repository, matcher, sessions, reference, clock)
+}
+
+// NewBacktester never connects to Kafka or Postgres, every symbol uses the reference data of settings
+func (c *ContainerBuilder) NewBacktester(settings application.BacktestSettings, output application.IBacktestOutput) *application.Backtester {
+	repository := infrastructure.NewMemoryOrderRepository()
+	sessions := application.NewJournaledSessions(order.ContinuousTradingPhase)
+	clock := lib.NewSimulatedClock(settings.Start)
+	reference := application.NewBacktestReferenceData(settings)
+	matcher := application.NewOrderMatcher(provider.DiscardProducer{},
+		c.orderEventTopics(),
+		func() order.OrderTransaction {
+			return order.OrderTransaction{Orders: repository, CircuitBreaker: sessions}
+		},
+		reference,
+		settings.FeeAccountID,
+		reference,
+		reference,
+		order.DefaultRiskChain(),
+		reference,
+		sessions,
+		reference,
+		reference,
+		application.DetachedBooks{},
+		clock)
+	return application.NewBacktester(repository, matcher, sessions, clock, output, settings)
+}
+
+func (c *ContainerBuilder) newOrderMatcher(producer provider.IProducer, transactionGen func() order.OrderTransaction, tradingSessions order.ITradingSessionProvider, books order.IBookReplica, clock lib.IClock) *application.OrderMatcher {
+	return application.NewOrderMatcher(producer,
+		c.orderEventTopics(),
+		transactionGen,
+		c.NewFeeSchedule(),
+		c.cnf.FeeCollectionAccount,
+		c.NewInstrumentRules(),
+		c.NewInstrumentRules(),
+		order.DefaultRiskChain(),
+		c.NewSelfTradePolicy(),
+		tradingSessions,
+		c.NewInstrumentRules(),
+		c.NewInstrumentRules(),
+		books,
+		clock)
+}
+
+func (c *ContainerBuilder) orderEventTopics() application.OrderEventTopics {
+	return application.OrderEventTopics{
+		Matched:            c.cnf.OrderMatchedTopic,
+		Rejected:           c.cnf.OrderRejectedTopic,
+		SelfTradePrevented: c.cnf.OrderSelfTradePreventedTopic,
+	}
+}
+
+// GetBookRegistry is shared by every matcher of the instance, books are loaded from the master
+func (c *ContainerBuilder) GetBookRegistry() *application.BookRegistry {
+	if c.bookRegistry == nil {
+		c.bookRegistry = application.NewBookRegistry(c.NewOrderWriteRepository(), c.GetKafkaProducerProvider(), c.cnf.OrderCreateTopic)
+	}
+	return c.bookRegistry
 }
 
 func (c *ContainerBuilder) GetKafkaCreateOrderConsumerProvider() *provider.KafkaConsumerProvider {
@@ -43,6 +148,7 @@ func (c *ContainerBuilder) GetKafkaCreateOrderConsumerProvider() *provider.Kafka
 		if err != nil {
 			log.Fatalln(err)
 		}
+		pv.AddPartitionListener(c.GetBookRegistry())
 		c.kafkaCreateOrderConsumerProvider = pv
 	}
 	return c.kafkaCreateOrderConsumerProvider
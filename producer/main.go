@@ -3,9 +3,11 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
@@ -15,10 +17,12 @@ import (
 var cfg Config
 
 type Order struct {
-	OrderID  int       `json:"orderID"`
-	Price    int       `json:"price"`
-	Quantity int       `json:"quantity"`
-	Side     OrderSide `json:"side"`
+	OrderID   int         `json:"orderID"`
+	AccountID string      `json:"accountID"`
+	Symbol    string      `json:"symbol"`
+	Price     json.Number `json:"price"`
+	Quantity  json.Number `json:"quantity"`
+	Side      OrderSide   `json:"side"`
 }
 
 type OrderSide string
@@ -49,13 +53,12 @@ func produceOrder(producer *kafka.Producer, order Order, topic string, wg *sync.
 		return
 	}
 
-	partitionKey := strconv.Itoa(order.OrderID % 2)
+	// every order of a symbol goes to the same partition so a single matcher owns its book
 	msg := &kafka.Message{
 		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
-		Key:            []byte(partitionKey),
+		Key:            []byte(order.Symbol),
 		Value:          orderBytes,
 	}
-	fmt.Println(partitionKey)
 	err = producer.Produce(msg, nil)
 	if err != nil {
 		fmt.Printf("Failed to produce message: %v\n", err)
@@ -73,7 +76,7 @@ func worker(id int, orders <-chan Order, producer *kafka.Producer, topic string,
 func main() {
 	cfg = configFromEnv()
 
-	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cfg.Broker})
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": cfg.Broker, "partitioner": "murmur2_random"})
 	if err != nil {
 		panic(err)
 	}
@@ -119,7 +122,13 @@ type Config struct {
 	MaxPrice    int
 	MinQuantity int
 	MaxQuantity int
-	BaseId      int
+	// PriceDecimals and QuantityDecimals are the fractional digits of the random values, they have to fit the
+	// tick and lot size of the symbols
+	PriceDecimals    int
+	QuantityDecimals int
+	BaseId           int
+	NumAccounts      int
+	Symbols          []string
 }
 
 func getEnv(key string, fallback string) string {
@@ -131,23 +140,39 @@ func getEnv(key string, fallback string) string {
 
 func configFromEnv() Config {
 	return Config{
-		Broker:      getEnv("BROKER", "localhost:29092"),
-		Topic:       getEnv("TOPIC", "order-events"),
-		NumWorkers:  cast.ToInt(getEnv("NUM_WORKERS", "100")),
-		NumOrders:   cast.ToInt(getEnv("NUM_ORDERS", "10000")),
-		MinPrice:    cast.ToInt(getEnv("MIN_PRICE", "1")),
-		MaxPrice:    cast.ToInt(getEnv("MAX_PRICE", "10")),
-		MinQuantity: cast.ToInt(getEnv("MIN_QUANTITY", "1")),
-		MaxQuantity: cast.ToInt(getEnv("MAX_QUANTITY", "20")),
-		BaseId:      cast.ToInt(getEnv("BASE_ID", "30000")),
+		Broker:           getEnv("BROKER", "localhost:29092"),
+		Topic:            getEnv("TOPIC", "order-events"),
+		NumWorkers:       cast.ToInt(getEnv("NUM_WORKERS", "100")),
+		NumOrders:        cast.ToInt(getEnv("NUM_ORDERS", "10000")),
+		MinPrice:         cast.ToInt(getEnv("MIN_PRICE", "1")),
+		MaxPrice:         cast.ToInt(getEnv("MAX_PRICE", "10")),
+		MinQuantity:      cast.ToInt(getEnv("MIN_QUANTITY", "1")),
+		MaxQuantity:      cast.ToInt(getEnv("MAX_QUANTITY", "20")),
+		PriceDecimals:    cast.ToInt(getEnv("PRICE_DECIMALS", "0")),
+		QuantityDecimals: cast.ToInt(getEnv("QUANTITY_DECIMALS", "0")),
+		BaseId:           cast.ToInt(getEnv("BASE_ID", "30000")),
+		NumAccounts:      cast.ToInt(getEnv("NUM_ACCOUNTS", "10")),
+		Symbols:          strings.Split(getEnv("SYMBOLS", "BTC-USD,ETH-USD"), ","),
 	}
 }
 
 func createRandomOrder(orderIDGen *OrderIDGenerator) Order {
 	return Order{
-		OrderID:  orderIDGen.getOrderID(),
-		Price:    rand.Intn(cfg.MaxPrice-cfg.MinPrice) + cfg.MinPrice,
-		Quantity: rand.Intn(cfg.MaxQuantity-cfg.MinQuantity) + cfg.MinQuantity,
-		Side:     []OrderSide{BuyOrderSide, SellOrderSide}[rand.Intn(2)],
+		OrderID:   orderIDGen.getOrderID(),
+		AccountID: fmt.Sprintf("account-%d", rand.Intn(cfg.NumAccounts)+1),
+		Symbol:    cfg.Symbols[rand.Intn(len(cfg.Symbols))],
+		Price:     randomDecimal(cfg.MinPrice, cfg.MaxPrice, cfg.PriceDecimals),
+		Quantity:  randomDecimal(cfg.MinQuantity, cfg.MaxQuantity, cfg.QuantityDecimals),
+		Side:      []OrderSide{BuyOrderSide, SellOrderSide}[rand.Intn(2)],
 	}
 }
+
+// randomDecimal picks a value in [min, max) with the given number of fractional digits
+func randomDecimal(min, max, decimals int) json.Number {
+	factor := int(math.Pow10(decimals))
+	units := rand.Intn((max-min)*factor) + min*factor
+	if decimals == 0 {
+		return json.Number(strconv.Itoa(units))
+	}
+	return json.Number(fmt.Sprintf("%d.%0*d", units/factor, decimals, units%factor))
+}